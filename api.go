@@ -8,28 +8,81 @@ import (
 
 	"github.com/opentofu/libregistry/metadata"
 	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
 	"github.com/opentofu/libregistry/vcs"
 )
 
 // API describes the API interface for accessing the registry.
 type API interface {
 	// AddModule adds a module based on a VCS repository. The VCS repository name must follow the naming convention
-	// of the VCS implementation passed to the registry API on initialization.
+	// of the VCS implementation passed to the registry API on initialization. The latest version is checked out and
+	// validated first, a *ModuleValidationFailedError lists every failed check if the repository does not hold a
+	// valid module. A *ModuleForkRejectedError is returned if the fork policy rejects the repository.
 	AddModule(ctx context.Context, vcsRepository string) error
+	// AddModuleAs adds a module like AddModule on behalf of a user. It returns a *PermissionDeniedError if the user
+	// may not act on behalf of the organization owning the repository.
+	AddModuleAs(ctx context.Context, username vcs.Username, vcsRepository string) error
+	// AddModuleFromSource adds a module stored in a repository that does not follow the naming convention, for
+	// example a repository holding many modules. Only tags with the tag prefix of the source are added as versions
+	// and downloads point to the subdirectory of the source. The module is validated like in AddModule.
+	AddModuleFromSource(ctx context.Context, moduleAddr module.Addr, source module.Source) error
 	// UpdateModule updates the list of available versions for a module in the registry from its source repository.
 	// This function is idempotent and adds the module to the storage if it does not exist yet.
 	UpdateModule(ctx context.Context, moduleAddr module.Addr) error
+	// UpdateModules updates the given modules in parallel. A failing module does not stop the update of the others,
+	// its error is recorded in the report instead. The returned error is only set if the bulk update as a whole
+	// failed, for example because the context was cancelled.
+	UpdateModules(ctx context.Context, moduleAddrs []module.Addr, opts ...BulkUpdateOpt) (ModuleUpdateReport, error)
+	// UpdateAllModules updates all modules in the registry in parallel, see UpdateModules.
+	UpdateAllModules(ctx context.Context, opts ...BulkUpdateOpt) (ModuleUpdateReport, error)
+	// SetModuleDeprecation marks a module as deprecated. If version is not empty, only that version is marked. The
+	// Since field defaults to the current time. An invalid successor address returns a *module.InvalidModuleAddrError.
+	SetModuleDeprecation(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber, deprecation module.Deprecation) error
+	// ClearModuleDeprecation removes the deprecation from a module, or from a single version if version is not empty.
+	ClearModuleDeprecation(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) error
+
+	// AddProvider adds a provider based on a VCS repository. The VCS repository name must follow the naming
+	// convention of the VCS implementation passed to the registry API on initialization.
+	AddProvider(ctx context.Context, vcsRepository string) error
+	// UpdateProvider updates the list of available versions for a provider in the registry from the releases in its
+	// source repository. This function is idempotent and adds the provider to the storage if it does not exist yet.
+	// Releases whose signature cannot be verified are rejected with a *ProviderSignatureInvalidError. If the
+	// provider has new releases, but no signing keys are registered for its namespace, it returns a
+	// *ProviderNamespaceKeysMissingError without downloading them. Releases whose docs cannot be collected are
+	// published without docs and reported with a *ProviderDocsFailedError.
+	UpdateProvider(ctx context.Context, providerAddr provider.Addr) error
+	// AddProviderNamespaceKeyAs adds a public GPG key for verifying the releases of all providers in a namespace on
+	// behalf of a user. The key ID is filled in from the key if empty. It returns a *PermissionDeniedError if the
+	// user may not act on behalf of the organization matching the namespace.
+	AddProviderNamespaceKeyAs(ctx context.Context, username vcs.Username, namespace string, key provider.Key) error
+	// SetProviderDeprecation marks a provider as deprecated. If version is not empty, only that version is marked.
+	// The Since field defaults to the current time. An invalid successor address returns a
+	// *provider.InvalidProviderAddrError.
+	SetProviderDeprecation(ctx context.Context, providerAddr provider.Addr, version provider.VersionNumber, deprecation provider.Deprecation) error
+	// ClearProviderDeprecation removes the deprecation from a provider, or from a single version if version is not
+	// empty.
+	ClearProviderDeprecation(ctx context.Context, providerAddr provider.Addr, version provider.VersionNumber) error
 }
 
 // New creates a new instance of the registry API with the given GitHub client and data API instance.
-func New(vcsClient vcs.Client, dataAPI metadata.ModuleDataAPI) (API, error) {
+func New(vcsClient vcs.Client, dataAPI metadata.API, opts ...Opt) (API, error) {
+	cfg := Config{}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, err
+		}
+	}
+	cfg.ApplyDefaults()
+
 	return &api{
+		cfg,
 		dataAPI,
 		vcsClient,
 	}, nil
 }
 
 type api struct {
-	dataAPI   metadata.ModuleDataAPI
+	config    Config
+	dataAPI   metadata.API
 	vcsClient vcs.Client
 }
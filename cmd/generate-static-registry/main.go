@@ -0,0 +1,49 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package main contains a tool to generate the static registry files from the registry data.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/opentofu/libregistry/generator"
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/filesystem"
+	"github.com/opentofu/libregistry/protocol"
+)
+
+func main() {
+	if len(os.Args) != 3 && !(len(os.Args) == 4 && os.Args[3] == "--full") {
+		_, _ = os.Stderr.Write([]byte("Usage: generate-static-registry path/to/registry path/to/output [--full]"))
+		os.Exit(1)
+	}
+
+	meta, err := metadata.New(filesystem.New(os.Args[1]))
+	if err != nil {
+		_, _ = os.Stderr.Write([]byte(fmt.Errorf("failed to initialize metadata system; did you pass the correct registry directory? (%w)", err).Error()))
+		os.Exit(1)
+	}
+
+	gen, err := generator.New(
+		meta,
+		filesystem.New(os.Args[2]),
+		// The modules of the OpenTofu registry are hosted on GitHub.
+		generator.WithModuleSource(protocol.GitHubModuleSource),
+		generator.WithFullRegeneration(len(os.Args) == 4),
+		generator.WithLogger(logger.NewGoLogLogger(log.New(os.Stderr, "", log.LstdFlags))),
+	)
+	if err != nil {
+		_, _ = os.Stderr.Write([]byte(err.Error()))
+		os.Exit(1)
+	}
+
+	if err := gen.Generate(context.Background()); err != nil {
+		_, _ = os.Stderr.Write([]byte(err.Error()))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"fmt"
+)
+
+// TagChangePolicy determines what happens to a published module version when its tag is deleted or moved to a
+// different commit in the module repository.
+type TagChangePolicy string
+
+const (
+	// TagChangePolicyKeep keeps the published version and only reports the change. The new commit of a moved tag is
+	// recorded, so the change is only reported once. Deleted tags are only detected when the update fetches the full
+	// tag list.
+	TagChangePolicyKeep TagChangePolicy = "keep"
+	// TagChangePolicyRemove removes versions whose tag was deleted or moved. Removed versions are recorded in the
+	// module metadata and not published again, even if the tag is recreated.
+	TagChangePolicyRemove TagChangePolicy = "remove"
+	// TagChangePolicyYank keeps the version, but flags it as yanked with a reason.
+	TagChangePolicyYank TagChangePolicy = "yank"
+)
+
+// Validate returns an error if the policy is not one of the known policies.
+func (p TagChangePolicy) Validate() error {
+	switch p {
+	case TagChangePolicyKeep, TagChangePolicyRemove, TagChangePolicyYank:
+		return nil
+	default:
+		return fmt.Errorf("invalid tag change policy: %s", p)
+	}
+}
+
+// ForkPolicy determines how modules are treated whose repository is a fork of another repository.
+type ForkPolicy string
+
+const (
+	// ForkPolicyAllow treats forks like any other repository.
+	ForkPolicyAllow ForkPolicy = "allow"
+	// ForkPolicyReject rejects all forks.
+	ForkPolicyReject ForkPolicy = "reject"
+	// ForkPolicyWarn accepts forks, but reports a warning if the parent repository is already registered as a
+	// module.
+	ForkPolicyWarn ForkPolicy = "warn"
+	// ForkPolicyRequireDivergence only accepts forks that have a version tag the parent repository does not have, or
+	// that points to a different commit.
+	ForkPolicyRequireDivergence ForkPolicy = "require_divergence"
+)
+
+// Validate returns an error if the policy is not one of the known policies.
+func (p ForkPolicy) Validate() error {
+	switch p {
+	case ForkPolicyAllow, ForkPolicyReject, ForkPolicyWarn, ForkPolicyRequireDivergence:
+		return nil
+	default:
+		return fmt.Errorf("invalid fork policy: %s", p)
+	}
+}
+
+// Opt is a function that modifies the config.
+type Opt func(config *Config) error
+
+// Config holds the configuration for the registry API.
+type Config struct {
+	// TagChangePolicy determines how module updates handle versions whose tag was deleted or moved. Any policy other
+	// than TagChangePolicyKeep requires the full tag list on every update. Defaults to TagChangePolicyKeep.
+	TagChangePolicy TagChangePolicy
+	// ModuleDetails enables extracting the variables, outputs, required providers and README of each new module
+	// version. This checks out every new version, so it is disabled by default.
+	ModuleDetails bool
+	// ProviderDocs enables collecting the documentation of each new provider version. This checks out every new
+	// version, so it is disabled by default.
+	ProviderDocs bool
+	// ForkPolicy determines how modules in forked repositories are treated when they are added. Defaults to
+	// ForkPolicyAllow.
+	ForkPolicy ForkPolicy
+}
+
+// ApplyDefaults adds the default values if none are present.
+func (c *Config) ApplyDefaults() {
+	if c.TagChangePolicy == "" {
+		c.TagChangePolicy = TagChangePolicyKeep
+	}
+	if c.ForkPolicy == "" {
+		c.ForkPolicy = ForkPolicyAllow
+	}
+}
+
+// WithTagChangePolicy sets the policy for module versions whose tag was deleted or moved in the module repository.
+func WithTagChangePolicy(policy TagChangePolicy) Opt {
+	return func(config *Config) error {
+		if err := policy.Validate(); err != nil {
+			return err
+		}
+		config.TagChangePolicy = policy
+		return nil
+	}
+}
+
+// WithModuleDetails enables extracting the documentation of new module versions on update. The details are stored
+// with PutModuleVersionDetails.
+func WithModuleDetails() Opt {
+	return func(config *Config) error {
+		config.ModuleDetails = true
+		return nil
+	}
+}
+
+// WithProviderDocs enables collecting the documentation of new provider versions on update. The pages are stored with
+// PutProviderDocs.
+func WithProviderDocs() Opt {
+	return func(config *Config) error {
+		config.ProviderDocs = true
+		return nil
+	}
+}
+
+// WithForkPolicy sets the policy for modules whose repository is a fork of another repository.
+func WithForkPolicy(policy ForkPolicy) Opt {
+	return func(config *Config) error {
+		if err := policy.Validate(); err != nil {
+			return err
+		}
+		config.ForkPolicy = policy
+		return nil
+	}
+}
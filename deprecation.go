@@ -0,0 +1,101 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentofu/libregistry/types"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+func (m api) SetModuleDeprecation(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber, deprecation module.Deprecation) error {
+	if deprecation.SuccessorAddr != nil {
+		if err := deprecation.SuccessorAddr.Validate(); err != nil {
+			return err
+		}
+	}
+	if deprecation.Since == nil {
+		deprecation.Since = types.OptionalTime(time.Now().UTC())
+	}
+	return m.setModuleDeprecation(ctx, moduleAddr, version, &deprecation)
+}
+
+func (m api) ClearModuleDeprecation(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) error {
+	return m.setModuleDeprecation(ctx, moduleAddr, version, nil)
+}
+
+func (m api) setModuleDeprecation(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber, deprecation *module.Deprecation) error {
+	if err := moduleAddr.Validate(); err != nil {
+		return err
+	}
+	moduleMetadata, err := m.dataAPI.GetModule(ctx, moduleAddr)
+	if err != nil {
+		return err
+	}
+	if version == "" {
+		moduleMetadata.Deprecation = deprecation
+	} else {
+		found := false
+		for i, ver := range moduleMetadata.Versions {
+			if ver.Version.Normalize() == version.Normalize() {
+				moduleMetadata.Versions[i].Deprecation = deprecation
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &ModuleVersionNotFoundError{
+				moduleAddr,
+				version,
+			}
+		}
+	}
+	return m.dataAPI.PutModule(ctx, moduleAddr, moduleMetadata)
+}
+
+func (m api) SetProviderDeprecation(ctx context.Context, providerAddr provider.Addr, version provider.VersionNumber, deprecation provider.Deprecation) error {
+	if deprecation.SuccessorAddr != nil {
+		if err := deprecation.SuccessorAddr.Validate(); err != nil {
+			return err
+		}
+	}
+	if deprecation.Since == nil {
+		deprecation.Since = types.OptionalTime(time.Now().UTC())
+	}
+	return m.setProviderDeprecation(ctx, providerAddr, version, &deprecation)
+}
+
+func (m api) ClearProviderDeprecation(ctx context.Context, providerAddr provider.Addr, version provider.VersionNumber) error {
+	return m.setProviderDeprecation(ctx, providerAddr, version, nil)
+}
+
+func (m api) setProviderDeprecation(ctx context.Context, providerAddr provider.Addr, version provider.VersionNumber, deprecation *provider.Deprecation) error {
+	providerAddr = providerAddr.Normalize()
+	providerMetadata, err := m.dataAPI.GetProvider(ctx, providerAddr, false)
+	if err != nil {
+		return err
+	}
+	if version == "" {
+		providerMetadata.Deprecation = deprecation
+	} else {
+		found := false
+		for i, ver := range providerMetadata.Versions {
+			if ver.Version.Normalize() == version.Normalize() {
+				providerMetadata.Versions[i].Deprecation = deprecation
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &ProviderVersionNotFoundError{
+				providerAddr,
+				version,
+			}
+		}
+	}
+	return m.dataAPI.PutProvider(ctx, providerAddr, providerMetadata)
+}
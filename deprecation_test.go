@@ -0,0 +1,206 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/libregistry"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/protocol"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+)
+
+func TestModuleDeprecation(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "vpc",
+		TargetSystem: "aws",
+	}
+	successorAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "network",
+		TargetSystem: "aws",
+	}
+	repo := vcs.RepositoryAddr{
+		Org:  "test",
+		Name: "terraform-aws-vpc",
+	}
+
+	ctx := context.Background()
+	inMemoryVCS := fakevcs.New()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, ver := range []vcs.VersionNumber{"v1.0.0", "v1.1.0"} {
+		if err := inMemoryVCS.CreateVersion(repo, ver, testModuleContents(".")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := registry.AddModule(ctx, repo.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("⚙️ Deprecating the module and version v1.0.0...")
+	if err := registry.SetModuleDeprecation(ctx, moduleAddr, "", module.Deprecation{
+		Reason:        "This module is no longer maintained.",
+		SuccessorAddr: &successorAddr,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.SetModuleDeprecation(ctx, moduleAddr, "1.0.0", module.Deprecation{
+		Reason: "This version has a known bug.",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var versionNotFound *libregistry.ModuleVersionNotFoundError
+	if err := registry.SetModuleDeprecation(ctx, moduleAddr, "v2.0.0", module.Deprecation{}); !errors.As(err, &versionNotFound) {
+		t.Fatalf("❌ Expected a ModuleVersionNotFoundError, got: %v", err)
+	}
+	var invalidAddr *module.InvalidModuleAddrError
+	if err := registry.SetModuleDeprecation(ctx, moduleAddr, "", module.Deprecation{
+		SuccessorAddr: &module.Addr{
+			Namespace: "new",
+		},
+	}); !errors.As(err, &invalidAddr) {
+		t.Fatalf("❌ Expected an InvalidModuleAddrError, got: %v", err)
+	}
+
+	t.Logf("⚙️ Checking that updates keep the deprecation...")
+	if err := registry.UpdateModule(ctx, moduleAddr); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := dataAPI.GetModule(ctx, moduleAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Deprecation == nil || stored.Deprecation.Since == nil {
+		t.Fatalf("❌ The module deprecation was not stored: %v", stored.Deprecation)
+	}
+	if stored.Deprecation.SuccessorAddr == nil || !stored.Deprecation.SuccessorAddr.Equals(successorAddr) {
+		t.Fatalf("❌ Incorrect successor stored: %v", stored.Deprecation.SuccessorAddr)
+	}
+	deprecated := stored.Versions.Deprecated()
+	if len(deprecated) != 1 || deprecated[0].Version != "v1.0.0" {
+		t.Fatalf("❌ Incorrect deprecated versions: %v", deprecated)
+	}
+	warnings := stored.Warnings()
+	if len(warnings) != 2 || !strings.Contains(warnings[0], successorAddr.String()) {
+		t.Fatalf("❌ Incorrect warnings: %v", warnings)
+	}
+
+	t.Logf("⚙️ Clearing the deprecation...")
+	if err := registry.ClearModuleDeprecation(ctx, moduleAddr, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.ClearModuleDeprecation(ctx, moduleAddr, "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	stored, err = dataAPI.GetModule(ctx, moduleAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if warnings := stored.Warnings(); len(warnings) != 0 {
+		t.Fatalf("❌ The deprecation was not cleared: %v", warnings)
+	}
+	t.Logf("✅ The module deprecation was set and cleared.")
+}
+
+func TestProviderDeprecation(t *testing.T) {
+	providerAddr := provider.Addr{
+		Namespace: "test",
+		Name:      "test",
+	}
+	repo := providerAddr.ToRepositoryAddr()
+
+	ctx := context.Background()
+	inMemoryVCS := fakevcs.New()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	keyRing := createProviderNamespaceKey(t, dataAPI, providerAddr.Namespace)
+	createProviderRelease(t, inMemoryVCS, keyRing, providerAddr, "v1.0.0", []string{"linux_amd64"})
+	if err := registry.UpdateProvider(ctx, providerAddr); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("⚙️ Deprecating the provider...")
+	if err := registry.SetProviderDeprecation(ctx, providerAddr, "", provider.Deprecation{
+		Reason: "Moved to a new namespace.",
+		SuccessorAddr: &provider.Addr{
+			Namespace: "new",
+			Name:      "test",
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.SetProviderDeprecation(ctx, providerAddr, "v1.0.0", provider.Deprecation{
+		Reason: "This version has a known bug.",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var versionNotFound *libregistry.ProviderVersionNotFoundError
+	if err := registry.SetProviderDeprecation(ctx, providerAddr, "v2.0.0", provider.Deprecation{}); !errors.As(err, &versionNotFound) {
+		t.Fatalf("❌ Expected a ProviderVersionNotFoundError, got: %v", err)
+	}
+	var invalidAddr *provider.InvalidProviderAddrError
+	if err := registry.SetProviderDeprecation(ctx, providerAddr, "", provider.Deprecation{
+		SuccessorAddr: &provider.Addr{
+			Namespace: "new",
+			Name:      "",
+		},
+	}); !errors.As(err, &invalidAddr) {
+		t.Fatalf("❌ Expected an InvalidProviderAddrError, got: %v", err)
+	}
+
+	stored, err := dataAPI.GetProvider(ctx, providerAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := protocol.NewProviderVersionsResponse(stored)
+	if len(response.Warnings) != 1 || !strings.Contains(response.Warnings[0], "new/test") {
+		t.Fatalf("❌ Incorrect warnings in the versions response: %v", response.Warnings)
+	}
+
+	if err := registry.ClearProviderDeprecation(ctx, providerAddr, ""); err != nil {
+		t.Fatal(err)
+	}
+	stored, err = dataAPI.GetProvider(ctx, providerAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Deprecation != nil {
+		t.Fatalf("❌ The deprecation was not cleared: %v", stored.Deprecation)
+	}
+	t.Logf("✅ The provider deprecation was set and cleared.")
+}
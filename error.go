@@ -4,7 +4,11 @@
 package libregistry
 
 import (
+	"strings"
+
 	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
+	"github.com/opentofu/libregistry/vcs"
 )
 
 type ModuleAlreadyExistsError struct {
@@ -40,3 +44,223 @@ func (m ModuleUpdateFailedError) Error() string {
 func (m ModuleUpdateFailedError) Unwrap() error {
 	return m.Cause
 }
+
+type ProviderAlreadyExistsError struct {
+	Provider provider.Addr
+}
+
+func (p ProviderAlreadyExistsError) Error() string {
+	return "Provider already exists: " + p.Provider.String()
+}
+
+type ProviderAddFailedError struct {
+	Provider provider.Addr
+	Cause    error
+}
+
+func (p ProviderAddFailedError) Error() string {
+	return "Adding the provider " + p.Provider.String() + " failed: " + p.Cause.Error()
+}
+
+func (p ProviderAddFailedError) Unwrap() error {
+	return p.Cause
+}
+
+type ProviderUpdateFailedError struct {
+	Provider provider.Addr
+	Cause    error
+}
+
+func (p ProviderUpdateFailedError) Error() string {
+	return "Updating the provider " + p.Provider.String() + " failed: " + p.Cause.Error()
+}
+
+func (p ProviderUpdateFailedError) Unwrap() error {
+	return p.Cause
+}
+
+// ProviderReleaseIncompleteError indicates that a release is missing an asset required for a provider version.
+type ProviderReleaseIncompleteError struct {
+	Provider provider.Addr
+	Version  vcs.VersionNumber
+	Asset    string
+}
+
+func (p ProviderReleaseIncompleteError) Error() string {
+	return "The release " + string(p.Version) + " of the provider " + p.Provider.String() + " has no " + p.Asset + " file"
+}
+
+// ProviderSignatureInvalidError indicates that the SHA256SUMS file of a provider release could not be verified with
+// any of the keys registered for the provider namespace.
+type ProviderSignatureInvalidError struct {
+	Provider provider.Addr
+	Version  vcs.VersionNumber
+	// KeyIDs contains the IDs of all keys the signature was checked against.
+	KeyIDs []string
+	Cause  error
+}
+
+func (p ProviderSignatureInvalidError) Error() string {
+	msg := "The signature for version " + string(p.Version) + " of the provider " + p.Provider.String() + " is invalid"
+	if len(p.KeyIDs) == 0 {
+		msg += ", no keys are registered for the namespace"
+	} else {
+		msg += ", tried keys: " + strings.Join(p.KeyIDs, ", ")
+	}
+	if p.Cause != nil {
+		msg += " (" + p.Cause.Error() + ")"
+	}
+	return msg
+}
+
+func (p ProviderSignatureInvalidError) Unwrap() error {
+	return p.Cause
+}
+
+// ProviderNamespaceKeysMissingError indicates that no signing keys are registered for the namespace of a provider, so
+// none of its releases can be verified. The releases are not downloaded until a key is added to the namespace.
+type ProviderNamespaceKeysMissingError struct {
+	Provider provider.Addr
+}
+
+func (p ProviderNamespaceKeysMissingError) Error() string {
+	return "No signing keys are registered for the namespace " + p.Provider.Namespace + ", the releases of the provider " +
+		p.Provider.String() + " cannot be verified"
+}
+
+// ProviderDocsFailedError indicates that the documentation of a provider release could not be collected. The version
+// is published without documentation.
+type ProviderDocsFailedError struct {
+	Provider provider.Addr
+	Version  vcs.VersionNumber
+	Cause    error
+}
+
+func (p ProviderDocsFailedError) Error() string {
+	return "Collecting the docs for version " + string(p.Version) + " of the provider " + p.Provider.String() +
+		" failed: " + p.Cause.Error()
+}
+
+func (p ProviderDocsFailedError) Unwrap() error {
+	return p.Cause
+}
+
+// ModuleVersionNotFoundError indicates that the module exists in the registry, but the version does not.
+type ModuleVersionNotFoundError struct {
+	Module  module.Addr
+	Version module.VersionNumber
+}
+
+func (m ModuleVersionNotFoundError) Error() string {
+	return "Version " + string(m.Version) + " of the module " + m.Module.String() + " not found"
+}
+
+// ProviderVersionNotFoundError indicates that the provider exists in the registry, but the version does not.
+type ProviderVersionNotFoundError struct {
+	Provider provider.Addr
+	Version  provider.VersionNumber
+}
+
+func (p ProviderVersionNotFoundError) Error() string {
+	return "Version " + string(p.Version) + " of the provider " + p.Provider.String() + " not found"
+}
+
+// ModuleForkRejectedError indicates that the fork policy rejected a module because its repository is a fork.
+type ModuleForkRejectedError struct {
+	Module   module.Addr
+	Decision ForkDecision
+}
+
+func (m ModuleForkRejectedError) Error() string {
+	return "The module " + m.Module.String() + " was rejected by the " + string(m.Decision.Policy) + " fork policy: " + m.Decision.Reason
+}
+
+// ModuleValidationCheck identifies a single check of the module validation.
+type ModuleValidationCheck string
+
+const (
+	// ModuleValidationCheckVersion fails if the repository has no tag that is a valid module version.
+	ModuleValidationCheckVersion ModuleValidationCheck = "version"
+	// ModuleValidationCheckArchivedFork fails if the repository is an archived fork of another repository.
+	ModuleValidationCheckArchivedFork ModuleValidationCheck = "archived_fork"
+	// ModuleValidationCheckConfigurationFiles fails if the module root contains no .tf or .tofu files.
+	ModuleValidationCheckConfigurationFiles ModuleValidationCheck = "configuration_files"
+	// ModuleValidationCheckSyntax fails if a configuration file in the module root cannot be parsed.
+	ModuleValidationCheckSyntax ModuleValidationCheck = "syntax"
+)
+
+// ModuleValidationFailure describes a single failed check of the module validation.
+type ModuleValidationFailure struct {
+	Check   ModuleValidationCheck
+	Message string
+}
+
+// ModuleValidationFailedError indicates that a submitted module is not a valid module. It lists every failed check.
+type ModuleValidationFailedError struct {
+	Module module.Addr
+	// Version is the version that was checked out for validation. It is empty if no version was found.
+	Version  module.VersionNumber
+	Failures []ModuleValidationFailure
+}
+
+func (m ModuleValidationFailedError) Error() string {
+	messages := make([]string, len(m.Failures))
+	for i, failure := range m.Failures {
+		messages[i] = failure.Message
+	}
+	result := "Validating the module " + m.Module.String()
+	if m.Version != "" {
+		result += " at version " + string(m.Version)
+	}
+	return result + " failed: " + strings.Join(messages, "; ")
+}
+
+// Failed returns true if the given check is among the failures.
+func (m ModuleValidationFailedError) Failed(check ModuleValidationCheck) bool {
+	for _, failure := range m.Failures {
+		if failure.Check == check {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionDeniedError indicates that a user may not act on behalf of an organization, for example because they are
+// not a member of it.
+type PermissionDeniedError struct {
+	Username     vcs.Username
+	Organization vcs.OrganizationAddr
+}
+
+func (p PermissionDeniedError) Error() string {
+	return "The user " + string(p.Username) + " does not have permission to act on behalf of " + string(p.Organization)
+}
+
+// PermissionCheckFailedError indicates that the VCS could not be asked whether a user may act on behalf of an
+// organization, for example because the user does not exist.
+type PermissionCheckFailedError struct {
+	Username     vcs.Username
+	Organization vcs.OrganizationAddr
+	Cause        error
+}
+
+func (p PermissionCheckFailedError) Error() string {
+	return "Failed to check the permission of " + string(p.Username) + " to act on behalf of " + string(p.Organization) + ": " + p.Cause.Error()
+}
+
+func (p PermissionCheckFailedError) Unwrap() error {
+	return p.Cause
+}
+
+type ProviderNamespaceKeyAddFailedError struct {
+	Namespace string
+	Cause     error
+}
+
+func (p ProviderNamespaceKeyAddFailedError) Error() string {
+	return "Adding the key to the provider namespace " + p.Namespace + " failed: " + p.Cause.Error()
+}
+
+func (p ProviderNamespaceKeyAddFailedError) Unwrap() error {
+	return p.Cause
+}
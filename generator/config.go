@@ -0,0 +1,67 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package generator
+
+import (
+	"fmt"
+
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/protocol"
+)
+
+// Opt is a function that modifies the config.
+type Opt func(config *Config) error
+
+// Config holds the configuration for the static registry generator.
+type Config struct {
+	// ModuleSource returns the download location of a module version. It is required, as there is no way to tell
+	// where the modules are hosted. Use protocol.VCSModuleSource with the VCS client of the registry or
+	// protocol.GitHubModuleSource for modules on github.com.
+	ModuleSource protocol.ModuleSourceFunc
+	// FullRegeneration rewrites the files of all addresses, even if they did not change since the last run.
+	FullRegeneration bool
+
+	// Logger holds the logger to write any logs to.
+	Logger logger.Logger
+}
+
+// ApplyDefaults adds the default values if none are present.
+func (c *Config) ApplyDefaults() {
+	if c.Logger == nil {
+		c.Logger = logger.NewNoopLogger()
+	}
+}
+
+// Validate checks if all required options are set.
+func (c Config) Validate() error {
+	if c.ModuleSource == nil {
+		return fmt.Errorf("no module source configured, use WithModuleSource to set one")
+	}
+	return nil
+}
+
+// WithModuleSource sets the function that determines where a module version is downloaded from.
+func WithModuleSource(moduleSource protocol.ModuleSourceFunc) Opt {
+	return func(config *Config) error {
+		config.ModuleSource = moduleSource
+		return nil
+	}
+}
+
+// WithFullRegeneration rewrites the files of all addresses instead of only the ones that changed since the last
+// run.
+func WithFullRegeneration(full bool) Opt {
+	return func(config *Config) error {
+		config.FullRegeneration = full
+		return nil
+	}
+}
+
+// WithLogger sets a logger to use for writing progress information.
+func WithLogger(logger logger.Logger) Opt {
+	return func(config *Config) error {
+		config.Logger = logger.WithName("Generator")
+		return nil
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package generator writes the registry as static files that can be served from a CDN. The generated files are the
+// JSON documents of the Module and Provider Registry Protocols, one file per versions and download endpoint.
+package generator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/protocol"
+)
+
+// StateFile is the file in the target storage holding the state of the last run. It is used to only regenerate the
+// addresses that changed.
+const StateFile storage.Path = ".generator-state.json"
+
+// Generator writes the static registry files.
+type Generator interface {
+	// Generate writes the protocol documents of all modules and providers, including provider aliases, into the
+	// target storage. Unless full regeneration is enabled, only addresses whose documents changed since the last run
+	// are written. Files of addresses that no longer exist are removed. The changes are only written to the target
+	// storage if the whole generation succeeds.
+	Generate(ctx context.Context) error
+}
+
+// New creates a new generator reading from the data API and writing into the target storage.
+func New(dataAPI metadata.API, target storage.API, options ...Opt) (Generator, error) {
+	config := Config{}
+	for _, opt := range options {
+		if err := opt(&config); err != nil {
+			return nil, err
+		}
+	}
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &generator{
+		config:  config,
+		dataAPI: dataAPI,
+		target:  target,
+	}, nil
+}
+
+type generator struct {
+	config  Config
+	dataAPI metadata.API
+	target  storage.API
+}
+
+// documents holds the generated files of a single address.
+type documents map[storage.Path][]byte
+
+func (d documents) put(path string, document any) error {
+	marshalled, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s (%w)", path, err)
+	}
+	d[storage.Path(path)] = marshalled
+	return nil
+}
+
+func (d documents) paths() []storage.Path {
+	paths := make([]storage.Path, 0, len(d))
+	for path := range d {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func (d documents) hash() string {
+	hash := sha256.New()
+	for _, path := range d.paths() {
+		hash.Write([]byte(path))
+		hash.Write([]byte{0})
+		hash.Write(d[path])
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// state is the contents of the StateFile.
+type state struct {
+	Modules   map[string]addrState `json:"modules"`
+	Providers map[string]addrState `json:"providers"`
+}
+
+// addrState records the generated files of a single address and the hash of their contents.
+type addrState struct {
+	Hash  string         `json:"hash"`
+	Files []storage.Path `json:"files"`
+}
+
+func (g generator) Generate(ctx context.Context) error {
+	changeSet := storage.NewChangeSet(g.target)
+
+	previousState, err := g.loadState(ctx)
+	if err != nil {
+		return err
+	}
+	newState := state{
+		Modules:   map[string]addrState{},
+		Providers: map[string]addrState{},
+	}
+
+	serviceDiscovery := documents{}
+	if err := serviceDiscovery.put(strings.TrimPrefix(protocol.ServiceDiscoveryPath, "/"), protocol.NewServiceDiscoveryResponse()); err != nil {
+		return err
+	}
+	for path, contents := range serviceDiscovery {
+		if err := changeSet.PutFile(ctx, path, contents); err != nil {
+			return fmt.Errorf("failed to write %s (%w)", path, err)
+		}
+	}
+
+	moduleDocuments, err := g.generateModules(ctx)
+	if err != nil {
+		return err
+	}
+	written, err := g.apply(ctx, changeSet, previousState.Modules, newState.Modules, moduleDocuments)
+	if err != nil {
+		return err
+	}
+	g.config.Logger.Info(ctx, "Wrote %d of %d modules.", written, len(moduleDocuments))
+
+	providerDocuments, err := g.generateProviders(ctx)
+	if err != nil {
+		return err
+	}
+	written, err = g.apply(ctx, changeSet, previousState.Providers, newState.Providers, providerDocuments)
+	if err != nil {
+		return err
+	}
+	g.config.Logger.Info(ctx, "Wrote %d of %d providers.", written, len(providerDocuments))
+
+	marshalledState, err := json.Marshal(newState)
+	if err != nil {
+		return fmt.Errorf("failed to marshal generator state (%w)", err)
+	}
+	if err := changeSet.PutFile(ctx, StateFile, marshalledState); err != nil {
+		return fmt.Errorf("failed to write generator state (%w)", err)
+	}
+	return changeSet.Commit(ctx)
+}
+
+// apply writes the documents of all addresses that changed compared to the previous state, records them in the new
+// state and removes the files that are no longer generated. It returns the number of addresses written.
+func (g generator) apply(
+	ctx context.Context,
+	target storage.API,
+	previousState map[string]addrState,
+	newState map[string]addrState,
+	docsByAddr map[string]documents,
+) (int, error) {
+	written := 0
+	for addr, docs := range docsByAddr {
+		current := addrState{
+			Hash:  docs.hash(),
+			Files: docs.paths(),
+		}
+		newState[addr] = current
+		previous, ok := previousState[addr]
+		if ok && !g.config.FullRegeneration && previous.Hash == current.Hash {
+			continue
+		}
+		for _, path := range current.Files {
+			if err := target.PutFile(ctx, path, docs[path]); err != nil {
+				return written, fmt.Errorf("failed to write %s (%w)", path, err)
+			}
+		}
+		if err := g.deleteFiles(ctx, target, previous.Files, docs); err != nil {
+			return written, err
+		}
+		written++
+	}
+	for addr, previous := range previousState {
+		if _, ok := docsByAddr[addr]; ok {
+			continue
+		}
+		if err := g.deleteFiles(ctx, target, previous.Files, nil); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// deleteFiles removes all files that are not in the kept documents.
+func (g generator) deleteFiles(ctx context.Context, target storage.API, files []storage.Path, keep documents) error {
+	for _, path := range files {
+		if _, ok := keep[path]; ok {
+			continue
+		}
+		if err := target.DeleteFile(ctx, path); err != nil {
+			return fmt.Errorf("failed to delete %s (%w)", path, err)
+		}
+	}
+	return nil
+}
+
+func (g generator) loadState(ctx context.Context) (state, error) {
+	result := state{}
+	contents, err := g.target.GetFile(ctx, StateFile)
+	if err != nil {
+		if !storage.IsFileNotFound(err) {
+			return result, fmt.Errorf("failed to read generator state (%w)", err)
+		}
+		return result, nil
+	}
+	if err := json.Unmarshal(contents, &result); err != nil {
+		return result, fmt.Errorf("failed to parse generator state %s (%w)", StateFile, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,227 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package generator_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/opentofu/libregistry/generator"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/metadata/storage/filesystem"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/protocol"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+func TestGenerate(t *testing.T) {
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	moduleAddr := module.Addr{
+		Namespace:    "opentofu",
+		Name:         "test",
+		TargetSystem: "aws",
+	}
+	if err := dataAPI.PutModule(ctx, moduleAddr, module.Metadata{
+		Versions: []module.Version{
+			{
+				Version: "v1.0.0",
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	createProvider(t, dataAPI)
+
+	target := &countingStorage{API: memory.New()}
+	gen, err := generator.New(dataAPI, target, generator.WithModuleSource(protocol.GitHubModuleSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gen.Generate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var moduleDownload protocol.ModuleDownloadResponse
+	readJSON(t, target, "v1/modules/opentofu/test/aws/1.0.0/download", &moduleDownload)
+	if moduleDownload.Location != "git::https://github.com/opentofu/terraform-aws-test?ref=v1.0.0" {
+		t.Fatalf("Incorrect module download location: %s", moduleDownload.Location)
+	}
+	var moduleVersions protocol.ModuleVersionsResponse
+	readJSON(t, target, "v1/modules/opentofu/test/aws/versions", &moduleVersions)
+	if len(moduleVersions.Modules) != 1 || len(moduleVersions.Modules[0].Versions) != 1 {
+		t.Fatalf("Incorrect module versions: %v", moduleVersions)
+	}
+	for _, namespace := range []string{"opentofu", "hashicorp"} {
+		var providerVersions protocol.ProviderVersionsResponse
+		readJSON(t, target, storage.Path("v1/providers/"+namespace+"/test/versions"), &providerVersions)
+		if len(providerVersions.Versions) != 1 {
+			t.Fatalf("Incorrect provider versions for namespace %s: %v", namespace, providerVersions)
+		}
+		var providerDownload protocol.ProviderDownloadResponse
+		readJSON(t, target, storage.Path("v1/providers/"+namespace+"/test/1.0.0/download/linux/amd64"), &providerDownload)
+		if len(providerDownload.SigningKeys.GPGPublicKeys) != 1 {
+			t.Fatalf("Incorrect signing keys for namespace %s: %v", namespace, providerDownload.SigningKeys)
+		}
+	}
+	var serviceDiscovery protocol.ServiceDiscoveryResponse
+	readJSON(t, target, ".well-known/terraform.json", &serviceDiscovery)
+
+	// Adding a version to the module should only rewrite the module files.
+	if err := dataAPI.PutModule(ctx, moduleAddr, module.Metadata{
+		Versions: []module.Version{
+			{
+				Version: "v1.1.0",
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	target.writes = nil
+	if err := gen.Generate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range target.writes {
+		if strings.HasPrefix(string(path), "v1/providers/") {
+			t.Fatalf("Unchanged provider file was rewritten: %s", path)
+		}
+	}
+	readJSON(t, target, "v1/modules/opentofu/test/aws/1.1.0/download", &moduleDownload)
+	if exists, _ := target.FileExists(ctx, "v1/modules/opentofu/test/aws/1.0.0/download"); exists {
+		t.Fatalf("The download file of the removed version still exists.")
+	}
+
+	// Removing the module should remove its files.
+	if err := dataAPI.DeleteModule(ctx, moduleAddr); err != nil {
+		t.Fatal(err)
+	}
+	if err := gen.Generate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := target.FileExists(ctx, "v1/modules/opentofu/test/aws/versions"); exists {
+		t.Fatalf("The versions file of the removed module still exists.")
+	}
+}
+
+// TestGenerateFilesystem tests the generator end to end with the registry data and the output on the filesystem,
+// which is how the generate-static-registry command runs it.
+func TestGenerateFilesystem(t *testing.T) {
+	ctx := context.Background()
+	dataAPI, err := metadata.New(filesystem.New(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	moduleAddr := module.Addr{
+		Namespace:    "opentofu",
+		Name:         "test",
+		TargetSystem: "aws",
+	}
+	if err := dataAPI.PutModule(ctx, moduleAddr, module.Metadata{
+		Versions: []module.Version{
+			{
+				Version: "v1.0.0",
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	createProvider(t, dataAPI)
+
+	target := filesystem.New(t.TempDir())
+	gen, err := generator.New(dataAPI, target, generator.WithModuleSource(protocol.GitHubModuleSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The second run reads the state file written by the first one.
+	for i := 0; i < 2; i++ {
+		if err := gen.Generate(ctx); err != nil {
+			t.Fatal(err)
+		}
+		var moduleVersions protocol.ModuleVersionsResponse
+		readJSON(t, target, "v1/modules/opentofu/test/aws/versions", &moduleVersions)
+		if len(moduleVersions.Modules) != 1 || len(moduleVersions.Modules[0].Versions) != 1 {
+			t.Fatalf("Incorrect module versions: %v", moduleVersions)
+		}
+		var providerVersions protocol.ProviderVersionsResponse
+		readJSON(t, target, "v1/providers/hashicorp/test/versions", &providerVersions)
+		if len(providerVersions.Versions) != 1 {
+			t.Fatalf("Incorrect provider versions: %v", providerVersions)
+		}
+		var serviceDiscovery protocol.ServiceDiscoveryResponse
+		readJSON(t, target, ".well-known/terraform.json", &serviceDiscovery)
+	}
+}
+
+// countingStorage records the paths of all PutFile calls.
+type countingStorage struct {
+	storage.API
+
+	writes []storage.Path
+}
+
+func (c *countingStorage) PutFile(ctx context.Context, path storage.Path, contents []byte) error {
+	c.writes = append(c.writes, path)
+	return c.API.PutFile(ctx, path, contents)
+}
+
+func createProvider(t *testing.T, dataAPI metadata.API) {
+	t.Helper()
+	ctx := context.Background()
+	if err := dataAPI.PutProvider(ctx, provider.Addr{
+		Namespace: "opentofu",
+		Name:      "test",
+	}, provider.Metadata{
+		Versions: []provider.Version{
+			{
+				Version:   "v1.0.0",
+				Protocols: []string{"5.0"},
+				Targets: []provider.Target{
+					{
+						OS:       "linux",
+						Arch:     "amd64",
+						Filename: "terraform-provider-test_1.0.0_linux_amd64.zip",
+					},
+				},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataAPI.PutProviderNamespaceAlias(ctx, "hashicorp", "opentofu"); err != nil {
+		t.Fatal(err)
+	}
+	key, err := crypto.GenerateKey("Test", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dataAPI.PutProviderNamespaceKey(ctx, "opentofu", provider.Key{
+		ASCIIArmor: publicKey,
+		KeyID:      strings.ToUpper(key.GetHexKeyID()),
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readJSON(t *testing.T, target storage.API, path storage.Path, result any) {
+	t.Helper()
+	contents, err := target.GetFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Failed to read %s (%v)", path, err)
+	}
+	if err := json.Unmarshal(contents, result); err != nil {
+		t.Fatalf("Failed to parse %s (%v)", path, err)
+	}
+}
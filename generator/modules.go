@@ -0,0 +1,50 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package generator
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/opentofu/libregistry/protocol"
+)
+
+// generateModules creates the versions and download documents for all modules, keyed by the module address.
+func (g generator) generateModules(ctx context.Context) (map[string]documents, error) {
+	modules, err := g.dataAPI.GetAllModules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]documents, len(modules))
+	for moduleAddr, moduleMetadata := range modules {
+		moduleAddr = moduleAddr.Normalize()
+		docs := documents{}
+		basePath := path.Join(
+			strings.TrimPrefix(protocol.ModulesV1Path, "/"),
+			moduleAddr.Namespace,
+			moduleAddr.Name,
+			moduleAddr.TargetSystem,
+		)
+		if err := docs.put(path.Join(basePath, "versions"), protocol.NewModuleVersionsResponse(moduleMetadata)); err != nil {
+			return nil, err
+		}
+		for _, ver := range moduleMetadata.Versions {
+			location, err := g.config.ModuleSource(ctx, moduleAddr, moduleMetadata, ver)
+			if err != nil {
+				return nil, err
+			}
+			if err := docs.put(
+				path.Join(basePath, strings.TrimPrefix(string(ver.Version), "v"), "download"),
+				protocol.ModuleDownloadResponse{
+					Location: location,
+				},
+			); err != nil {
+				return nil, err
+			}
+		}
+		result[moduleAddr.String()] = docs
+	}
+	return result, nil
+}
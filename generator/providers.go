@@ -0,0 +1,76 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package generator
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/opentofu/libregistry/protocol"
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+// generateProviders creates the versions and download documents for all providers and their aliases, keyed by the
+// provider address.
+func (g generator) generateProviders(ctx context.Context) (map[string]documents, error) {
+	providers, err := g.dataAPI.GetAllProviders(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	namespaceKeys := map[string][]provider.Key{}
+	result := make(map[string]documents, len(providers))
+	for providerAddr, providerMetadata := range providers {
+		providerAddr = providerAddr.Normalize()
+		canonicalAddr, err := g.dataAPI.GetProviderCanonicalAddr(ctx, providerAddr)
+		if err != nil {
+			return nil, err
+		}
+		keys, ok := namespaceKeys[canonicalAddr.Namespace]
+		if !ok {
+			keys, err = g.getNamespaceKeys(ctx, canonicalAddr.Namespace)
+			if err != nil {
+				return nil, err
+			}
+			namespaceKeys[canonicalAddr.Namespace] = keys
+		}
+
+		docs := documents{}
+		basePath := path.Join(
+			strings.TrimPrefix(protocol.ProvidersV1Path, "/"),
+			providerAddr.Namespace,
+			providerAddr.Name,
+		)
+		if err := docs.put(path.Join(basePath, "versions"), protocol.NewProviderVersionsResponse(providerMetadata)); err != nil {
+			return nil, err
+		}
+		for _, ver := range providerMetadata.Versions {
+			for _, target := range ver.Targets {
+				if err := docs.put(
+					path.Join(basePath, strings.TrimPrefix(string(ver.Version), "v"), "download", target.OS, target.Arch),
+					protocol.NewProviderDownloadResponse(ver, target, keys),
+				); err != nil {
+					return nil, err
+				}
+			}
+		}
+		result[providerAddr.String()] = docs
+	}
+	return result, nil
+}
+
+func (g generator) getNamespaceKeys(ctx context.Context, namespace string) ([]provider.Key, error) {
+	keyIDs, err := g.dataAPI.ListProviderNamespaceKeyIDs(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]provider.Key, len(keyIDs))
+	for i, keyID := range keyIDs {
+		keys[i], err = g.dataAPI.GetProviderNamespaceKey(ctx, namespace, keyID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
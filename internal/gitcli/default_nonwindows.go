@@ -0,0 +1,9 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+
+package gitcli
+
+// DefaultGitPath is the name of the git binary looked up in the path when no git path is configured.
+const DefaultGitPath = "git"
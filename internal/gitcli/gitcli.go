@@ -0,0 +1,476 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package gitcli manages working copies of git repositories using the git command line. It is shared between the VCS
+// implementations that check out repositories over git.
+package gitcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/opentofu/libregistry/internal/retry"
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// Config holds the configuration for the git command line.
+type Config struct {
+	// GitPath holds the path to the git binary.
+	GitPath string
+	// CheckoutRootDirectory is the root directory where repositories should be checked out.
+	CheckoutRootDirectory string
+	// SkipCleanupWorkingCopyOnClose indicates that the working copy should not be cleaned up when it is closed.
+	SkipCleanupWorkingCopyOnClose bool
+	// MirrorDirectory enables the mirror cache if set. A bare mirror of each repository is kept in this directory
+	// and refreshed on every use. Working copies are cloned from the mirror instead of the remote.
+	MirrorDirectory string
+	// MirrorMaxSize is the maximum size of the mirror cache in bytes. The least recently used mirrors are evicted
+	// when the cache grows beyond this size. Zero means no limit.
+	MirrorMaxSize int64
+	// Logger holds the logger to write any logs to.
+	Logger logger.Logger
+}
+
+// New creates a new git command line wrapper. Working copies opened through the same instance are locked against
+// concurrent use.
+func New(config Config) *Git {
+	return &Git{
+		config: config,
+		lock:   &sync.Mutex{},
+		locks:  map[string]*sync.Mutex{},
+	}
+}
+
+// Git runs git commands and keeps track of the locks on the working copies.
+type Git struct {
+	config Config
+	lock   *sync.Mutex
+	locks  map[string]*sync.Mutex
+}
+
+// RepositoryExistsFunc is called when cloning a repository fails to determine if the repository exists at all.
+type RepositoryExistsFunc func(ctx context.Context) (bool, error)
+
+// Checkout opens the working copy of the repository and checks out the given version. The client is returned from
+// the Client() call of the working copy.
+func (g *Git) Checkout(
+	ctx context.Context,
+	client vcs.Client,
+	repository vcs.RepositoryAddr,
+	cloneURL string,
+	repositoryExists RepositoryExistsFunc,
+	version vcs.VersionNumber,
+) (vcs.WorkingCopy, error) {
+	if err := version.Validate(); err != nil {
+		return nil, err
+	}
+	wc, err := g.Open(ctx, client, repository, cloneURL, repositoryExists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to get working copy: %w", err))
+	}
+
+	tagExists, err := wc.TagExists(ctx, version)
+	if err != nil {
+		_ = wc.Close()
+		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to check if tag %s exists: %w", version, err))
+	}
+	if !tagExists {
+		_ = wc.Close()
+		return nil, &vcs.VersionNotFoundError{
+			RepositoryAddr: repository,
+			Version:        version,
+		}
+	}
+
+	if err := wc.reset(ctx); err != nil {
+		_ = wc.Close()
+		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to reset repository: %w", err))
+	}
+
+	if err := wc.clean(ctx); err != nil {
+		_ = wc.Close()
+		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to clean repository: %w", err))
+	}
+
+	if err := wc.checkout(ctx, version); err != nil {
+		_ = wc.Close()
+		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to check out tag %s: %w", version, err))
+	}
+	return wc, nil
+}
+
+// Open returns the locked working copy of the repository, cloning it from the clone URL if it is not present yet and
+// fetching all tags. The caller must call Close on the working copy to release the lock.
+func (g *Git) Open(
+	ctx context.Context,
+	client vcs.Client,
+	repository vcs.RepositoryAddr,
+	cloneURL string,
+	repositoryExists RepositoryExistsFunc,
+) (*WorkingCopy, error) {
+	if err := repository.Validate(); err != nil {
+		return nil, err
+	}
+	parentDirectory := path.Join(g.config.CheckoutRootDirectory, string(repository.Org))
+	checkoutDirectory := path.Join(parentDirectory, repository.Name)
+	gitDirectory := path.Join(checkoutDirectory, ".git")
+
+	g.lock.Lock()
+	lock, ok := g.locks[checkoutDirectory]
+	if !ok {
+		lock = &sync.Mutex{}
+		g.locks[checkoutDirectory] = lock
+	}
+	g.lock.Unlock()
+	lock.Lock()
+	cleanup := func() {
+		g.lock.Lock()
+
+		if !g.config.SkipCleanupWorkingCopyOnClose {
+			// Make sure that any open file descriptors are closed before cleaning up the directory so Windows file
+			// locking doesn't block the cleanup:
+			runtime.GC()
+
+			if err := os.RemoveAll(checkoutDirectory); err != nil {
+				g.config.Logger.Debug(ctx, "Failed to clean up clone repository at %s (%v)", checkoutDirectory, err)
+			}
+		}
+
+		delete(g.locks, checkoutDirectory)
+		lock.Unlock()
+		g.lock.Unlock()
+	}
+
+	if g.config.MirrorDirectory != "" {
+		mirrorDirectory, err := g.refreshMirror(ctx, repository, cloneURL, repositoryExists)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		g.evictMirrors(ctx, mirrorDirectory)
+	}
+
+	stat, err := os.Stat(gitDirectory)
+	if err != nil || !stat.IsDir() {
+		if err := os.RemoveAll(checkoutDirectory); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to remove broken checkout directory %s (%w)", checkoutDirectory, err)
+		}
+		if err := os.MkdirAll(parentDirectory, 0700); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to create checkout parent directory %s (%w)", parentDirectory, err)
+		}
+		cloneParams := []string{"clone", "--depth", "1", cloneURL, checkoutDirectory}
+		if g.config.MirrorDirectory != "" {
+			// Local clones hard link the objects of the mirror, so this is cheap and the working copy stays
+			// intact if the mirror is evicted.
+			cloneParams = []string{"clone", g.mirrorDirectory(repository), checkoutDirectory}
+		}
+		if err := g.Run(ctx, parentDirectory, nil, cloneParams...); err != nil {
+			cleanup()
+
+			// Clone failed, check if repository exists.
+			if repositoryExists != nil {
+				repoExists, e := repositoryExists(ctx)
+				if e == nil && !repoExists {
+					return nil, &vcs.RepositoryNotFoundError{RepositoryAddr: repository, Cause: err}
+				}
+			}
+
+			return nil, err
+		}
+	}
+
+	if err := g.Run(ctx, checkoutDirectory, nil, "fetch", "--tags", "--force"); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 128 {
+			cleanup()
+			return nil, &vcs.RepositoryNotFoundError{RepositoryAddr: repository, Cause: err}
+		}
+		cleanup()
+		return nil, err
+	}
+
+	return &WorkingCopy{
+		ReadDirFS:  os.DirFS(checkoutDirectory).(fs.ReadDirFS),
+		repository: repository,
+		dir:        checkoutDirectory,
+		cleanup:    cleanup,
+		git:        g,
+		client:     client,
+	}, nil
+}
+
+// WorkingCopy is a locked working copy of a repository.
+type WorkingCopy struct {
+	fs.ReadDirFS
+	cleanup    func()
+	repository vcs.RepositoryAddr
+	version    vcs.VersionNumber
+	dir        string
+	git        *Git
+	client     vcs.Client
+}
+
+func (w *WorkingCopy) Repository() vcs.RepositoryAddr {
+	return w.repository
+}
+
+func (w *WorkingCopy) Version() vcs.VersionNumber {
+	return w.version
+}
+
+func (w *WorkingCopy) Client() vcs.Client {
+	return w.client
+}
+
+func (w *WorkingCopy) RawDirectory() (string, error) {
+	return w.dir, nil
+}
+
+func (w *WorkingCopy) Close() error {
+	w.cleanup()
+	return nil
+}
+
+func is128Retryable(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == 128
+}
+
+func (w *WorkingCopy) checkout(ctx context.Context, version vcs.VersionNumber) error {
+	if err := retry.Func(
+		ctx,
+		"git checkout "+string(version),
+		func() error {
+			return w.git.Run(ctx, w.dir, nil, "checkout", string(version))
+		},
+		is128Retryable,
+		10,
+		100*time.Millisecond,
+		w.git.config.Logger,
+	); err != nil {
+		if !is128Retryable(err) {
+			// Checkout failed, see if tag exists.
+			tagExists, e := w.TagExists(ctx, version)
+			if e == nil && !tagExists {
+				return &vcs.VersionNotFoundError{Version: version, RepositoryAddr: w.repository, Cause: err}
+			}
+
+		}
+		return err
+	}
+	w.version = version
+	return nil
+}
+
+func (w *WorkingCopy) reset(ctx context.Context) error {
+	return retry.Func(
+		ctx,
+		"git reset --hard",
+		func() error {
+			return w.git.Run(ctx, w.dir, nil, "reset", "--hard")
+		},
+		is128Retryable,
+		10,
+		100*time.Millisecond,
+		w.git.config.Logger,
+	)
+}
+
+func (w *WorkingCopy) clean(ctx context.Context) error {
+	return retry.Func(
+		ctx,
+		"git clean -fd",
+		func() error {
+			return w.git.Run(ctx, w.dir, nil, "clean", "-fd")
+		},
+		is128Retryable,
+		10,
+		100*time.Millisecond,
+		w.git.config.Logger,
+	)
+}
+
+// TagExists returns true if the tag exists in the working copy.
+func (w *WorkingCopy) TagExists(ctx context.Context, version vcs.VersionNumber) (bool, error) {
+	tags, err := w.ListTags(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, tag := range tags {
+		if tag.VersionNumber.Equals(version) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetTag returns the version information of a single tag.
+func (w *WorkingCopy) GetTag(ctx context.Context, tag vcs.VersionNumber) (vcs.Version, error) {
+	tags, err := w.ListTags(ctx)
+	if err != nil {
+		return vcs.Version{}, err
+	}
+	for _, t := range tags {
+		if t.VersionNumber.Equals(tag) {
+			return t, nil
+		}
+	}
+	return vcs.Version{}, &vcs.VersionNotFoundError{
+		RepositoryAddr: w.repository,
+		Version:        tag,
+	}
+}
+
+// ListTags lists all tags in the working copy that are valid version numbers.
+func (w *WorkingCopy) ListTags(ctx context.Context) ([]vcs.Version, error) {
+	stdout, err := w.listRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(stdout.String(), "\n")
+	var result []vcs.Version
+	for _, line := range lines {
+		// The peeled object name is empty for lightweight tags, so only the line ending may be trimmed here.
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "\t", 4)
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("line does not contain enough parts to parse: %s", line)
+		}
+		tag := vcs.VersionNumber(strings.ReplaceAll(parts[0], "refs/tags/", ""))
+		unixTime, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse git output: %s (%v)", line, err)
+		}
+		created := time.Unix(int64(unixTime), 0)
+		// Annotated tags point to a tag object, the peeled object name is the commit in this case.
+		commit := parts[3]
+		if commit == "" {
+			commit = parts[2]
+		}
+		ver := vcs.Version{
+			VersionNumber: tag,
+			Created:       created,
+			Commit:        commit,
+		}
+		if err := ver.Validate(); err != nil {
+			w.git.config.Logger.Debug(ctx, "Skipping tag %s because it does not match the naming rules.", ver.VersionNumber)
+			continue
+		}
+		result = append(result, ver)
+	}
+	return result, nil
+}
+
+func (w *WorkingCopy) listRefs(ctx context.Context) (*bytes.Buffer, error) {
+	return retry.Func2(
+		ctx,
+		fmt.Sprintf("git for-each-ref: %s", w.dir),
+		func() (*bytes.Buffer, error) {
+			stdout := &bytes.Buffer{}
+			err := w.git.Run(ctx, w.dir, stdout, "for-each-ref", "--format=%(refname:short)\t%(creatordate:format:%s)\t%(objectname)\t%(*objectname)", "refs/tags/*")
+			return stdout, err
+		},
+		is128Retryable,
+		10,
+		100*time.Millisecond,
+		w.git.config.Logger,
+	)
+}
+
+// Run runs a git command in the given directory. If stdout is nil, the output is written to the debug log.
+func (g *Git) Run(ctx context.Context, dir string, stdout io.Writer, params ...string) error {
+	params = append([]string{"-c", "credential.helper="}, params...)
+	cmd := exec.Command(g.config.GitPath, params...)
+	commandString := strings.Join(append([]string{g.config.GitPath}, redactParams(params)...), " ")
+	logger.LogTrace(ctx, g.config.Logger, "Running "+commandString)
+	if stdout == nil {
+		stdout = logger.NewWriter(ctx, g.config.Logger, logger.LevelDebug, dir+"> "+commandString+": ")
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = logger.NewWriter(ctx, g.config.Logger, logger.LevelDebug, dir+"> "+commandString+": ")
+	cmd.Dir = dir
+	cmd.Env = []string{"GIT_TERMINAL_PROMPT=0"}
+	done := make(chan struct{})
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run %s (%w)", commandString, err)
+	}
+	var lastErr error
+	go func() {
+		defer close(done)
+		lastErr = cmd.Wait()
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		_ = cmd.Process.Signal(syscall.SIGTERM)
+		select {
+		case <-time.After(30 * time.Second):
+			_ = cmd.Process.Kill()
+		case <-done:
+		}
+		<-done
+	}
+	if lastErr == nil {
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(lastErr, &exitErr) {
+		return fmt.Errorf("%s failed (%w)", commandString, lastErr)
+	}
+	if exitErr.ExitCode() != 0 {
+		return fmt.Errorf("%s exited with exit code %d (%w)", commandString, exitErr.ExitCode(), exitErr)
+	}
+	return nil
+}
+
+// httpExtraHeaderParam is the prefix of the config parameter that passes an HTTP header to git, for example to
+// authenticate.
+const httpExtraHeaderParam = "http.extraHeader="
+
+// redactParams returns a copy of the git parameters with the values of HTTP headers hidden, so credentials passed to a
+// command do not end up in logs or error messages.
+func redactParams(params []string) []string {
+	result := make([]string, len(params))
+	for i, param := range params {
+		if strings.HasPrefix(param, httpExtraHeaderParam) {
+			param = httpExtraHeaderParam + "***"
+		}
+		result[i] = param
+	}
+	return result
+}
+
+// ValidateGitPath checks if the git binary at the given path is usable.
+func ValidateGitPath(path string) error {
+	cmd := exec.Command(path, "version")
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.ExitCode() != 0 {
+				return fmt.Errorf("git binary %s is not usable (git version exited with %d)", path, exitErr.ExitCode())
+			}
+		} else {
+			return fmt.Errorf("git binary %s is not usable (%w)", path, err)
+		}
+	}
+	return nil
+}
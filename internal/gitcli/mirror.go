@@ -0,0 +1,203 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package gitcli
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentofu/libregistry/vcs"
+)
+
+func (g *Git) mirrorDirectory(repository vcs.RepositoryAddr) string {
+	return path.Join(g.config.MirrorDirectory, string(repository.Org), repository.Name+".git")
+}
+
+// refreshMirror creates or updates the bare mirror of the repository and marks it as recently used. The caller must
+// hold the lock for the repository.
+func (g *Git) refreshMirror(
+	ctx context.Context,
+	repository vcs.RepositoryAddr,
+	cloneURL string,
+	repositoryExists RepositoryExistsFunc,
+) (string, error) {
+	mirrorDirectory := g.mirrorDirectory(repository)
+	// The mirror is kept on disk indefinitely, so the credentials are passed to each command instead of being
+	// written to its config as part of the remote URL.
+	remoteURL, credentialParams := splitCredentials(cloneURL)
+	stat, err := os.Stat(mirrorDirectory)
+	if err != nil || !stat.IsDir() {
+		if err := os.RemoveAll(mirrorDirectory); err != nil {
+			return "", fmt.Errorf("failed to remove broken mirror directory %s (%w)", mirrorDirectory, err)
+		}
+		parentDirectory := path.Dir(mirrorDirectory)
+		if err := os.MkdirAll(parentDirectory, 0700); err != nil {
+			return "", fmt.Errorf("failed to create mirror parent directory %s (%w)", parentDirectory, err)
+		}
+		cloneParams := append(credentialParams, "clone", "--mirror", remoteURL, mirrorDirectory)
+		if err := g.Run(ctx, parentDirectory, nil, cloneParams...); err != nil {
+			_ = os.RemoveAll(mirrorDirectory)
+			if repositoryExists != nil {
+				repoExists, e := repositoryExists(ctx)
+				if e == nil && !repoExists {
+					return "", &vcs.RepositoryNotFoundError{RepositoryAddr: repository, Cause: err}
+				}
+			}
+			return "", err
+		}
+	} else {
+		// Setting the URL on every fetch follows changes to the clone URL and removes credentials stored in the
+		// config of mirrors created by earlier versions.
+		if err := g.Run(ctx, mirrorDirectory, nil, "remote", "set-url", "origin", remoteURL); err != nil {
+			return "", err
+		}
+		fetchParams := append(credentialParams, "fetch", "--prune", "--tags", "--force", "origin")
+		if err := g.Run(ctx, mirrorDirectory, nil, fetchParams...); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitCode() == 128 && repositoryExists != nil {
+				repoExists, e := repositoryExists(ctx)
+				if e == nil && !repoExists {
+					return "", &vcs.RepositoryNotFoundError{RepositoryAddr: repository, Cause: err}
+				}
+			}
+			return "", err
+		}
+	}
+	now := time.Now()
+	if err := os.Chtimes(mirrorDirectory, now, now); err != nil {
+		g.config.Logger.Debug(ctx, "Failed to update the last use time of mirror %s (%v)", mirrorDirectory, err)
+	}
+	return mirrorDirectory, nil
+}
+
+// splitCredentials removes the credentials from the clone URL. It returns the URL without credentials and the git
+// parameters that pass the credentials to a single command as an HTTP authorization header.
+func splitCredentials(cloneURL string) (string, []string) {
+	u, err := url.Parse(cloneURL)
+	if err != nil || u.User == nil {
+		return cloneURL, nil
+	}
+	password, _ := u.User.Password()
+	credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+	u.User = nil
+	return u.String(), []string{"-c", httpExtraHeaderParam + "Authorization: Basic " + credentials}
+}
+
+type mirror struct {
+	directory         string
+	checkoutDirectory string
+	size              int64
+	lastUsed          time.Time
+}
+
+// evictMirrors removes the least recently used mirrors until the mirror cache fits into MirrorMaxSize. The current
+// mirror and mirrors of repositories that are in use are never evicted.
+func (g *Git) evictMirrors(ctx context.Context, currentMirrorDirectory string) {
+	if g.config.MirrorMaxSize <= 0 {
+		return
+	}
+
+	// Walking the mirrors for their size takes a while, so it happens before taking the lock that blocks opening and
+	// closing working copies.
+	mirrors, err := g.listMirrors()
+	if err != nil {
+		g.config.Logger.Warn(ctx, "Failed to list mirrors in %s, skipping eviction (%v)", g.config.MirrorDirectory, err)
+		return
+	}
+	var totalSize int64
+	for _, m := range mirrors {
+		totalSize += m.size
+	}
+	slices.SortFunc(mirrors, func(a, b mirror) int {
+		return a.lastUsed.Compare(b.lastUsed)
+	})
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	for _, m := range mirrors {
+		if totalSize <= g.config.MirrorMaxSize {
+			return
+		}
+		if m.directory == currentMirrorDirectory {
+			continue
+		}
+		if _, inUse := g.locks[m.checkoutDirectory]; inUse {
+			continue
+		}
+		g.config.Logger.Debug(ctx, "Evicting mirror %s (%d bytes)", m.directory, m.size)
+		if err := os.RemoveAll(m.directory); err != nil {
+			g.config.Logger.Warn(ctx, "Failed to evict mirror %s (%v)", m.directory, err)
+			continue
+		}
+		totalSize -= m.size
+	}
+}
+
+func (g *Git) listMirrors() ([]mirror, error) {
+	orgs, err := os.ReadDir(g.config.MirrorDirectory)
+	if err != nil {
+		return nil, err
+	}
+	var result []mirror
+	for _, org := range orgs {
+		if !org.IsDir() {
+			continue
+		}
+		repos, err := os.ReadDir(path.Join(g.config.MirrorDirectory, org.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range repos {
+			if !repo.IsDir() || !strings.HasSuffix(repo.Name(), ".git") {
+				continue
+			}
+			info, err := repo.Info()
+			if err != nil {
+				return nil, err
+			}
+			directory := path.Join(g.config.MirrorDirectory, org.Name(), repo.Name())
+			size, err := directorySize(directory)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, mirror{
+				directory:         directory,
+				checkoutDirectory: path.Join(g.config.CheckoutRootDirectory, org.Name(), strings.TrimSuffix(repo.Name(), ".git")),
+				size:              size,
+				lastUsed:          info.ModTime(),
+			})
+		}
+	}
+	return result, nil
+}
+
+func directorySize(directory string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(directory, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,133 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package gitcli_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/libregistry/internal/gitcli"
+	"github.com/opentofu/libregistry/internal/gittest"
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+func TestMirrorCache(t *testing.T) {
+	ctx := context.Background()
+	remoteRoot := t.TempDir()
+	mirrorRoot := t.TempDir()
+	repoA := vcs.RepositoryAddr{Org: "opentofu", Name: "repo-a"}
+	repoB := vcs.RepositoryAddr{Org: "opentofu", Name: "repo-b"}
+	gittest.CreateRepository(t, remoteRoot, repoA, map[string]string{"main.tf": ""}, "v1.0.0")
+	gittest.CreateRepository(t, remoteRoot, repoB, map[string]string{"main.tf": ""}, "v1.0.0")
+	cloneURL := func(repository vcs.RepositoryAddr) string {
+		return "file://" + filepath.ToSlash(filepath.Join(remoteRoot, string(repository.Org), repository.Name+".git"))
+	}
+
+	git := gitcli.New(gitcli.Config{
+		GitPath:               "git",
+		CheckoutRootDirectory: t.TempDir(),
+		MirrorDirectory:       mirrorRoot,
+		// Any mirror exceeds this size, so only the mirror in use is kept.
+		MirrorMaxSize: 1,
+		Logger:        logger.NewTestLogger(t),
+	})
+	listTags := func(repository vcs.RepositoryAddr) []vcs.Version {
+		t.Helper()
+		wc, err := git.Open(ctx, nil, repository, cloneURL(repository), nil)
+		if err != nil {
+			t.Fatalf("❌ Failed to open working copy for %s (%v)", repository, err)
+		}
+		defer func() {
+			if err := wc.Close(); err != nil {
+				t.Fatalf("❌ Failed to close working copy (%v)", err)
+			}
+		}()
+		tags, err := wc.ListTags(ctx)
+		if err != nil {
+			t.Fatalf("❌ Failed to list tags for %s (%v)", repository, err)
+		}
+		return tags
+	}
+	mirrorExists := func(repository vcs.RepositoryAddr) bool {
+		_, err := os.Stat(filepath.Join(mirrorRoot, string(repository.Org), repository.Name+".git"))
+		return err == nil
+	}
+
+	if tags := listTags(repoA); len(tags) != 1 {
+		t.Fatalf("❌ Incorrect tags returned: %v", tags)
+	}
+	if !mirrorExists(repoA) {
+		t.Fatalf("❌ No mirror created for %s.", repoA)
+	}
+
+	t.Logf("⚙️ Adding a tag to %s and checking if the mirror picks it up...", repoA)
+	gittest.Run(t, filepath.Join(remoteRoot, string(repoA.Org), repoA.Name+".git"), "tag", "v1.1.0", "v1.0.0")
+	if tags := listTags(repoA); len(tags) != 2 {
+		t.Fatalf("❌ The mirror was not refreshed, incorrect tags returned: %v", tags)
+	}
+
+	t.Logf("⚙️ Using %s and checking if the mirror of %s is evicted...", repoB, repoA)
+	if tags := listTags(repoB); len(tags) != 1 {
+		t.Fatalf("❌ Incorrect tags returned: %v", tags)
+	}
+	if mirrorExists(repoA) {
+		t.Fatalf("❌ The least recently used mirror of %s was not evicted.", repoA)
+	}
+	if !mirrorExists(repoB) {
+		t.Fatalf("❌ The mirror of %s in use was evicted.", repoB)
+	}
+	t.Logf("✅ The mirror cache works as intended.")
+}
+
+// TestMirrorCredentials tests that the credentials in the clone URL are not stored in the config of the mirror.
+func TestMirrorCredentials(t *testing.T) {
+	ctx := context.Background()
+	remoteRoot := t.TempDir()
+	mirrorRoot := t.TempDir()
+	repo := vcs.RepositoryAddr{Org: "opentofu", Name: "repo"}
+	gittest.CreateRepository(t, remoteRoot, repo, map[string]string{"main.tf": ""}, "v1.0.0")
+	cloneURL := "file://user:secret@" + filepath.ToSlash(filepath.Join(remoteRoot, string(repo.Org), repo.Name+".git"))
+	mirrorDirectory := filepath.Join(mirrorRoot, string(repo.Org), repo.Name+".git")
+
+	git := gitcli.New(gitcli.Config{
+		GitPath:               "git",
+		CheckoutRootDirectory: t.TempDir(),
+		MirrorDirectory:       mirrorRoot,
+		Logger:                logger.NewTestLogger(t),
+	})
+	open := func() {
+		t.Helper()
+		wc, err := git.Open(ctx, nil, repo, cloneURL, nil)
+		if err != nil {
+			t.Fatalf("❌ Failed to open working copy (%v)", err)
+		}
+		if err := wc.Close(); err != nil {
+			t.Fatalf("❌ Failed to close working copy (%v)", err)
+		}
+	}
+	checkConfig := func() {
+		t.Helper()
+		config, err := os.ReadFile(filepath.Join(mirrorDirectory, "config"))
+		if err != nil {
+			t.Fatalf("❌ Failed to read the mirror config (%v)", err)
+		}
+		if strings.Contains(string(config), "secret") {
+			t.Fatalf("❌ The mirror config contains the credentials:\n%s", config)
+		}
+	}
+
+	t.Logf("⚙️ Creating the mirror...")
+	open()
+	checkConfig()
+
+	t.Logf("⚙️ Storing credentials in the mirror config like earlier versions did and refreshing the mirror...")
+	gittest.Run(t, mirrorDirectory, "remote", "set-url", "origin", cloneURL)
+	open()
+	checkConfig()
+	t.Logf("✅ The mirror config holds no credentials.")
+}
@@ -0,0 +1,86 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package gittest provides helpers for testing VCS implementations against local git repositories served over HTTP.
+package gittest
+
+import (
+	"net/http"
+	"net/http/cgi"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// CommitDate is the author and committer date of all commits and tags created by this package, so tests can check
+// the creation dates of versions.
+var CommitDate = time.Date(2024, 7, 8, 16, 55, 36, 0, time.UTC)
+
+// CreateRepository creates a bare repository at root/org/name.git. The files are added in the first commit and each
+// tag is created on a separate, empty commit on top of it.
+func CreateRepository(t *testing.T, root string, repository vcs.RepositoryAddr, files map[string]string, tags ...vcs.VersionNumber) {
+	t.Helper()
+
+	workDir := t.TempDir()
+	for name, contents := range files {
+		fileName := filepath.Join(workDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fileName), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fileName, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	Run(t, workDir, "init", "--initial-branch=main")
+	Run(t, workDir, "add", "--all")
+	Run(t, workDir, "commit", "--allow-empty", "-m", "Initial commit")
+	for _, tag := range tags {
+		Run(t, workDir, "commit", "--allow-empty", "-m", "Release "+string(tag))
+		Run(t, workDir, "tag", string(tag))
+	}
+
+	bareDir := filepath.Join(root, string(repository.Org), repository.Name+".git")
+	if err := os.MkdirAll(filepath.Dir(bareDir), 0700); err != nil {
+		t.Fatal(err)
+	}
+	Run(t, root, "clone", "--bare", workDir, bareDir)
+}
+
+// Run runs a git command in the given directory and fails the test if it fails.
+func Run(t *testing.T, dir string, params ...string) {
+	t.Helper()
+	params = append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false", "-c", "tag.gpgsign=false"}, params...)
+	cmd := exec.Command("git", params...)
+	cmd.Dir = dir
+	cmd.Env = append(
+		os.Environ(),
+		"GIT_AUTHOR_DATE="+CommitDate.Format(time.RFC3339),
+		"GIT_COMMITTER_DATE="+CommitDate.Format(time.RFC3339),
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed (%v)\n%s", params, err, output)
+	}
+}
+
+// NewHandler returns an HTTP handler serving all repositories below the root directory over the smart HTTP protocol
+// using git http-backend.
+func NewHandler(t *testing.T, root string) http.Handler {
+	t.Helper()
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skipf("git is not available (%v)", err)
+	}
+	return &cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Root: "/",
+		Env: []string{
+			"GIT_PROJECT_ROOT=" + root,
+			"GIT_HTTP_EXPORT_ALL=1",
+		},
+	}
+}
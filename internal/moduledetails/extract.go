@@ -0,0 +1,218 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package moduledetails extracts the documentation of a module, such as its variables and outputs, from the module
+// source code.
+package moduledetails
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	submodulesDirectory = "modules"
+	examplesDirectory   = "examples"
+)
+
+// Extract reads the module in the root of the given filesystem, as well as its submodules and examples. Files that
+// cannot be parsed are recorded in the ParseErrors of the affected directory instead of failing the extraction, so
+// only errors reading the filesystem are returned.
+func Extract(fsys fs.FS) (module.VersionDetails, error) {
+	root, err := extractDirectory(fsys, ".")
+	if err != nil {
+		return module.VersionDetails{}, err
+	}
+	result := module.VersionDetails{
+		Root: root,
+	}
+	if result.Submodules, err = extractChildren(fsys, submodulesDirectory); err != nil {
+		return module.VersionDetails{}, err
+	}
+	if result.Examples, err = extractChildren(fsys, examplesDirectory); err != nil {
+		return module.VersionDetails{}, err
+	}
+	return result, nil
+}
+
+func extractChildren(fsys fs.FS, dir string) ([]module.Details, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read directory %s (%w)", dir, err)
+	}
+	var result []module.Details
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		details, err := extractDirectory(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, details)
+	}
+	return result, nil
+}
+
+func extractDirectory(fsys fs.FS, dir string) (module.Details, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return module.Details{}, fmt.Errorf("failed to read directory %s (%w)", dir, err)
+	}
+	result := module.Details{}
+	if dir != "." {
+		result.Path = dir
+	}
+	providers := map[string]*module.RequiredProvider{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		switch {
+		case strings.EqualFold(name, "README.md"):
+			contents, err := fs.ReadFile(fsys, path.Join(dir, name))
+			if err != nil {
+				return module.Details{}, fmt.Errorf("failed to read %s (%w)", path.Join(dir, name), err)
+			}
+			result.README = string(contents)
+		case strings.HasSuffix(name, ".tf"):
+			filename := path.Join(dir, name)
+			src, err := fs.ReadFile(fsys, filename)
+			if err != nil {
+				return module.Details{}, fmt.Errorf("failed to read %s (%w)", filename, err)
+			}
+			file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+			if diags.HasErrors() {
+				result.ParseErrors = append(result.ParseErrors, diags.Error())
+				continue
+			}
+			extractFile(file.Body.(*hclsyntax.Body), src, &result, providers)
+		}
+	}
+	for _, provider := range providers {
+		result.RequiredProviders = append(result.RequiredProviders, *provider)
+	}
+	slices.SortFunc(result.RequiredProviders, func(a, b module.RequiredProvider) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result, nil
+}
+
+func extractFile(body *hclsyntax.Body, src []byte, result *module.Details, providers map[string]*module.RequiredProvider) {
+	for _, block := range body.Blocks {
+		switch {
+		case block.Type == "variable" && len(block.Labels) == 1:
+			result.Variables = append(result.Variables, extractVariable(block, src))
+		case block.Type == "output" && len(block.Labels) == 1:
+			result.Outputs = append(result.Outputs, module.Output{
+				Name:        block.Labels[0],
+				Description: stringAttribute(block.Body, "description", src),
+				Sensitive:   boolAttribute(block.Body, "sensitive"),
+			})
+		case block.Type == "terraform":
+			if attr, ok := block.Body.Attributes["required_version"]; ok {
+				result.RequiredVersion = append(result.RequiredVersion, stringValue(attr.Expr, src))
+			}
+			for _, child := range block.Body.Blocks {
+				if child.Type == "required_providers" {
+					extractRequiredProviders(child.Body, src, providers)
+				}
+			}
+		}
+	}
+}
+
+func extractVariable(block *hclsyntax.Block, src []byte) module.Variable {
+	variable := module.Variable{
+		Name:        block.Labels[0],
+		Description: stringAttribute(block.Body, "description", src),
+		Sensitive:   boolAttribute(block.Body, "sensitive"),
+		Required:    true,
+	}
+	if attr, ok := block.Body.Attributes["type"]; ok {
+		variable.Type = sourceText(attr.Expr, src)
+	}
+	if attr, ok := block.Body.Attributes["default"]; ok {
+		variable.Default = sourceText(attr.Expr, src)
+		variable.Required = false
+	}
+	return variable
+}
+
+// extractRequiredProviders reads a required_providers block. Each entry is either an object with the source and
+// version, or a plain version constraint in the legacy syntax.
+func extractRequiredProviders(body *hclsyntax.Body, src []byte, providers map[string]*module.RequiredProvider) {
+	for name, attr := range body.Attributes {
+		provider, ok := providers[name]
+		if !ok {
+			provider = &module.RequiredProvider{
+				Name: name,
+			}
+			providers[name] = provider
+		}
+		pairs, diags := hcl.ExprMap(attr.Expr)
+		if diags.HasErrors() {
+			provider.VersionConstraints = append(provider.VersionConstraints, stringValue(attr.Expr, src))
+			continue
+		}
+		for _, pair := range pairs {
+			key := hcl.ExprAsKeyword(pair.Key)
+			if key == "" {
+				key = stringValue(pair.Key, src)
+			}
+			switch key {
+			case "source":
+				provider.Source = stringValue(pair.Value, src)
+			case "version":
+				provider.VersionConstraints = append(provider.VersionConstraints, stringValue(pair.Value, src))
+			}
+		}
+	}
+}
+
+func stringAttribute(body *hclsyntax.Body, name string, src []byte) string {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return ""
+	}
+	return stringValue(attr.Expr, src)
+}
+
+func boolAttribute(body *hclsyntax.Body, name string) bool {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return false
+	}
+	value, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || !value.IsKnown() || value.IsNull() || value.Type() != cty.Bool {
+		return false
+	}
+	return value.True()
+}
+
+// stringValue returns the value of a literal string expression. Other expressions, such as references, are returned
+// as written in the source code.
+func stringValue(expr hcl.Expression, src []byte) string {
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() || !value.IsKnown() || value.IsNull() || value.Type() != cty.String {
+		return sourceText(expr, src)
+	}
+	return value.AsString()
+}
+
+func sourceText(expr hcl.Expression, src []byte) string {
+	return string(expr.Range().SliceBytes(src))
+}
@@ -0,0 +1,113 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package moduledetails_test
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opentofu/libregistry/internal/moduledetails"
+	"github.com/opentofu/libregistry/types/module"
+)
+
+func TestExtract(t *testing.T) {
+	fsys := fstest.MapFS{
+		"README.md": {Data: []byte("# VPC module\n")},
+		"main.tf": {Data: []byte(`
+terraform {
+  required_version = ">= 1.6.0"
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+    random = "~> 3.0"
+  }
+}
+`)},
+		"variables.tf": {Data: []byte(`
+variable "cidr" {
+  type        = string
+  description = "The CIDR block of the VPC."
+}
+
+variable "tags" {
+  type    = map(string)
+  default = {}
+}
+`)},
+		"outputs.tf": {Data: []byte(`
+output "id" {
+  description = "The ID of the VPC."
+  value       = aws_vpc.this.id
+}
+
+output "secret" {
+  value     = var.cidr
+  sensitive = true
+}
+`)},
+		"modules/subnet/main.tf": {Data: []byte(`
+variable "vpc_id" {}
+`)},
+		"examples/simple/main.tf": {Data: []byte(`
+module "vpc" {
+  source = "../.."
+`)},
+	}
+
+	details, err := moduledetails.Extract(fsys)
+	if err != nil {
+		t.Fatalf("❌ Extraction failed (%v)", err)
+	}
+
+	root := details.Root
+	if root.README != "# VPC module\n" {
+		t.Fatalf("❌ Incorrect README: %q", root.README)
+	}
+	expectedVariables := []module.Variable{
+		{Name: "cidr", Type: "string", Description: "The CIDR block of the VPC.", Required: true},
+		{Name: "tags", Type: "map(string)", Default: "{}"},
+	}
+	if !slices.Equal(root.Variables, expectedVariables) {
+		t.Fatalf("❌ Incorrect variables: %v", root.Variables)
+	}
+	expectedOutputs := []module.Output{
+		{Name: "id", Description: "The ID of the VPC."},
+		{Name: "secret", Sensitive: true},
+	}
+	if !slices.Equal(root.Outputs, expectedOutputs) {
+		t.Fatalf("❌ Incorrect outputs: %v", root.Outputs)
+	}
+	if !slices.Equal(root.RequiredVersion, []string{">= 1.6.0"}) {
+		t.Fatalf("❌ Incorrect required version: %v", root.RequiredVersion)
+	}
+	if len(root.RequiredProviders) != 2 {
+		t.Fatalf("❌ Incorrect required providers: %v", root.RequiredProviders)
+	}
+	if aws := root.RequiredProviders[0]; aws.Name != "aws" || aws.Source != "hashicorp/aws" || !slices.Equal(aws.VersionConstraints, []string{"~> 5.0"}) {
+		t.Fatalf("❌ Incorrect aws provider: %v", aws)
+	}
+	if random := root.RequiredProviders[1]; random.Name != "random" || random.Source != "" || !slices.Equal(random.VersionConstraints, []string{"~> 3.0"}) {
+		t.Fatalf("❌ Incorrect random provider: %v", random)
+	}
+	t.Logf("✅ The root module details are correct.")
+
+	if len(details.Submodules) != 1 || details.Submodules[0].Path != "modules/subnet" {
+		t.Fatalf("❌ Incorrect submodules: %v", details.Submodules)
+	}
+	if !slices.Equal(details.Submodules[0].Variables, []module.Variable{{Name: "vpc_id", Required: true}}) {
+		t.Fatalf("❌ Incorrect submodule variables: %v", details.Submodules[0].Variables)
+	}
+	t.Logf("✅ The submodule details are correct.")
+
+	if len(details.Examples) != 1 || details.Examples[0].Path != "examples/simple" {
+		t.Fatalf("❌ Incorrect examples: %v", details.Examples)
+	}
+	if len(details.Examples[0].ParseErrors) != 1 {
+		t.Fatalf("❌ The broken example did not result in a parse error: %v", details.Examples[0].ParseErrors)
+	}
+	t.Logf("✅ The broken example is recorded with a parse error.")
+}
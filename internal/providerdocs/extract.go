@@ -0,0 +1,188 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package providerdocs collects the documentation pages of a provider from its source code.
+package providerdocs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+// layout describes where a documentation tree keeps each type of page.
+type layout struct {
+	root        string
+	overview    string
+	directories map[string]provider.DocType
+}
+
+// layouts holds the supported documentation trees in order of preference. The docs/ directory is the current layout,
+// website/docs/ is the legacy one.
+var layouts = []layout{
+	{
+		root:     "docs",
+		overview: "index",
+		directories: map[string]provider.DocType{
+			"resources":    provider.DocTypeResource,
+			"data-sources": provider.DocTypeDataSource,
+			"guides":       provider.DocTypeGuide,
+			"functions":    provider.DocTypeFunction,
+		},
+	},
+	{
+		root:     "website/docs",
+		overview: "index",
+		directories: map[string]provider.DocType{
+			"r":         provider.DocTypeResource,
+			"d":         provider.DocTypeDataSource,
+			"guides":    provider.DocTypeGuide,
+			"functions": provider.DocTypeFunction,
+		},
+	},
+}
+
+// docExtensions lists the file extensions of documentation pages. Longer extensions come first so they are stripped
+// entirely from the slug.
+var docExtensions = []string{".html.markdown", ".html.md", ".markdown", ".md"}
+
+// Extract collects the documentation pages from the first documentation tree found in the given filesystem. It
+// returns no pages if the provider has no documentation.
+func Extract(fsys fs.FS) ([]provider.Doc, error) {
+	for _, l := range layouts {
+		if _, err := fs.Stat(fsys, l.root); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s (%w)", l.root, err)
+		}
+		return l.extract(fsys)
+	}
+	return nil, nil
+}
+
+func (l layout) extract(fsys fs.FS) ([]provider.Doc, error) {
+	entries, err := fs.ReadDir(fsys, l.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s (%w)", l.root, err)
+	}
+	var result []provider.Doc
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			if slug, ok := docSlug(entry.Name()); ok && slug == l.overview {
+				doc, err := readDoc(fsys, path.Join(l.root, entry.Name()), provider.DocTypeOverview, slug)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, doc)
+			}
+			continue
+		}
+		docType, ok := l.directories[entry.Name()]
+		if !ok {
+			continue
+		}
+		docs, err := extractDirectory(fsys, path.Join(l.root, entry.Name()), docType)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, docs...)
+	}
+	return result, nil
+}
+
+func extractDirectory(fsys fs.FS, dir string, docType provider.DocType) ([]provider.Doc, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s (%w)", dir, err)
+	}
+	var result []provider.Doc
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		slug, ok := docSlug(entry.Name())
+		if !ok {
+			continue
+		}
+		doc, err := readDoc(fsys, path.Join(dir, entry.Name()), docType, slug)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, doc)
+	}
+	return result, nil
+}
+
+func readDoc(fsys fs.FS, file string, docType provider.DocType, slug string) (provider.Doc, error) {
+	contents, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return provider.Doc{}, fmt.Errorf("failed to read %s (%w)", file, err)
+	}
+	frontmatter, content := splitFrontmatter(string(contents))
+	return provider.Doc{
+		DocItem: provider.DocItem{
+			Type:        docType,
+			Slug:        slug,
+			Title:       frontmatter["page_title"],
+			Subcategory: frontmatter["subcategory"],
+			Description: frontmatter["description"],
+			Path:        file,
+		},
+		Content: content,
+	}, nil
+}
+
+func docSlug(fileName string) (string, bool) {
+	for _, extension := range docExtensions {
+		if strings.HasSuffix(fileName, extension) {
+			return strings.TrimSuffix(fileName, extension), true
+		}
+	}
+	return "", false
+}
+
+// splitFrontmatter separates the YAML frontmatter from the page contents. Only the flat key-value pairs used in
+// provider docs are supported, including block scalars such as "description: |-".
+func splitFrontmatter(contents string) (map[string]string, string) {
+	contents = strings.ReplaceAll(contents, "\r\n", "\n")
+	if !strings.HasPrefix(contents, "---\n") {
+		return nil, contents
+	}
+	end := strings.Index(contents[4:], "\n---")
+	if end == -1 {
+		return nil, contents
+	}
+	header := contents[4 : 4+end]
+	content := strings.TrimPrefix(contents[4+end+4:], "\n")
+
+	result := map[string]string{}
+	lines := strings.Split(header, "\n")
+	for i := 0; i < len(lines); i++ {
+		key, value, ok := strings.Cut(lines[i], ":")
+		if !ok || strings.HasPrefix(key, " ") || strings.HasPrefix(key, "\t") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "|" || value == "|-" || value == ">" || value == ">-" {
+			var block []string
+			for i+1 < len(lines) && (strings.HasPrefix(lines[i+1], " ") || strings.HasPrefix(lines[i+1], "\t") || lines[i+1] == "") {
+				i++
+				block = append(block, strings.TrimSpace(lines[i]))
+			}
+			separator := "\n"
+			if strings.HasPrefix(value, ">") {
+				separator = " "
+			}
+			value = strings.TrimSpace(strings.Join(block, separator))
+		} else if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		result[strings.TrimSpace(key)] = value
+	}
+	return result, content
+}
@@ -0,0 +1,81 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package providerdocs_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/opentofu/libregistry/internal/providerdocs"
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+func TestExtractLegacyLayout(t *testing.T) {
+	fsys := fstest.MapFS{
+		"website/docs/index.html.markdown": {Data: []byte("---\nlayout: \"test\"\npage_title: \"Provider: Test\"\n---\n\n# Test\n")},
+		"website/docs/r/thing.html.markdown": {Data: []byte(`---
+subcategory: "Things"
+page_title: "test_thing"
+description: |-
+  Manages a thing.
+  Or two.
+---
+# test_thing
+`)},
+		"website/docs/d/thing.html.md":      {Data: []byte("# data.test_thing\n")},
+		"website/docs/guides/upgrade.md":    {Data: []byte("---\npage_title: 'Upgrade guide'\n---\nUpgrade.\n")},
+		"website/docs/functions/parse.md":   {Data: []byte("# parse\n")},
+		"website/docs/r/thing.png":          {Data: []byte("not a doc")},
+		"website/docs/unknown/something.md": {Data: []byte("not a doc")},
+	}
+
+	docs, err := providerdocs.Extract(fsys)
+	if err != nil {
+		t.Fatalf("❌ Extraction failed (%v)", err)
+	}
+	byPath := map[string]provider.Doc{}
+	for _, doc := range docs {
+		byPath[doc.Path] = doc
+	}
+	if len(byPath) != 5 {
+		t.Fatalf("❌ Incorrect number of docs: %v", docs)
+	}
+
+	overview := byPath["website/docs/index.html.markdown"]
+	if overview.Type != provider.DocTypeOverview || overview.Slug != "index" || overview.Title != "Provider: Test" || overview.Content != "\n# Test\n" {
+		t.Fatalf("❌ Incorrect overview: %v", overview)
+	}
+	resource := byPath["website/docs/r/thing.html.markdown"]
+	if resource.Type != provider.DocTypeResource || resource.Slug != "thing" || resource.Subcategory != "Things" {
+		t.Fatalf("❌ Incorrect resource: %v", resource)
+	}
+	if resource.Description != "Manages a thing.\nOr two." {
+		t.Fatalf("❌ Incorrect block scalar description: %q", resource.Description)
+	}
+	if dataSource := byPath["website/docs/d/thing.html.md"]; dataSource.Type != provider.DocTypeDataSource || dataSource.Content != "# data.test_thing\n" {
+		t.Fatalf("❌ Incorrect data source: %v", dataSource)
+	}
+	if guide := byPath["website/docs/guides/upgrade.md"]; guide.Type != provider.DocTypeGuide || guide.Title != "Upgrade guide" {
+		t.Fatalf("❌ Incorrect guide: %v", guide)
+	}
+	if function := byPath["website/docs/functions/parse.md"]; function.Type != provider.DocTypeFunction || function.Slug != "parse" {
+		t.Fatalf("❌ Incorrect function: %v", function)
+	}
+	t.Logf("✅ All docs were collected from the legacy layout.")
+}
+
+func TestExtractPrefersDocsDirectory(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/resources/new.md":            {Data: []byte("# new\n")},
+		"website/docs/r/old.html.markdown": {Data: []byte("# old\n")},
+	}
+	docs, err := providerdocs.Extract(fsys)
+	if err != nil {
+		t.Fatalf("❌ Extraction failed (%v)", err)
+	}
+	if len(docs) != 1 || docs[0].Slug != "new" {
+		t.Fatalf("❌ Incorrect docs: %v", docs)
+	}
+	t.Logf("✅ The docs directory takes precedence over website/docs.")
+}
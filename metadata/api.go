@@ -4,6 +4,8 @@
 package metadata
 
 import (
+	"context"
+
 	"github.com/opentofu/libregistry/metadata/storage"
 )
 
@@ -11,6 +13,10 @@ import (
 type API interface {
 	ModuleDataAPI
 	ProviderDataAPI
+
+	// Begin starts a new transaction. All changes made through the returned Transaction are buffered in memory and
+	// are visible to reads on the Transaction only. They are written to the backing storage when calling Commit.
+	Begin(ctx context.Context) (Transaction, error)
 }
 
 // New creates a new API.
@@ -24,11 +24,17 @@ type ModuleDataAPI interface {
 	// GetAllModules returns a map of all module addresses and the metadata.
 	GetAllModules(ctx context.Context) (map[module.Addr]module.Metadata, error)
 
-	// PutModule queues the addition of a module with the given metadata. Call Commit() to write the changes
-	// to the backing storage.
+	// PutModule queues the addition of a module with the given metadata. When called on a Transaction, the change is
+	// only written to the backing storage on Commit().
 	PutModule(ctx context.Context, moduleAddr module.Addr, metadata module.Metadata) error
 	// DeleteModule queues up the deletion of a given module.
 	DeleteModule(ctx context.Context, moduleAddr module.Addr) error
+
+	// GetModuleVersionDetails returns the documentation extracted from a single module version.
+	GetModuleVersionDetails(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) (module.VersionDetails, error)
+	// PutModuleVersionDetails queues the addition of the documentation of a single module version. When called on a
+	// Transaction, the change is only written to the backing storage on Commit().
+	PutModuleVersionDetails(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber, details module.VersionDetails) error
 }
 
 const modulesDirectory = "modules"
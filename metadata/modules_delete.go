@@ -5,10 +5,29 @@ package metadata
 
 import (
 	"context"
+	"fmt"
+	"path"
 
+	"github.com/opentofu/libregistry/metadata/storage"
 	"github.com/opentofu/libregistry/types/module"
 )
 
 func (r registryDataAPI) DeleteModule(ctx context.Context, moduleAddr module.Addr) error {
+	return r.inTransaction(ctx, func(tx registryDataAPI) error {
+		return tx.deleteModule(ctx, moduleAddr)
+	})
+}
+
+func (r registryDataAPI) deleteModule(ctx context.Context, moduleAddr module.Addr) error {
+	detailsDirectory := r.getModuleVersionDetailsDirectory(moduleAddr)
+	detailsFiles, err := r.storageAPI.ListFiles(ctx, detailsDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to list module version details in %s (%w)", detailsDirectory, err)
+	}
+	for _, file := range detailsFiles {
+		if err := r.storageAPI.DeleteFile(ctx, storage.Path(path.Join(string(detailsDirectory), file))); err != nil {
+			return fmt.Errorf("failed to delete module version details file %s (%w)", file, err)
+		}
+	}
 	return r.storageAPI.DeleteFile(ctx, r.getModulePath(moduleAddr))
 }
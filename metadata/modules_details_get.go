@@ -0,0 +1,36 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/types/module"
+)
+
+func (r registryDataAPI) GetModuleVersionDetails(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) (module.VersionDetails, error) {
+	if err := version.Validate(); err != nil {
+		return module.VersionDetails{}, err
+	}
+	path := r.getModuleVersionDetailsPath(moduleAddr, version)
+	fileContents, err := r.storageAPI.GetFile(ctx, path)
+	if err != nil {
+		if storage.IsFileNotFound(err) {
+			return module.VersionDetails{}, &ModuleVersionDetailsNotFoundError{
+				ModuleAddr: moduleAddr,
+				Version:    version,
+				Cause:      err,
+			}
+		}
+		return module.VersionDetails{}, fmt.Errorf("failed to read module version details file %s (%w)", path, err)
+	}
+	var details module.VersionDetails
+	if err := json.Unmarshal(fileContents, &details); err != nil {
+		return module.VersionDetails{}, fmt.Errorf("failed to parse module version details file %s (%w)", path, err)
+	}
+	return details, nil
+}
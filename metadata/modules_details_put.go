@@ -0,0 +1,27 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opentofu/libregistry/types/module"
+)
+
+func (r registryDataAPI) PutModuleVersionDetails(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber, details module.VersionDetails) error {
+	if err := version.Validate(); err != nil {
+		return err
+	}
+	marshalled, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to marshal module version details (%w)", err)
+	}
+	path := r.getModuleVersionDetailsPath(moduleAddr, version)
+	if err := r.storageAPI.PutFile(ctx, path, marshalled); err != nil {
+		return fmt.Errorf("failed to write module version details file %s (%w)", path, err)
+	}
+	return nil
+}
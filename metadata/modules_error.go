@@ -19,3 +19,17 @@ func (m ModuleNotFoundError) Error() string {
 func (m ModuleNotFoundError) Unwrap() error {
 	return m.Cause
 }
+
+type ModuleVersionDetailsNotFoundError struct {
+	ModuleAddr module.Addr
+	Version    module.VersionNumber
+	Cause      error
+}
+
+func (m ModuleVersionDetailsNotFoundError) Error() string {
+	return "Module version details not found: " + m.ModuleAddr.String() + " " + string(m.Version)
+}
+
+func (m ModuleVersionDetailsNotFoundError) Unwrap() error {
+	return m.Cause
+}
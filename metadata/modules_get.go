@@ -6,7 +6,6 @@ package metadata
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 
 	"github.com/opentofu/libregistry/metadata/storage"
@@ -17,8 +16,7 @@ func (r registryDataAPI) GetModule(ctx context.Context, moduleAddr module.Addr)
 	path := r.getModulePath(moduleAddr)
 	fileContents, err := r.storageAPI.GetFile(ctx, path)
 	if err != nil {
-		var notFoundErr *storage.ErrFileNotFound
-		if errors.As(err, &notFoundErr) {
+		if storage.IsFileNotFound(err) {
 			return module.Metadata{}, &ModuleNotFoundError{
 				ModuleAddr: moduleAddr,
 				Cause:      err,
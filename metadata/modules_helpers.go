@@ -14,3 +14,15 @@ func (r registryDataAPI) getModulePath(moduleAddr module.Addr) storage.Path {
 	moduleAddr = moduleAddr.Normalize()
 	return storage.Path(path.Join(modulesDirectory, moduleAddr.Namespace[0:1], moduleAddr.Namespace, moduleAddr.Name, moduleAddr.TargetSystem) + ".json")
 }
+
+// getModuleVersionDetailsPath returns the path of the version details next to the module file, for example
+// modules/o/opentofu/vpc/aws/v1.0.0.json for modules/o/opentofu/vpc/aws.json.
+func (r registryDataAPI) getModuleVersionDetailsPath(moduleAddr module.Addr, version module.VersionNumber) storage.Path {
+	return storage.Path(path.Join(string(r.getModuleVersionDetailsDirectory(moduleAddr)), string(version.Normalize())) + ".json")
+}
+
+// getModuleVersionDetailsDirectory returns the directory holding the version details of a module.
+func (r registryDataAPI) getModuleVersionDetailsDirectory(moduleAddr module.Addr) storage.Path {
+	moduleAddr = moduleAddr.Normalize()
+	return storage.Path(path.Join(modulesDirectory, moduleAddr.Namespace[0:1], moduleAddr.Namespace, moduleAddr.Name, moduleAddr.TargetSystem))
+}
@@ -5,7 +5,6 @@ package metadata
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"path"
 	"strings"
@@ -18,8 +17,7 @@ func (r registryDataAPI) ListModules(ctx context.Context) ([]module.Addr, error)
 	moduleLetters, err := r.storageAPI.ListDirectories(ctx, "modules")
 	if err != nil {
 		// The modules directory does not exist:
-		var notFoundError storage.ErrFileNotFound
-		if errors.As(err, &notFoundError) {
+		if storage.IsFileNotFound(err) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to list 'modules' directory (%w)", err)
@@ -40,8 +38,7 @@ func (r registryDataAPI) listModulesByNamespaceLetter(ctx context.Context, lette
 	namespaces, e := r.storageAPI.ListDirectories(ctx, p)
 	if e != nil {
 		// The letter directory does not exist:
-		var notFoundError storage.ErrFileNotFound
-		if errors.As(e, &notFoundError) {
+		if storage.IsFileNotFound(e) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to list module directory %s (%w)", p, e)
@@ -63,8 +60,7 @@ func (r registryDataAPI) ListModulesByNamespace(ctx context.Context, namespace s
 	directories, err := r.storageAPI.ListDirectories(ctx, p)
 	if err != nil {
 		// The namespace directory does not exist:
-		var notFoundError storage.ErrFileNotFound
-		if errors.As(err, &notFoundError) {
+		if storage.IsFileNotFound(err) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to list namespace directory %s (%w)", p, err)
@@ -189,3 +189,44 @@ func TestModuleCRUD(t *testing.T) {
 	})
 	t.Run("6-list-get", checkEmpty)
 }
+
+// TestModuleVersionDetailsDelete tests that deleting a module also removes the details of its versions.
+func TestModuleVersionDetailsDelete(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "opentofu",
+		Name:         "test",
+		TargetSystem: "aws",
+	}
+
+	forEachStorageBackend(t, func(t *testing.T, api metadata.API) {
+		ctx := context.Background()
+
+		if err := api.PutModule(ctx, moduleAddr, module.Metadata{
+			Versions: []module.Version{
+				{
+					Version: "1.0.0",
+				},
+			},
+		}); err != nil {
+			t.Fatalf("Failed to put module (%v)", err)
+		}
+		if err := api.PutModuleVersionDetails(ctx, moduleAddr, "1.0.0", module.VersionDetails{}); err != nil {
+			t.Fatalf("Failed to put module version details (%v)", err)
+		}
+		if err := api.PutModuleVersionDetails(ctx, moduleAddr, "not a version", module.VersionDetails{}); err == nil {
+			t.Fatalf("Putting the details of an invalid version did not fail.")
+		}
+		if _, err := api.GetModuleVersionDetails(ctx, moduleAddr, "v1.0.0"); err != nil {
+			t.Fatalf("Failed to get module version details (%v)", err)
+		}
+
+		if err := api.DeleteModule(ctx, moduleAddr); err != nil {
+			t.Fatalf("Failed to delete module (%v)", err)
+		}
+		_, err := api.GetModuleVersionDetails(ctx, moduleAddr, "1.0.0")
+		var notFound *metadata.ModuleVersionDetailsNotFoundError
+		if !errors.As(err, &notFound) {
+			t.Fatalf("The module version details remained after deleting the module (%v)", err)
+		}
+	})
+}
@@ -19,6 +19,19 @@ type ProviderDataAPI interface {
 	// legacy provider addresses.
 	ListProviderAliases(ctx context.Context) (map[provider.Addr]provider.Addr, error)
 
+	// PutProviderNamespaceAlias queues up adding an alias from one namespace to another. It returns a
+	// *ProviderNamespaceNotFoundError if the target namespace has no providers or provider aliases and a
+	// *ProviderNamespaceAliasCycleError if the alias would create a cycle.
+	PutProviderNamespaceAlias(ctx context.Context, from string, to string) error
+	// DeleteProviderNamespaceAlias queues up deleting the alias of the specified namespace.
+	DeleteProviderNamespaceAlias(ctx context.Context, from string) error
+	// PutProviderAlias queues up adding an alias from one provider address to another. It returns a
+	// *ProviderNotFoundError if the target provider does not exist and a *ProviderAliasCycleError if the alias would
+	// create a cycle.
+	PutProviderAlias(ctx context.Context, from provider.Addr, to provider.Addr) error
+	// DeleteProviderAlias queues up deleting the alias of the specified provider address.
+	DeleteProviderAlias(ctx context.Context, from provider.Addr) error
+
 	// ListProviders returns all providers in the registry. The includeAliases parameter lets you include aliased copies
 	// of providers.
 	ListProviders(ctx context.Context, includeAliases bool) ([]provider.Addr, error)
@@ -46,6 +59,16 @@ type ProviderDataAPI interface {
 	// DeleteProvider queues up deleting the specified provider.
 	DeleteProvider(ctx context.Context, addr provider.Addr) error
 
+	// GetProviderDocsIndex returns the list of documentation pages of a provider version. It returns a
+	// *ProviderDocsNotFoundError if no documentation is stored for the version.
+	GetProviderDocsIndex(ctx context.Context, addr provider.Addr, version provider.VersionNumber) (provider.DocsIndex, error)
+	// GetProviderDoc returns a single documentation page of a provider version by its type and slug. It returns a
+	// *ProviderDocNotFoundError if the page does not exist.
+	GetProviderDoc(ctx context.Context, addr provider.Addr, version provider.VersionNumber, docType provider.DocType, slug string) (provider.Doc, error)
+	// PutProviderDocs queues up writing the documentation pages of a provider version and the index listing them.
+	// Pages stored for the version before are replaced, so pages missing from docs are removed.
+	PutProviderDocs(ctx context.Context, addr provider.Addr, version provider.VersionNumber, docs []provider.Doc) error
+
 	// ListProviderNamespacesWithKeys returns a list of provider namespaces that have a key registered.
 	ListProviderNamespacesWithKeys(ctx context.Context) ([]string, error)
 	// ListProviderNamespaceKeyIDs lists the keys IDs of all keys registered in a provider namespace.
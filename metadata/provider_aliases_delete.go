@@ -0,0 +1,48 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+func (r registryDataAPI) DeleteProviderAlias(ctx context.Context, from provider.Addr) error {
+	return r.inTransaction(ctx, func(tx registryDataAPI) error {
+		return tx.deleteProviderAlias(ctx, from)
+	})
+}
+
+func (r registryDataAPI) deleteProviderAlias(ctx context.Context, from provider.Addr) error {
+	aliases, err := r.getProviderAliases(ctx)
+	if err != nil {
+		return err
+	}
+	key := from.String()
+	if _, ok := aliases.Providers[key]; !ok {
+		return nil
+	}
+	delete(aliases.Providers, key)
+	return r.putProviderAliases(ctx, aliases)
+}
+
+func (r registryDataAPI) DeleteProviderNamespaceAlias(ctx context.Context, from string) error {
+	return r.inTransaction(ctx, func(tx registryDataAPI) error {
+		return tx.deleteProviderNamespaceAlias(ctx, from)
+	})
+}
+
+func (r registryDataAPI) deleteProviderNamespaceAlias(ctx context.Context, from string) error {
+	aliases, err := r.getProviderAliases(ctx)
+	if err != nil {
+		return err
+	}
+	from = provider.NormalizeNamespace(from)
+	if _, ok := aliases.Namespaces[from]; !ok {
+		return nil
+	}
+	delete(aliases.Namespaces, from)
+	return r.putProviderAliases(ctx, aliases)
+}
@@ -0,0 +1,106 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+func (r registryDataAPI) PutProviderAlias(ctx context.Context, from provider.Addr, to provider.Addr) error {
+	return r.inTransaction(ctx, func(tx registryDataAPI) error {
+		return tx.putProviderAlias(ctx, from, to)
+	})
+}
+
+func (r registryDataAPI) putProviderAlias(ctx context.Context, from provider.Addr, to provider.Addr) error {
+	from = from.Normalize()
+	to = to.Normalize()
+
+	exists, err := r.storageAPI.FileExists(ctx, r.getProviderPathRaw(to))
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &ProviderNotFoundError{
+			ProviderAddr: to,
+		}
+	}
+
+	aliases, err := r.getProviderAliases(ctx)
+	if err != nil {
+		return err
+	}
+	providerAliases, err := r.ListProviderAliases(ctx)
+	if err != nil {
+		return err
+	}
+	// Follow the chain of aliases starting at the target. If it leads back to the alias, the new alias would
+	// create a cycle.
+	current := to
+	for i := 0; i <= len(providerAliases); i++ {
+		if current.Equals(from) {
+			return &ProviderAliasCycleError{
+				From: from,
+				To:   to,
+			}
+		}
+		next, ok := providerAliases[current]
+		if !ok {
+			break
+		}
+		current = next
+	}
+
+	aliases.Providers[from.String()] = to.String()
+	return r.putProviderAliases(ctx, aliases)
+}
+
+func (r registryDataAPI) PutProviderNamespaceAlias(ctx context.Context, from string, to string) error {
+	return r.inTransaction(ctx, func(tx registryDataAPI) error {
+		return tx.putProviderNamespaceAlias(ctx, from, to)
+	})
+}
+
+func (r registryDataAPI) putProviderNamespaceAlias(ctx context.Context, from string, to string) error {
+	from = provider.NormalizeNamespace(from)
+	to = provider.NormalizeNamespace(to)
+
+	providers, err := r.ListProvidersByNamespace(ctx, to, true)
+	if err != nil {
+		return err
+	}
+	if len(providers) == 0 {
+		return &ProviderNamespaceNotFoundError{
+			Namespace: to,
+		}
+	}
+
+	aliases, err := r.getProviderAliases(ctx)
+	if err != nil {
+		return err
+	}
+	namespaceAliases, err := r.ListProviderNamespaceAliases(ctx)
+	if err != nil {
+		return err
+	}
+	current := to
+	for i := 0; i <= len(namespaceAliases); i++ {
+		if current == from {
+			return &ProviderNamespaceAliasCycleError{
+				From: from,
+				To:   to,
+			}
+		}
+		next, ok := namespaceAliases[current]
+		if !ok {
+			break
+		}
+		current = next
+	}
+
+	aliases.Namespaces[from] = to
+	return r.putProviderAliases(ctx, aliases)
+}
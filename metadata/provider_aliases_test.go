@@ -0,0 +1,89 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+// TestProviderAliasValidation tests that aliases pointing to non-existent providers and alias cycles are rejected. It
+// starts without an aliases file, so the built-in aliases must be used as a fallback.
+func TestProviderAliasValidation(t *testing.T) {
+	providerA := provider.Addr{
+		Namespace: "a",
+		Name:      "test",
+	}
+	providerB := provider.Addr{
+		Namespace: "b",
+		Name:      "test",
+	}
+
+	forEachStorageBackend(t, func(t *testing.T, api metadata.API) {
+		ctx := context.Background()
+
+		err := api.PutProviderAlias(ctx, providerA, providerB)
+		var notFound *metadata.ProviderNotFoundError
+		if !errors.As(err, &notFound) {
+			t.Fatalf("Aliasing a non-existent provider did not return the correct error (%v)", err)
+		}
+		err = api.PutProviderNamespaceAlias(ctx, providerA.Namespace, providerB.Namespace)
+		var namespaceNotFound *metadata.ProviderNamespaceNotFoundError
+		if !errors.As(err, &namespaceNotFound) {
+			t.Fatalf("Aliasing a non-existent namespace did not return the correct error (%v)", err)
+		}
+
+		for _, addr := range []provider.Addr{providerA, providerB} {
+			if err := api.PutProvider(ctx, addr, provider.Metadata{}); err != nil {
+				t.Fatalf("Failed to put provider (%v)", err)
+			}
+		}
+
+		if err := api.PutProviderAlias(ctx, providerA, providerB); err != nil {
+			t.Fatalf("Failed to put provider alias (%v)", err)
+		}
+		err = api.PutProviderAlias(ctx, providerB, providerA)
+		var cycle *metadata.ProviderAliasCycleError
+		if !errors.As(err, &cycle) {
+			t.Fatalf("Creating a provider alias cycle did not return the correct error (%v)", err)
+		}
+
+		if err := api.PutProviderNamespaceAlias(ctx, providerA.Namespace, providerB.Namespace); err != nil {
+			t.Fatalf("Failed to put provider namespace alias (%v)", err)
+		}
+		err = api.PutProviderNamespaceAlias(ctx, providerB.Namespace, providerA.Namespace)
+		var namespaceCycle *metadata.ProviderNamespaceAliasCycleError
+		if !errors.As(err, &namespaceCycle) {
+			t.Fatalf("Creating a provider namespace alias cycle did not return the correct error (%v)", err)
+		}
+
+		if err := api.DeleteProviderAlias(ctx, providerA); err != nil {
+			t.Fatalf("Failed to delete provider alias (%v)", err)
+		}
+		if err := api.DeleteProviderNamespaceAlias(ctx, providerA.Namespace); err != nil {
+			t.Fatalf("Failed to delete provider namespace alias (%v)", err)
+		}
+		providerAliases, err := api.ListProviderAliases(ctx)
+		if err != nil {
+			t.Fatalf("Failed to list provider aliases (%v)", err)
+		}
+		namespaceAliases, err := api.ListProviderNamespaceAliases(ctx)
+		if err != nil {
+			t.Fatalf("Failed to list provider namespace aliases (%v)", err)
+		}
+		if _, ok := providerAliases[providerA]; ok {
+			t.Fatalf("Provider alias remained after deletion.")
+		}
+		if _, ok := namespaceAliases[providerA.Namespace]; ok {
+			t.Fatalf("Provider namespace alias remained after deletion.")
+		}
+		if namespaceAliases["hashicorp"] != "opentofu" {
+			t.Fatalf("The default namespace aliases were lost after the first change (%v)", namespaceAliases)
+		}
+	})
+}
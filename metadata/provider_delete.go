@@ -10,5 +10,14 @@ import (
 )
 
 func (r registryDataAPI) DeleteProvider(ctx context.Context, providerAddr provider.Addr) error {
+	return r.inTransaction(ctx, func(tx registryDataAPI) error {
+		return tx.deleteProvider(ctx, providerAddr)
+	})
+}
+
+func (r registryDataAPI) deleteProvider(ctx context.Context, providerAddr provider.Addr) error {
+	if err := r.deleteProviderDocs(ctx, providerAddr, nil); err != nil {
+		return err
+	}
 	return r.storageAPI.DeleteFile(ctx, r.getProviderPathRaw(providerAddr))
 }
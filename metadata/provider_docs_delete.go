@@ -0,0 +1,61 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+// deleteProviderDocs removes the documentation of all provider versions that are not in the keep list.
+func (r registryDataAPI) deleteProviderDocs(ctx context.Context, addr provider.Addr, keep provider.VersionList) error {
+	docsDirectory := storage.Path(r.getProviderDocsDirectory(addr))
+	versions, err := r.storageAPI.ListDirectories(ctx, docsDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to list provider docs in %s (%w)", docsDirectory, err)
+	}
+	kept := make(map[provider.VersionNumber]struct{}, len(keep))
+	for _, ver := range keep {
+		kept[ver.Version.Normalize()] = struct{}{}
+	}
+	for _, version := range versions {
+		if _, ok := kept[provider.VersionNumber(version).Normalize()]; ok {
+			continue
+		}
+		if err := r.deleteProviderVersionDocs(ctx, storage.Path(path.Join(string(docsDirectory), version))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteProviderVersionDocs removes the index and the pages stored for a single provider version.
+func (r registryDataAPI) deleteProviderVersionDocs(ctx context.Context, versionDirectory storage.Path) error {
+	docTypes, err := r.storageAPI.ListDirectories(ctx, versionDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to list provider docs in %s (%w)", versionDirectory, err)
+	}
+	for _, docType := range docTypes {
+		typeDirectory := storage.Path(path.Join(string(versionDirectory), docType))
+		files, err := r.storageAPI.ListFiles(ctx, typeDirectory)
+		if err != nil {
+			return fmt.Errorf("failed to list provider docs in %s (%w)", typeDirectory, err)
+		}
+		for _, file := range files {
+			p := storage.Path(path.Join(string(typeDirectory), file))
+			if err := r.storageAPI.DeleteFile(ctx, p); err != nil {
+				return fmt.Errorf("failed to delete provider doc file %s (%w)", p, err)
+			}
+		}
+	}
+	p := storage.Path(path.Join(string(versionDirectory), providerDocsIndexFile))
+	if err := r.storageAPI.DeleteFile(ctx, p); err != nil {
+		return fmt.Errorf("failed to delete provider docs index file %s (%w)", p, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+func (r registryDataAPI) GetProviderDocsIndex(ctx context.Context, addr provider.Addr, version provider.VersionNumber) (provider.DocsIndex, error) {
+	if err := version.Validate(); err != nil {
+		return provider.DocsIndex{}, err
+	}
+	p := storage.Path(path.Join(r.getProviderDocsPath(addr, version), providerDocsIndexFile))
+	fileContents, err := r.storageAPI.GetFile(ctx, p)
+	if err != nil {
+		if storage.IsFileNotFound(err) {
+			return provider.DocsIndex{}, &ProviderDocsNotFoundError{
+				ProviderAddr: addr,
+				Version:      version,
+				Cause:        err,
+			}
+		}
+		return provider.DocsIndex{}, fmt.Errorf("failed to read provider docs index file %s (%w)", p, err)
+	}
+	var index provider.DocsIndex
+	if err := json.Unmarshal(fileContents, &index); err != nil {
+		return provider.DocsIndex{}, fmt.Errorf("failed to parse provider docs index file %s (%w)", p, err)
+	}
+	return index, nil
+}
+
+func (r registryDataAPI) GetProviderDoc(ctx context.Context, addr provider.Addr, version provider.VersionNumber, docType provider.DocType, slug string) (provider.Doc, error) {
+	if err := version.Validate(); err != nil {
+		return provider.Doc{}, err
+	}
+	if err := validateDocSlug(docType, slug); err != nil {
+		return provider.Doc{}, err
+	}
+	p := storage.Path(path.Join(r.getProviderDocsPath(addr, version), string(docType), slug) + ".json")
+	fileContents, err := r.storageAPI.GetFile(ctx, p)
+	if err != nil {
+		if storage.IsFileNotFound(err) {
+			return provider.Doc{}, &ProviderDocNotFoundError{
+				ProviderAddr: addr,
+				Version:      version,
+				Type:         docType,
+				Slug:         slug,
+				Cause:        err,
+			}
+		}
+		return provider.Doc{}, fmt.Errorf("failed to read provider doc file %s (%w)", p, err)
+	}
+	var doc provider.Doc
+	if err := json.Unmarshal(fileContents, &doc); err != nil {
+		return provider.Doc{}, fmt.Errorf("failed to parse provider doc file %s (%w)", p, err)
+	}
+	return doc, nil
+}
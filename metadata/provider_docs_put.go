@@ -0,0 +1,75 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+func (r registryDataAPI) PutProviderDocs(ctx context.Context, addr provider.Addr, version provider.VersionNumber, docs []provider.Doc) error {
+	if err := version.Validate(); err != nil {
+		return err
+	}
+	index := provider.DocsIndex{
+		Items: make([]provider.DocItem, 0, len(docs)),
+	}
+	for _, doc := range docs {
+		if err := validateDocSlug(doc.Type, doc.Slug); err != nil {
+			return err
+		}
+		index.Items = append(index.Items, doc.DocItem)
+	}
+
+	return r.inTransaction(ctx, func(tx registryDataAPI) error {
+		return tx.putProviderDocs(ctx, addr, version, docs, index)
+	})
+}
+
+func (r registryDataAPI) putProviderDocs(ctx context.Context, addr provider.Addr, version provider.VersionNumber, docs []provider.Doc, index provider.DocsIndex) error {
+	basePath := r.getProviderDocsPath(addr, version)
+	// Remove the pages of an earlier put so pages that no longer exist cannot be read anymore.
+	if err := r.deleteProviderVersionDocs(ctx, storage.Path(basePath)); err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		marshalled, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("failed to marshal provider doc %s/%s (%w)", doc.Type, doc.Slug, err)
+		}
+		p := storage.Path(path.Join(basePath, string(doc.Type), doc.Slug) + ".json")
+		if err := r.storageAPI.PutFile(ctx, p, marshalled); err != nil {
+			return fmt.Errorf("failed to write provider doc file %s (%w)", p, err)
+		}
+	}
+	// The index is written last so it only lists pages that were stored.
+	marshalled, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal provider docs index (%w)", err)
+	}
+	p := storage.Path(path.Join(basePath, providerDocsIndexFile))
+	if err := r.storageAPI.PutFile(ctx, p, marshalled); err != nil {
+		return fmt.Errorf("failed to write provider docs index file %s (%w)", p, err)
+	}
+	return nil
+}
+
+const providerDocsIndexFile = "index.json"
+
+// validateDocSlug makes sure the doc type and slug can be used as a path element.
+func validateDocSlug(docType provider.DocType, slug string) error {
+	if err := docType.Validate(); err != nil {
+		return err
+	}
+	if slug == "" || slug == "." || slug == ".." || strings.ContainsAny(slug, "/\\") {
+		return fmt.Errorf("invalid doc slug: %s", slug)
+	}
+	return nil
+}
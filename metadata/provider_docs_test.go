@@ -0,0 +1,112 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+// TestProviderDocsCleanup tests that the docs of removed versions and deleted providers are removed.
+func TestProviderDocsCleanup(t *testing.T) {
+	providerAddr := provider.Addr{
+		Namespace: "opentofu",
+		Name:      "test",
+	}
+	docs := []provider.Doc{
+		{
+			DocItem: provider.DocItem{
+				Type: provider.DocTypeResource,
+				Slug: "thing",
+			},
+			Content: "# test_thing",
+		},
+	}
+
+	forEachStorageBackend(t, func(t *testing.T, api metadata.API) {
+		ctx := context.Background()
+
+		for _, version := range []provider.VersionNumber{"v1.0.0", "v2.0.0"} {
+			if err := api.PutProviderDocs(ctx, providerAddr, version, docs); err != nil {
+				t.Fatalf("Failed to put provider docs (%v)", err)
+			}
+		}
+		if err := api.PutProvider(ctx, providerAddr, provider.Metadata{
+			Versions: []provider.Version{
+				{
+					Version: "v2.0.0",
+				},
+			},
+		}); err != nil {
+			t.Fatalf("Failed to put provider (%v)", err)
+		}
+
+		checkDeleted := func(version provider.VersionNumber) {
+			t.Helper()
+			var indexNotFound *metadata.ProviderDocsNotFoundError
+			if _, err := api.GetProviderDocsIndex(ctx, providerAddr, version); !errors.As(err, &indexNotFound) {
+				t.Fatalf("The docs index of version %s was not deleted (%v)", version, err)
+			}
+			var docNotFound *metadata.ProviderDocNotFoundError
+			if _, err := api.GetProviderDoc(ctx, providerAddr, version, provider.DocTypeResource, "thing"); !errors.As(err, &docNotFound) {
+				t.Fatalf("The doc of version %s was not deleted (%v)", version, err)
+			}
+		}
+		checkDeleted("v1.0.0")
+		if _, err := api.GetProviderDoc(ctx, providerAddr, "v2.0.0", provider.DocTypeResource, "thing"); err != nil {
+			t.Fatalf("The docs of a published version were deleted (%v)", err)
+		}
+
+		if err := api.DeleteProvider(ctx, providerAddr); err != nil {
+			t.Fatalf("Failed to delete provider (%v)", err)
+		}
+		checkDeleted("v2.0.0")
+	})
+}
+
+// TestProviderDocsReplace tests that putting the docs of a version again removes the pages that are no longer present.
+func TestProviderDocsReplace(t *testing.T) {
+	providerAddr := provider.Addr{
+		Namespace: "opentofu",
+		Name:      "test",
+	}
+	doc := func(slug string) provider.Doc {
+		return provider.Doc{
+			DocItem: provider.DocItem{
+				Type: provider.DocTypeResource,
+				Slug: slug,
+			},
+			Content: "# test_" + slug,
+		}
+	}
+
+	forEachStorageBackend(t, func(t *testing.T, api metadata.API) {
+		ctx := context.Background()
+		if err := api.PutProviderDocs(ctx, providerAddr, "v1.0.0", []provider.Doc{doc("old"), doc("kept")}); err != nil {
+			t.Fatalf("Failed to put provider docs (%v)", err)
+		}
+		if err := api.PutProviderDocs(ctx, providerAddr, "v1.0.0", []provider.Doc{doc("kept")}); err != nil {
+			t.Fatalf("Failed to put provider docs again (%v)", err)
+		}
+
+		index, err := api.GetProviderDocsIndex(ctx, providerAddr, "v1.0.0")
+		if err != nil {
+			t.Fatalf("Failed to get provider docs index (%v)", err)
+		}
+		if len(index.Items) != 1 || index.Items[0].Slug != "kept" {
+			t.Fatalf("Incorrect docs index after replacing the docs: %v", index.Items)
+		}
+		if _, err := api.GetProviderDoc(ctx, providerAddr, "v1.0.0", provider.DocTypeResource, "kept"); err != nil {
+			t.Fatalf("Failed to get the kept doc (%v)", err)
+		}
+		var docNotFound *metadata.ProviderDocNotFoundError
+		if _, err := api.GetProviderDoc(ctx, providerAddr, "v1.0.0", provider.DocTypeResource, "old"); !errors.As(err, &docNotFound) {
+			t.Fatalf("The stale doc is still readable after replacing the docs (%v)", err)
+		}
+	})
+}
@@ -22,3 +22,65 @@ func (m ProviderNotFoundError) Error() string {
 func (m ProviderNotFoundError) Unwrap() error {
 	return m.Cause
 }
+
+// ProviderNamespaceNotFoundError indicates that a provider namespace has no providers.
+type ProviderNamespaceNotFoundError struct {
+	Namespace string
+}
+
+func (m ProviderNamespaceNotFoundError) Error() string {
+	return "Provider namespace not found: " + m.Namespace
+}
+
+// ProviderAliasCycleError indicates that adding a provider alias would result in a cycle of aliases.
+type ProviderAliasCycleError struct {
+	From provider.Addr
+	To   provider.Addr
+}
+
+func (m ProviderAliasCycleError) Error() string {
+	return "Aliasing provider " + m.From.String() + " to " + m.To.String() + " would create an alias cycle"
+}
+
+// ProviderNamespaceAliasCycleError indicates that adding a provider namespace alias would result in a cycle of
+// aliases.
+type ProviderNamespaceAliasCycleError struct {
+	From string
+	To   string
+}
+
+func (m ProviderNamespaceAliasCycleError) Error() string {
+	return "Aliasing provider namespace " + m.From + " to " + m.To + " would create an alias cycle"
+}
+
+// ProviderDocsNotFoundError indicates that no documentation is stored for a provider version.
+type ProviderDocsNotFoundError struct {
+	ProviderAddr provider.Addr
+	Version      provider.VersionNumber
+	Cause        error
+}
+
+func (m ProviderDocsNotFoundError) Error() string {
+	return "Provider docs not found: " + m.ProviderAddr.String() + " " + string(m.Version)
+}
+
+func (m ProviderDocsNotFoundError) Unwrap() error {
+	return m.Cause
+}
+
+// ProviderDocNotFoundError indicates that a single page of the provider documentation does not exist.
+type ProviderDocNotFoundError struct {
+	ProviderAddr provider.Addr
+	Version      provider.VersionNumber
+	Type         provider.DocType
+	Slug         string
+	Cause        error
+}
+
+func (m ProviderDocNotFoundError) Error() string {
+	return "Provider doc not found: " + m.ProviderAddr.String() + " " + string(m.Version) + " " + string(m.Type) + "/" + m.Slug
+}
+
+func (m ProviderDocNotFoundError) Unwrap() error {
+	return m.Cause
+}
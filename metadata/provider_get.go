@@ -6,7 +6,6 @@ package metadata
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 
 	"github.com/opentofu/libregistry/metadata/storage"
@@ -27,8 +26,7 @@ func (r registryDataAPI) GetProvider(ctx context.Context, providerAddr provider.
 
 	fileContents, err := r.storageAPI.GetFile(ctx, path)
 	if err != nil {
-		var notFound *storage.ErrFileNotFound
-		if errors.As(err, &notFound) {
+		if storage.IsFileNotFound(err) {
 			return provider.Metadata{}, &ProviderNotFoundError{
 				ProviderAddr: providerAddr,
 				Cause:        err,
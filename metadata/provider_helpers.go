@@ -20,3 +20,15 @@ func (r registryDataAPI) getProviderPathCanonical(ctx context.Context, providerA
 	_, providerPath, err := r.getProviderCanonical(ctx, providerAddr)
 	return providerPath, err
 }
+
+// getProviderDocsPath returns the directory holding the documentation of a provider version, for example
+// providers/o/opentofu/aws/docs/v1.0.0 for providers/o/opentofu/aws.json.
+func (r registryDataAPI) getProviderDocsPath(providerAddr provider.Addr, version provider.VersionNumber) string {
+	return path.Join(r.getProviderDocsDirectory(providerAddr), string(version.Normalize()))
+}
+
+// getProviderDocsDirectory returns the directory holding the documentation of all versions of a provider.
+func (r registryDataAPI) getProviderDocsDirectory(providerAddr provider.Addr) string {
+	providerAddr = providerAddr.Normalize()
+	return path.Join(providersDirectory, providerAddr.Namespace[0:1], providerAddr.Namespace, providerAddr.Name, "docs")
+}
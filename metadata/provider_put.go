@@ -12,6 +12,12 @@ import (
 )
 
 func (r registryDataAPI) PutProvider(ctx context.Context, providerAddr provider.Addr, metadata provider.Metadata) error {
+	return r.inTransaction(ctx, func(tx registryDataAPI) error {
+		return tx.putProvider(ctx, providerAddr, metadata)
+	})
+}
+
+func (r registryDataAPI) putProvider(ctx context.Context, providerAddr provider.Addr, metadata provider.Metadata) error {
 	marshalled, err := json.Marshal(metadata)
 	if err != nil {
 		return fmt.Errorf("failed to marshal module metadata (%w)", err)
@@ -20,5 +26,6 @@ func (r registryDataAPI) PutProvider(ctx context.Context, providerAddr provider.
 	if err := r.storageAPI.PutFile(ctx, path, marshalled); err != nil {
 		return fmt.Errorf("failed to write module file %s (%w)", path, err)
 	}
-	return nil
+	// Remove the documentation of versions that are no longer published.
+	return r.deleteProviderDocs(ctx, providerAddr, metadata.Versions)
 }
@@ -0,0 +1,309 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ChangeSet is a storage API that buffers all PutFile and DeleteFile calls in memory. Read calls return the
+// buffered state on top of the backing storage. The changes are only written to the backing storage when calling
+// Commit.
+type ChangeSet interface {
+	API
+
+	// Commit writes all buffered changes to the backing storage. If writing any of the changes fails, the changes
+	// already written are reverted on a best-effort basis. After a successful commit the change set is empty and can
+	// be reused.
+	Commit(ctx context.Context) error
+	// Rollback discards all buffered changes.
+	Rollback(ctx context.Context) error
+}
+
+// NewChangeSet creates a new, empty change set on top of the backing storage.
+func NewChangeSet(backingStorage API) ChangeSet {
+	return &changeSet{
+		backingStorage: backingStorage,
+		lock:           &sync.Mutex{},
+		changes:        map[Path]change{},
+	}
+}
+
+type change struct {
+	contents []byte
+	deleted  bool
+}
+
+type changeSet struct {
+	backingStorage API
+	lock           *sync.Mutex
+	changes        map[Path]change
+}
+
+func (c *changeSet) ListFiles(ctx context.Context, directory Path) ([]string, error) {
+	if err := directory.Validate(); err != nil {
+		return nil, err
+	}
+	files, err := c.backingStorage.ListFiles(ctx, directory)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	fileSet := make(map[string]struct{}, len(files))
+	for _, file := range files {
+		fileSet[file] = struct{}{}
+	}
+	for path, ch := range c.changes {
+		if path.Basename() != directory {
+			continue
+		}
+		if ch.deleted {
+			delete(fileSet, path.Filename())
+		} else {
+			fileSet[path.Filename()] = struct{}{}
+		}
+	}
+	return sortedKeys(fileSet), nil
+}
+
+func (c *changeSet) ListDirectories(ctx context.Context, directory Path) ([]string, error) {
+	if err := directory.Validate(); err != nil {
+		return nil, err
+	}
+	directories, err := c.backingStorage.ListDirectories(ctx, directory)
+	if err != nil {
+		return nil, err
+	}
+
+	directorySet := make(map[string]struct{}, len(directories))
+	for _, dir := range directories {
+		directorySet[dir] = struct{}{}
+	}
+	prefix := ""
+	if directory != "" {
+		prefix = string(directory) + "/"
+	}
+
+	c.lock.Lock()
+	deleted := map[Path]struct{}{}
+	written := map[string]struct{}{}
+	for path, ch := range c.changes {
+		if !strings.HasPrefix(string(path), prefix) {
+			continue
+		}
+		parts := strings.Split(strings.TrimPrefix(string(path), prefix), "/")
+		if len(parts) <= 1 {
+			continue
+		}
+		if ch.deleted {
+			deleted[path] = struct{}{}
+		} else {
+			directorySet[parts[0]] = struct{}{}
+			written[parts[0]] = struct{}{}
+		}
+	}
+	c.lock.Unlock()
+
+	// Directories of the backing storage whose files were all deleted in the change set no longer exist.
+	for _, dir := range directories {
+		if _, ok := written[dir]; ok {
+			continue
+		}
+		dirPath := Path(prefix + dir)
+		if !hasDeletedFiles(deleted, dirPath) {
+			continue
+		}
+		exists, err := c.hasRemainingFiles(ctx, dirPath, deleted)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			delete(directorySet, dir)
+		}
+	}
+	return sortedKeys(directorySet), nil
+}
+
+// hasDeletedFiles returns true if any of the deleted paths is in the directory or one of its subdirectories.
+func hasDeletedFiles(deleted map[Path]struct{}, directory Path) bool {
+	for path := range deleted {
+		if strings.HasPrefix(string(path), string(directory)+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRemainingFiles returns true if the directory in the backing storage, or one of its subdirectories, holds a file
+// that is not deleted in the change set.
+func (c *changeSet) hasRemainingFiles(ctx context.Context, directory Path, deleted map[Path]struct{}) (bool, error) {
+	files, err := c.backingStorage.ListFiles(ctx, directory)
+	if err != nil {
+		return false, err
+	}
+	for _, file := range files {
+		if _, ok := deleted[Path(string(directory)+"/"+file)]; !ok {
+			return true, nil
+		}
+	}
+	subdirectories, err := c.backingStorage.ListDirectories(ctx, directory)
+	if err != nil {
+		return false, err
+	}
+	for _, subdirectory := range subdirectories {
+		exists, err := c.hasRemainingFiles(ctx, Path(string(directory)+"/"+subdirectory), deleted)
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+	return false, nil
+}
+
+func (c *changeSet) PutFile(_ context.Context, path Path, contents []byte) error {
+	if err := path.Validate(); err != nil {
+		return err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.changes[path] = change{
+		contents: contents,
+	}
+	return nil
+}
+
+func (c *changeSet) GetFile(ctx context.Context, path Path) ([]byte, error) {
+	if err := path.Validate(); err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	ch, ok := c.changes[path]
+	c.lock.Unlock()
+	if !ok {
+		return c.backingStorage.GetFile(ctx, path)
+	}
+	if ch.deleted {
+		return nil, &ErrFileNotFound{
+			Path: path,
+		}
+	}
+	return ch.contents, nil
+}
+
+func (c *changeSet) FileExists(ctx context.Context, path Path) (bool, error) {
+	if err := path.Validate(); err != nil {
+		return false, err
+	}
+	c.lock.Lock()
+	ch, ok := c.changes[path]
+	c.lock.Unlock()
+	if !ok {
+		return c.backingStorage.FileExists(ctx, path)
+	}
+	return !ch.deleted, nil
+}
+
+func (c *changeSet) DeleteFile(_ context.Context, path Path) error {
+	if err := path.Validate(); err != nil {
+		return err
+	}
+	if path == "" {
+		return &ErrFileNotFound{
+			Path: path,
+		}
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.changes[path] = change{
+		deleted: true,
+	}
+	return nil
+}
+
+func (c *changeSet) Commit(ctx context.Context) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	paths := make([]Path, 0, len(c.changes))
+	for path := range c.changes {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	// undo holds the original state of every path already written so a failed commit can be reverted.
+	var undo []Path
+	originals := map[Path]change{}
+	for _, path := range paths {
+		original, err := c.backingStorage.GetFile(ctx, path)
+		if err != nil {
+			if !IsFileNotFound(err) {
+				return c.revert(ctx, undo, originals, fmt.Errorf("failed to read original file %s (%w)", path, err))
+			}
+			originals[path] = change{deleted: true}
+		} else {
+			originals[path] = change{contents: original}
+		}
+		undo = append(undo, path)
+
+		ch := c.changes[path]
+		if ch.deleted {
+			err = c.backingStorage.DeleteFile(ctx, path)
+		} else {
+			err = c.backingStorage.PutFile(ctx, path, ch.contents)
+		}
+		if err != nil {
+			return c.revert(ctx, undo, originals, fmt.Errorf("failed to commit file %s (%w)", path, err))
+		}
+	}
+	c.changes = map[Path]change{}
+	return nil
+}
+
+// revert restores the original state of all paths in undo in reverse order and returns the commit error.
+func (c *changeSet) revert(ctx context.Context, undo []Path, originals map[Path]change, commitErr error) error {
+	var errs []error
+	for i := len(undo) - 1; i >= 0; i-- {
+		path := undo[i]
+		original := originals[path]
+		var err error
+		if original.deleted {
+			err = c.backingStorage.DeleteFile(ctx, path)
+		} else {
+			err = c.backingStorage.PutFile(ctx, path, original.contents)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to revert file %s (%w)", path, err))
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("%w, reverting the commit also failed (%w)", commitErr, errors.Join(errs...))
+	}
+	return commitErr
+}
+
+func (c *changeSet) Rollback(_ context.Context) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.changes = map[Path]change{}
+	return nil
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	result := make([]string, 0, len(set))
+	for key := range set {
+		result = append(result, key)
+	}
+	slices.Sort(result)
+	return result
+}
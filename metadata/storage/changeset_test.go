@@ -0,0 +1,166 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package storage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/metadata/storage/filesystem"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+)
+
+// backingStorages lists the storage implementations the change set is tested against. The backends return
+// storage.ErrFileNotFound in different forms, so the change set must work with all of them.
+var backingStorages = map[string]func(t *testing.T) storage.API{
+	"memory": func(_ *testing.T) storage.API {
+		return memory.New()
+	},
+	"filesystem": func(t *testing.T) storage.API {
+		return filesystem.New(t.TempDir())
+	},
+}
+
+// forEachBackingStorage runs the test function as a subtest for each backing storage.
+func forEachBackingStorage(t *testing.T, test func(t *testing.T, backingStorage storage.API)) {
+	for name, factory := range backingStorages {
+		t.Run(name, func(t *testing.T) {
+			test(t, factory(t))
+		})
+	}
+}
+
+func TestChangeSet(t *testing.T) {
+	for name, factory := range backingStorages {
+		t.Run(name, func(t *testing.T) {
+			storage.TestStorageAPI(t, func(t *testing.T) storage.API {
+				return storage.NewChangeSet(factory(t))
+			})
+		})
+	}
+}
+
+func TestChangeSetCommit(t *testing.T) {
+	forEachBackingStorage(t, func(t *testing.T, backingStorage storage.API) {
+		ctx := context.Background()
+		if err := backingStorage.PutFile(ctx, "a/old.txt", []byte("old")); err != nil {
+			t.Fatal(err)
+		}
+
+		changeSet := storage.NewChangeSet(backingStorage)
+		if err := changeSet.PutFile(ctx, "a/new.txt", []byte("new")); err != nil {
+			t.Fatal(err)
+		}
+		if err := changeSet.DeleteFile(ctx, "a/old.txt"); err != nil {
+			t.Fatal(err)
+		}
+
+		files, err := changeSet.ListFiles(ctx, "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 || files[0] != "new.txt" {
+			t.Fatalf("Incorrect files in the change set: %v", files)
+		}
+		if exists, _ := backingStorage.FileExists(ctx, "a/new.txt"); exists {
+			t.Fatalf("The uncommitted file is visible in the backing storage.")
+		}
+
+		if err := changeSet.Commit(ctx); err != nil {
+			t.Fatal(err)
+		}
+		files, err = backingStorage.ListFiles(ctx, "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 || files[0] != "new.txt" {
+			t.Fatalf("Incorrect files in the backing storage after commit: %v", files)
+		}
+	})
+}
+
+func TestChangeSetListDirectoriesDeleted(t *testing.T) {
+	forEachBackingStorage(t, func(t *testing.T, backingStorage storage.API) {
+		ctx := context.Background()
+		for _, file := range []storage.Path{"a/b/x.txt", "a/c/y.txt", "a/d/e/z.txt"} {
+			if err := backingStorage.PutFile(ctx, file, []byte("test")); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		changeSet := storage.NewChangeSet(backingStorage)
+		for _, file := range []storage.Path{"a/b/x.txt", "a/d/e/z.txt"} {
+			if err := changeSet.DeleteFile(ctx, file); err != nil {
+				t.Fatal(err)
+			}
+		}
+		directories, err := changeSet.ListDirectories(ctx, "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(directories) != 1 || directories[0] != "c" {
+			t.Fatalf("Incorrect directories after deleting all their files: %v", directories)
+		}
+
+		if err := changeSet.PutFile(ctx, "a/b/new.txt", []byte("new")); err != nil {
+			t.Fatal(err)
+		}
+		directories, err = changeSet.ListDirectories(ctx, "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(directories) != 2 || directories[0] != "b" || directories[1] != "c" {
+			t.Fatalf("Incorrect directories after writing a new file: %v", directories)
+		}
+	})
+}
+
+func TestChangeSetRollback(t *testing.T) {
+	forEachBackingStorage(t, func(t *testing.T, backingStorage storage.API) {
+		ctx := context.Background()
+
+		changeSet := storage.NewChangeSet(backingStorage)
+		if err := changeSet.PutFile(ctx, "test.txt", []byte("test")); err != nil {
+			t.Fatal(err)
+		}
+		if err := changeSet.Rollback(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if exists, _ := changeSet.FileExists(ctx, "test.txt"); exists {
+			t.Fatalf("The file still exists in the change set after rollback.")
+		}
+		if err := changeSet.Commit(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if exists, _ := backingStorage.FileExists(ctx, "test.txt"); exists {
+			t.Fatalf("The rolled back file was written to the backing storage.")
+		}
+	})
+}
+
+// TestChangeSetCommitFailure tests that changes already written are reverted when a later write fails.
+func TestChangeSetCommitFailure(t *testing.T) {
+	forEachBackingStorage(t, func(t *testing.T, backingStorage storage.API) {
+		ctx := context.Background()
+		if err := backingStorage.PutFile(ctx, "a/b/c.txt", []byte("c")); err != nil {
+			t.Fatal(err)
+		}
+
+		changeSet := storage.NewChangeSet(backingStorage)
+		if err := changeSet.PutFile(ctx, "a/a.txt", []byte("a")); err != nil {
+			t.Fatal(err)
+		}
+		// "a/b" is a directory in the backing storage, so writing it as a file fails.
+		if err := changeSet.PutFile(ctx, "a/b", []byte("b")); err != nil {
+			t.Fatal(err)
+		}
+		if err := changeSet.Commit(ctx); err == nil {
+			t.Fatalf("Committing a conflicting file did not result in an error.")
+		}
+		if exists, _ := backingStorage.FileExists(ctx, "a/a.txt"); exists {
+			t.Fatalf("The partially committed file was not reverted.")
+		}
+	})
+}
@@ -4,6 +4,7 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -17,6 +18,14 @@ func (e ErrFileNotFound) Error() string {
 	return fmt.Sprintf("File not found %s", e.Path)
 }
 
+// IsFileNotFound returns true if err is or wraps an ErrFileNotFound. Storage implementations return it both by
+// value and as a pointer, so both forms are matched.
+func IsFileNotFound(err error) bool {
+	var notFound ErrFileNotFound
+	var notFoundPtr *ErrFileNotFound
+	return errors.As(err, &notFound) || errors.As(err, &notFoundPtr)
+}
+
 // ErrFileAlreadyExists signals that a file or directory already exists.
 type ErrFileAlreadyExists struct {
 	Path Path
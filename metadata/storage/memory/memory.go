@@ -7,11 +7,12 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"sync"
 
 	"github.com/opentofu/libregistry/metadata/storage"
 )
 
-// New returns an in-memory filesystem for testing purposes.
+// New returns an in-memory filesystem for testing purposes. It is safe for concurrent use.
 func New() storage.API {
 	return &api{
 		root: &directory{
@@ -22,6 +23,7 @@ func New() storage.API {
 }
 
 type api struct {
+	lock sync.Mutex
 	root *directory
 }
 
@@ -31,6 +33,9 @@ type directory struct {
 }
 
 func (a *api) ListFiles(_ context.Context, directory storage.Path) ([]string, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
 	if err := directory.Validate(); err != nil {
 		return nil, err
 	}
@@ -87,6 +92,9 @@ func (a *api) ensureDirectory(path storage.Path) *directory {
 }
 
 func (a *api) ListDirectories(_ context.Context, directory storage.Path) ([]string, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
 	if err := directory.Validate(); err != nil {
 		return nil, err
 	}
@@ -109,6 +117,9 @@ func (a *api) ListDirectories(_ context.Context, directory storage.Path) ([]stri
 }
 
 func (a *api) PutFile(_ context.Context, filePath storage.Path, contents []byte) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
 	if err := filePath.Validate(); err != nil {
 		return err
 	}
@@ -126,6 +137,9 @@ func (a *api) PutFile(_ context.Context, filePath storage.Path, contents []byte)
 }
 
 func (a *api) GetFile(_ context.Context, filePath storage.Path) ([]byte, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
 	if err := filePath.Validate(); err != nil {
 		return nil, err
 	}
@@ -144,6 +158,9 @@ func (a *api) GetFile(_ context.Context, filePath storage.Path) ([]byte, error)
 }
 
 func (a *api) FileExists(_ context.Context, filePath storage.Path) (bool, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
 	if err := filePath.Validate(); err != nil {
 		return false, err
 	}
@@ -161,6 +178,9 @@ func (a *api) FileExists(_ context.Context, filePath storage.Path) (bool, error)
 }
 
 func (a *api) DeleteFile(_ context.Context, filePath storage.Path) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
 	if err := filePath.Validate(); err != nil {
 		return err
 	}
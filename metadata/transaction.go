@@ -0,0 +1,58 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+)
+
+// Transaction is a set of changes to the registry data that is applied all at once. Reads on the transaction return
+// the uncommitted state, while reads on the API the transaction was started from only see the changes after Commit.
+type Transaction interface {
+	ModuleDataAPI
+	ProviderDataAPI
+
+	// Commit writes all changes in the transaction to the backing storage. If any write fails, the changes already
+	// written are reverted and an error is returned. The transaction is empty after a successful commit.
+	Commit(ctx context.Context) error
+	// Rollback discards all changes in the transaction.
+	Rollback(ctx context.Context) error
+}
+
+func (r registryDataAPI) Begin(_ context.Context) (Transaction, error) {
+	changeSet := storage.NewChangeSet(r.storageAPI)
+	return &transaction{
+		registryDataAPI: registryDataAPI{
+			storageAPI: changeSet,
+		},
+		changeSet: changeSet,
+	}, nil
+}
+
+// inTransaction runs fn on a change set on top of the storage. The changes are committed if fn succeeds and discarded
+// otherwise, so functions writing several files never leave a partial change behind.
+func (r registryDataAPI) inTransaction(ctx context.Context, fn func(tx registryDataAPI) error) error {
+	changeSet := storage.NewChangeSet(r.storageAPI)
+	if err := fn(registryDataAPI{storageAPI: changeSet}); err != nil {
+		_ = changeSet.Rollback(ctx)
+		return err
+	}
+	return changeSet.Commit(ctx)
+}
+
+type transaction struct {
+	registryDataAPI
+
+	changeSet storage.ChangeSet
+}
+
+func (t *transaction) Commit(ctx context.Context) error {
+	return t.changeSet.Commit(ctx)
+}
+
+func (t *transaction) Rollback(ctx context.Context) error {
+	return t.changeSet.Rollback(ctx)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/metadata/storage/filesystem"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+// storageBackends lists the storage implementations the metadata API is tested against. They report missing
+// files differently, so the API must work with all of them.
+var storageBackends = map[string]func(t *testing.T) storage.API{
+	"memory": func(_ *testing.T) storage.API {
+		return memory.New()
+	},
+	"filesystem": func(t *testing.T) storage.API {
+		return filesystem.New(t.TempDir())
+	},
+}
+
+// forEachStorageBackend runs the test function as a subtest with a fresh metadata API for each storage backend.
+func forEachStorageBackend(t *testing.T, test func(t *testing.T, api metadata.API)) {
+	for name, factory := range storageBackends {
+		t.Run(name, func(t *testing.T) {
+			api, err := metadata.New(factory(t))
+			if err != nil {
+				t.Fatalf("Failed to initialize API (%v)", err)
+			}
+			test(t, api)
+		})
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	forEachStorageBackend(t, func(t *testing.T, api metadata.API) {
+		ctx := context.Background()
+		moduleAddr := module.Addr{
+			Namespace:    "opentofu",
+			Name:         "test",
+			TargetSystem: "aws",
+		}
+
+		for _, commit := range []bool{false, true} {
+			tx, err := api.Begin(ctx)
+			if err != nil {
+				t.Fatalf("Failed to begin transaction (%v)", err)
+			}
+			if err := tx.PutModule(ctx, moduleAddr, module.Metadata{}); err != nil {
+				t.Fatalf("Failed to put module (%v)", err)
+			}
+			modules, err := tx.ListModules(ctx)
+			if err != nil {
+				t.Fatalf("Failed to list modules (%v)", err)
+			}
+			if len(modules) != 1 {
+				t.Fatalf("The uncommitted module is not visible in the transaction.")
+			}
+			modules, err = api.ListModules(ctx)
+			if err != nil {
+				t.Fatalf("Failed to list modules (%v)", err)
+			}
+			if len(modules) != 0 {
+				t.Fatalf("The uncommitted module is visible outside the transaction.")
+			}
+
+			if commit {
+				err = tx.Commit(ctx)
+			} else {
+				err = tx.Rollback(ctx)
+			}
+			if err != nil {
+				t.Fatalf("Failed to finish transaction (%v)", err)
+			}
+			modules, err = api.ListModules(ctx)
+			if err != nil {
+				t.Fatalf("Failed to list modules (%v)", err)
+			}
+			if commit && len(modules) != 1 {
+				t.Fatalf("The committed module is not visible.")
+			}
+			if !commit && len(modules) != 0 {
+				t.Fatalf("The rolled back module is visible.")
+			}
+		}
+	})
+}
+
+// TestTransactionNewProvider tests that writing a provider that did not exist before commits its change set.
+func TestTransactionNewProvider(t *testing.T) {
+	forEachStorageBackend(t, func(t *testing.T, api metadata.API) {
+		ctx := context.Background()
+		providerAddr := provider.Addr{
+			Namespace: "opentofu",
+			Name:      "test",
+		}
+		if err := api.PutProvider(ctx, providerAddr, provider.Metadata{}); err != nil {
+			t.Fatalf("Failed to put new provider (%v)", err)
+		}
+		if _, err := api.GetProvider(ctx, providerAddr, false); err != nil {
+			t.Fatalf("Failed to get the new provider (%v)", err)
+		}
+		var notFound *metadata.ProviderNotFoundError
+		if _, err := api.GetProvider(ctx, provider.Addr{Namespace: "opentofu", Name: "missing"}, false); !errors.As(err, &notFound) {
+			t.Fatalf("Getting a missing provider did not return a ProviderNotFoundError (%v)", err)
+		}
+	})
+}
@@ -5,8 +5,10 @@ package libregistry
 
 import (
 	"context"
+	"errors"
 
 	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
 )
 
 func (m api) AddModule(ctx context.Context, repository string) error {
@@ -27,16 +29,123 @@ func (m api) AddModule(ctx context.Context, repository string) error {
 		}
 	}
 
+	if err := m.checkModuleNotExists(ctx, submitted); err != nil {
+		return err
+	}
+
+	if err := m.checkModuleValid(ctx, submitted, module.Source{}); err != nil {
+		return err
+	}
+
+	forkNotice, err := m.checkModuleFork(ctx, submitted, module.Source{})
+	if err != nil {
+		return err
+	}
+
+	_, err = m.refreshModule(ctx, submitted, module.Metadata{
+		Fork: forkNotice,
+	})
+	return err
+}
+
+func (m api) AddModuleAs(ctx context.Context, username vcs.Username, repository string) error {
+	vcsRepository, err := m.vcsClient.ParseRepositoryAddr(repository)
+	if err != nil {
+		return err
+	}
+	if err := m.checkPermission(ctx, username, vcsRepository.Org); err != nil {
+		return err
+	}
+	return m.AddModule(ctx, repository)
+}
+
+func (m api) AddModuleFromSource(ctx context.Context, moduleAddr module.Addr, source module.Source) error {
+	if err := moduleAddr.Validate(); err != nil {
+		return &ModuleAddFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+	if err := source.Validate(); err != nil {
+		return &ModuleAddFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+
+	if err := m.checkModuleNotExists(ctx, moduleAddr); err != nil {
+		return err
+	}
+
+	if err := m.checkModuleValid(ctx, moduleAddr, source); err != nil {
+		return err
+	}
+
+	forkNotice, err := m.checkModuleFork(ctx, moduleAddr, source)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.refreshModule(ctx, moduleAddr, module.Metadata{
+		Source: source,
+		Fork:   forkNotice,
+	})
+	return err
+}
+
+func (m api) checkModuleNotExists(ctx context.Context, moduleAddr module.Addr) error {
 	modules, err := m.dataAPI.ListModules(ctx)
 	if err != nil {
 		return err
 	}
 
 	for _, p := range modules {
-		if p.Equals(submitted) {
-			return &ModuleAlreadyExistsError{submitted}
+		if p.Equals(moduleAddr) {
+			return &ModuleAlreadyExistsError{moduleAddr}
 		}
 	}
+	return nil
+}
 
-	return m.UpdateModule(ctx, submitted)
+// checkModuleValid runs the module validation and returns the *ModuleValidationFailedError as is, so callers can
+// inspect the failed checks. Errors running the checks are wrapped in a *ModuleAddFailedError.
+func (m api) checkModuleValid(ctx context.Context, moduleAddr module.Addr, source module.Source) error {
+	err := m.validateModule(ctx, moduleAddr, source)
+	if err == nil {
+		return nil
+	}
+	var validationErr *ModuleValidationFailedError
+	if errors.As(err, &validationErr) {
+		return err
+	}
+	return &ModuleAddFailedError{
+		moduleAddr,
+		err,
+	}
+}
+
+// checkModuleFork returns a *ModuleForkRejectedError if the fork policy rejects the module. If the policy accepts
+// the fork with a notice, the notice is returned to be stored with the module.
+func (m api) checkModuleFork(ctx context.Context, moduleAddr module.Addr, source module.Source) (*module.ForkNotice, error) {
+	decision, err := m.decideModuleFork(ctx, moduleAddr, source)
+	if err != nil {
+		return nil, &ModuleAddFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+	if decision == nil {
+		return nil, nil
+	}
+	if !decision.Allowed {
+		return nil, &ModuleForkRejectedError{
+			Module:   moduleAddr,
+			Decision: *decision,
+		}
+	}
+	return &module.ForkNotice{
+		Policy: string(decision.Policy),
+		ForkOf: decision.ForkOf.String(),
+		Reason: decision.Reason,
+	}, nil
 }
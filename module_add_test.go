@@ -6,14 +6,17 @@ package libregistry_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/fs"
 	"os"
+	"path"
 	"testing"
+	"testing/fstest"
 
 	"github.com/opentofu/libregistry"
 	"github.com/opentofu/libregistry/metadata"
 	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/protocol"
 	"github.com/opentofu/libregistry/types/module"
 	"github.com/opentofu/libregistry/vcs"
 	"github.com/opentofu/libregistry/vcs/fakevcs"
@@ -99,7 +102,7 @@ func TestAddModule(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+	if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", testModuleContents(".")); err != nil {
 		t.Fatal(err)
 	}
 
@@ -120,3 +123,374 @@ func TestAddModule(t *testing.T) {
 		t.Fatalf("Incorrect version stored: %s", ver)
 	}
 }
+
+func TestAddModuleFromSource(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "vpc",
+		TargetSystem: "aws",
+	}
+	repo := vcs.RepositoryAddr{
+		Org:  "test",
+		Name: "platform-modules",
+	}
+	source := module.Source{
+		CustomRepository: repo.String(),
+		TagPrefix:        "vpc/",
+		Subdirectory:     "modules/vpc",
+	}
+
+	ctx := context.Background()
+	inMemoryVCS := fakevcs.New()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []vcs.VersionNumber{"vpc/v1.0.0", "iam/v2.0.0", "vpc/v1.1.0", "v3.0.0"} {
+		if err := inMemoryVCS.CreateVersion(repo, tag, testModuleContents(source.Subdirectory)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	invalidSource := source
+	invalidSource.Subdirectory = "../vpc"
+	if err := registry.AddModuleFromSource(ctx, moduleAddr, invalidSource); err == nil {
+		t.Fatalf("❌ Adding a module with an invalid subdirectory did not fail.")
+	}
+
+	if err := registry.AddModuleFromSource(ctx, moduleAddr, source); err != nil {
+		t.Fatalf("❌ Failed to add module (%v)", err)
+	}
+
+	stored, err := dataAPI.GetModule(ctx, moduleAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Source != source {
+		t.Fatalf("❌ Incorrect source stored: %v", stored.Source)
+	}
+	if len(stored.Versions) != 2 || stored.Versions[0].Version != "v1.1.0" || stored.Versions[1].Version != "v1.0.0" {
+		t.Fatalf("❌ Incorrect versions stored: %v", stored.Versions)
+	}
+	tag, err := inMemoryVCS.GetTagVersion(ctx, repo, "vpc/v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Versions[0].Commit != tag.Commit {
+		t.Fatalf("❌ Incorrect commit stored: %s (expected: %s)", stored.Versions[0].Commit, tag.Commit)
+	}
+
+	downloadURL, err := protocol.GitHubModuleSource(ctx, moduleAddr, stored, stored.Versions[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "git::https://github.com/test/platform-modules//modules/vpc?ref=vpc/v1.1.0"; downloadURL != expected {
+		t.Fatalf("❌ Incorrect download URL: %s (expected: %s)", downloadURL, expected)
+	}
+
+	if err := registry.AddModuleFromSource(ctx, moduleAddr, source); err == nil {
+		t.Fatalf("❌ Adding the module twice did not fail.")
+	}
+	t.Logf("✅ The module was added from the shared repository.")
+}
+
+// TestAddModuleFromSourceWithoutV tests that the download address points to the tag as it exists in the repository
+// when the tag has no "v" before the version number.
+func TestAddModuleFromSourceWithoutV(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "vpc",
+		TargetSystem: "aws",
+	}
+	repo := vcs.RepositoryAddr{
+		Org:  "test",
+		Name: "platform-modules",
+	}
+	source := module.Source{
+		CustomRepository: repo.String(),
+		TagPrefix:        "vpc/",
+	}
+
+	ctx := context.Background()
+	inMemoryVCS := fakevcs.New()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateVersion(repo, "vpc/1.2.0", testModuleContents(".")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := registry.AddModuleFromSource(ctx, moduleAddr, source); err != nil {
+		t.Fatalf("❌ Failed to add module (%v)", err)
+	}
+	stored, err := dataAPI.GetModule(ctx, moduleAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Versions) != 1 || stored.Versions[0].Version != "1.2.0" || stored.Versions[0].Tag != "vpc/1.2.0" {
+		t.Fatalf("❌ Incorrect versions stored: %v", stored.Versions)
+	}
+
+	downloadURL, err := protocol.GitHubModuleSource(ctx, moduleAddr, stored, stored.Versions[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "git::https://github.com/test/platform-modules?ref=vpc/1.2.0"; downloadURL != expected {
+		t.Fatalf("❌ Incorrect download URL: %s (expected: %s)", downloadURL, expected)
+	}
+	t.Logf("✅ The download address points to the real tag.")
+}
+
+// TestAddModuleValidation tests that a repository that does not hold a valid module is rejected with every failed
+// check listed.
+func TestAddModuleValidation(t *testing.T) {
+	ctx := context.Background()
+	inMemoryVCS := fakevcs.New()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("⚙️ Submitting an archived fork with a broken configuration file...")
+	repo := vcs.RepositoryAddr{
+		Org:  "test",
+		Name: "terraform-aws-vpc",
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{
+		ForkOf: &vcs.RepositoryAddr{
+			Org:  "upstream",
+			Name: "terraform-aws-vpc",
+		},
+		Archived: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", testModuleContents(".")); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateVersion(repo, "v1.1.0", fstest.MapFS{
+		"main.tf": {Data: []byte("variable \"name\" {\n")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	err = registry.AddModule(ctx, repo.String())
+	var validationErr *libregistry.ModuleValidationFailedError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("❌ Expected a module validation error, got: %v", err)
+	}
+	if validationErr.Version != "v1.1.0" {
+		t.Fatalf("❌ Incorrect version validated: %s", validationErr.Version)
+	}
+	if len(validationErr.Failures) != 2 ||
+		!validationErr.Failed(libregistry.ModuleValidationCheckArchivedFork) ||
+		!validationErr.Failed(libregistry.ModuleValidationCheckSyntax) {
+		t.Fatalf("❌ Incorrect failed checks: %v", validationErr.Failures)
+	}
+	if _, err := dataAPI.GetModule(ctx, module.Addr{Namespace: "test", Name: "vpc", TargetSystem: "aws"}); err == nil {
+		t.Fatalf("❌ The rejected module was stored.")
+	}
+	t.Logf("✅ The archived fork with a syntax error was rejected with both checks.")
+
+	t.Logf("⚙️ Submitting a repository without configuration files...")
+	emptyRepo := vcs.RepositoryAddr{
+		Org:  "test",
+		Name: "terraform-aws-empty",
+	}
+	if err := inMemoryVCS.CreateRepository(emptyRepo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateVersion(emptyRepo, "v1.0.0", fstest.MapFS{
+		"README.md": {Data: []byte("# Not a module\n")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	err = registry.AddModule(ctx, emptyRepo.String())
+	if !errors.As(err, &validationErr) || !validationErr.Failed(libregistry.ModuleValidationCheckConfigurationFiles) {
+		t.Fatalf("❌ Expected a configuration files failure, got: %v", err)
+	}
+	t.Logf("✅ The repository without configuration files was rejected.")
+}
+
+// TestAddModuleForkPolicy tests that the fork policy rejects or reports a fork of a registered module.
+func TestAddModuleForkPolicy(t *testing.T) {
+	parentRepo := vcs.RepositoryAddr{
+		Org:  "upstream",
+		Name: "terraform-aws-vpc",
+	}
+	forkRepo := vcs.RepositoryAddr{
+		Org:  "test",
+		Name: "terraform-aws-vpc",
+	}
+	forkAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "vpc",
+		TargetSystem: "aws",
+	}
+	setup := func(t *testing.T, policy libregistry.ForkPolicy) (fakevcs.VCSClient, libregistry.API) {
+		ctx := context.Background()
+		inMemoryVCS := fakevcs.New()
+		dataAPI, err := metadata.New(memory.New())
+		if err != nil {
+			t.Fatal(err)
+		}
+		registry, err := libregistry.New(inMemoryVCS, dataAPI, libregistry.WithForkPolicy(policy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, org := range []vcs.OrganizationAddr{parentRepo.Org, forkRepo.Org} {
+			if err := inMemoryVCS.CreateOrganization(org); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := inMemoryVCS.CreateRepository(parentRepo, vcs.RepositoryInfo{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := inMemoryVCS.CreateVersion(parentRepo, "v1.0.0", testModuleContents(".")); err != nil {
+			t.Fatal(err)
+		}
+		if err := registry.AddModule(ctx, parentRepo.String()); err != nil {
+			t.Fatal(err)
+		}
+		if err := inMemoryVCS.CreateRepository(forkRepo, vcs.RepositoryInfo{ForkOf: &parentRepo}); err != nil {
+			t.Fatal(err)
+		}
+		return inMemoryVCS, registry
+	}
+
+	t.Run("reject", func(t *testing.T) {
+		_, registry := setup(t, libregistry.ForkPolicyReject)
+		err := registry.AddModule(context.Background(), forkRepo.String())
+		var rejected *libregistry.ModuleForkRejectedError
+		if !errors.As(err, &rejected) {
+			t.Fatalf("❌ Expected a fork rejection, got: %v", err)
+		}
+		if rejected.Decision.ForkOf != parentRepo || rejected.Decision.Allowed {
+			t.Fatalf("❌ Incorrect decision: %v", rejected.Decision)
+		}
+		t.Logf("✅ The fork was rejected: %v", err)
+	})
+	t.Run("require_divergence", func(t *testing.T) {
+		inMemoryVCS, registry := setup(t, libregistry.ForkPolicyRequireDivergence)
+		ctx := context.Background()
+		var rejected *libregistry.ModuleForkRejectedError
+		if err := registry.AddModule(ctx, forkRepo.String()); !errors.As(err, &rejected) {
+			t.Fatalf("❌ Expected the unchanged fork to be rejected, got: %v", err)
+		}
+		t.Logf("✅ The unchanged fork was rejected.")
+
+		if err := inMemoryVCS.CreateVersion(forkRepo, "v1.1.0", testModuleContents(".")); err != nil {
+			t.Fatal(err)
+		}
+		if err := registry.AddModule(ctx, forkRepo.String()); err != nil {
+			t.Fatalf("❌ Failed to add the diverged fork (%v)", err)
+		}
+		t.Logf("✅ The diverged fork was added.")
+	})
+	t.Run("warn", func(t *testing.T) {
+		_, registry := setup(t, libregistry.ForkPolicyWarn)
+		ctx := context.Background()
+		if err := registry.AddModule(ctx, forkRepo.String()); err != nil {
+			t.Fatalf("❌ Failed to add the fork (%v)", err)
+		}
+		report, err := registry.UpdateModules(ctx, []module.Addr{forkAddr})
+		if err != nil {
+			t.Fatal(err)
+		}
+		decision := report.Results[0].Fork
+		if decision == nil || !decision.Allowed || decision.Policy != libregistry.ForkPolicyWarn {
+			t.Fatalf("❌ The update report does not contain the fork warning: %v", decision)
+		}
+		t.Logf("✅ The update report contains the fork warning: %s", decision.Reason)
+	})
+}
+
+// TestAddModuleAs tests that only members of the organization owning the repository can add a module.
+func TestAddModuleAs(t *testing.T) {
+	ctx := context.Background()
+	inMemoryVCS := fakevcs.New()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := vcs.RepositoryAddr{
+		Org:  "test",
+		Name: "terraform-aws-vpc",
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", testModuleContents(".")); err != nil {
+		t.Fatal(err)
+	}
+	for _, username := range []vcs.Username{"member", "outsider"} {
+		if err := inMemoryVCS.AddUser(username); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := inMemoryVCS.AddMember(repo.Org, "member"); err != nil {
+		t.Fatal(err)
+	}
+
+	err = registry.AddModuleAs(ctx, "outsider", repo.String())
+	var permissionDenied *libregistry.PermissionDeniedError
+	if !errors.As(err, &permissionDenied) {
+		t.Fatalf("❌ Expected a permission denied error, got: %v", err)
+	}
+	if permissionDenied.Username != "outsider" || permissionDenied.Organization != repo.Org {
+		t.Fatalf("❌ Incorrect permission denied error: %v", permissionDenied)
+	}
+	if modules, err := dataAPI.ListModules(ctx); err != nil || len(modules) != 0 {
+		t.Fatalf("❌ The module was stored despite the denied permission: %v (%v)", modules, err)
+	}
+	t.Logf("✅ The outsider was denied.")
+
+	if err := registry.AddModuleAs(ctx, "member", repo.String()); err != nil {
+		t.Fatalf("❌ Failed to add the module as a member (%v)", err)
+	}
+	if _, err := dataAPI.GetModule(ctx, module.Addr{Namespace: "test", Name: "vpc", TargetSystem: "aws"}); err != nil {
+		t.Fatalf("❌ The module was not stored (%v)", err)
+	}
+	t.Logf("✅ The member added the module.")
+}
+
+// testModuleContents returns the contents of a minimal valid module in the given directory.
+func testModuleContents(dir string) fstest.MapFS {
+	return fstest.MapFS{
+		path.Join(dir, "main.tf"): {Data: []byte("variable \"name\" {\n  type = string\n}\n")},
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// ForkDecision describes how the fork policy treated a module whose repository is a fork.
+type ForkDecision struct {
+	// Policy is the fork policy that was applied.
+	Policy ForkPolicy
+	// ForkOf is the parent repository of the module repository.
+	ForkOf vcs.RepositoryAddr
+	// Allowed is false if the policy rejects the fork.
+	Allowed bool
+	// Reason is a human-readable explanation of the decision.
+	Reason string
+}
+
+// decideModuleFork applies the fork policy to the module repository. It returns nil if the policy has nothing to
+// report, for example because the repository is not a fork.
+func (m api) decideModuleFork(ctx context.Context, moduleAddr module.Addr, source module.Source) (*ForkDecision, error) {
+	if m.config.ForkPolicy == ForkPolicyAllow {
+		return nil, nil
+	}
+	repo, err := m.getModuleRepo(moduleAddr, module.Metadata{Source: source})
+	if err != nil {
+		return nil, err
+	}
+	repoInfo, err := m.vcsClient.GetRepositoryInfo(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	if repoInfo.ForkOf == nil {
+		return nil, nil
+	}
+	decision := &ForkDecision{
+		Policy: m.config.ForkPolicy,
+		ForkOf: *repoInfo.ForkOf,
+	}
+
+	switch m.config.ForkPolicy {
+	case ForkPolicyReject:
+		decision.Reason = "the repository " + repo.String() + " is a fork of " + repoInfo.ForkOf.String() + " and forks are not accepted"
+	case ForkPolicyWarn:
+		parentAddr, registered, err := m.isParentModuleRegistered(ctx, *repoInfo.ForkOf)
+		if err != nil {
+			return nil, err
+		}
+		if !registered {
+			return nil, nil
+		}
+		decision.Allowed = true
+		decision.Reason = "the repository " + repo.String() + " is a fork of " + repoInfo.ForkOf.String() + ", which is already registered as " + parentAddr.String()
+	case ForkPolicyRequireDivergence:
+		divergedTag, err := m.findDivergedTag(ctx, repo, *repoInfo.ForkOf, source)
+		if err != nil {
+			return nil, err
+		}
+		if divergedTag == "" {
+			decision.Reason = "the repository " + repo.String() + " is a fork of " + repoInfo.ForkOf.String() + " without any tags of its own"
+		} else {
+			decision.Allowed = true
+			decision.Reason = "the repository " + repo.String() + " is a fork of " + repoInfo.ForkOf.String() + ", but has diverged at tag " + string(divergedTag)
+		}
+	}
+	return decision, nil
+}
+
+// storedForkDecision returns the fork policy decision recorded when the module was added, or nil if there is none.
+func (m api) storedForkDecision(notice *module.ForkNotice) (*ForkDecision, error) {
+	if notice == nil {
+		return nil, nil
+	}
+	forkOf, err := m.vcsClient.ParseRepositoryAddr(notice.ForkOf)
+	if err != nil {
+		return nil, err
+	}
+	return &ForkDecision{
+		Policy:  ForkPolicy(notice.Policy),
+		ForkOf:  forkOf,
+		Allowed: true,
+		Reason:  notice.Reason,
+	}, nil
+}
+
+// isParentModuleRegistered checks if the parent repository of a fork is registered as a module under the naming
+// convention.
+func (m api) isParentModuleRegistered(ctx context.Context, parent vcs.RepositoryAddr) (module.Addr, bool, error) {
+	parentAddr, err := module.AddrFromRepository(parent)
+	if err != nil || parentAddr.Validate() != nil {
+		// The parent does not follow the naming convention, so it cannot be registered under it.
+		return module.Addr{}, false, nil
+	}
+	if _, err := m.dataAPI.GetModule(ctx, parentAddr); err != nil {
+		var notFoundError *metadata.ModuleNotFoundError
+		if errors.As(err, &notFoundError) {
+			return parentAddr, false, nil
+		}
+		return parentAddr, false, err
+	}
+	return parentAddr, true, nil
+}
+
+// findDivergedTag returns a version tag of the fork that the parent repository does not have, or that points to a
+// different commit. It returns an empty string if the fork has not diverged. A parent that no longer exists counts
+// as diverged, so the first version tag of the fork is returned.
+func (m api) findDivergedTag(ctx context.Context, repo vcs.RepositoryAddr, parent vcs.RepositoryAddr, source module.Source) (vcs.VersionNumber, error) {
+	forkTags, err := m.vcsClient.ListAllTags(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+	parentCommits := map[vcs.VersionNumber]string{}
+	parentTags, err := m.vcsClient.ListAllTags(ctx, parent)
+	if err != nil {
+		var notFoundError *vcs.RepositoryNotFoundError
+		if !errors.As(err, &notFoundError) {
+			return "", err
+		}
+	}
+	for _, tag := range parentTags {
+		parentCommits[tag.VersionNumber] = tag.Commit
+	}
+	for _, tag := range forkTags {
+		if _, ok := source.VersionFromTag(tag.VersionNumber); !ok {
+			continue
+		}
+		parentCommit, ok := parentCommits[tag.VersionNumber]
+		if !ok || (parentCommit != "" && tag.Commit != "" && parentCommit != tag.Commit) {
+			return tag.VersionNumber, nil
+		}
+	}
+	return "", nil
+}
@@ -6,15 +6,30 @@ package libregistry
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
 
+	"github.com/opentofu/libregistry/internal/moduledetails"
 	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/types"
 	"github.com/opentofu/libregistry/types/module"
 	"github.com/opentofu/libregistry/vcs"
 )
 
 func (m api) UpdateModule(ctx context.Context, moduleAddr module.Addr) error {
+	_, err := m.updateModule(ctx, moduleAddr)
+	return err
+}
+
+// updateModule updates the module and returns the versions that were added, removed or affected by a tag change in
+// the process.
+func (m api) updateModule(ctx context.Context, moduleAddr module.Addr) (ModuleUpdateResult, error) {
+	result := ModuleUpdateResult{
+		Module: moduleAddr,
+	}
 	if err := moduleAddr.Validate(); err != nil {
-		return &ModuleUpdateFailedError{
+		return result, &ModuleUpdateFailedError{
 			moduleAddr,
 			err,
 		}
@@ -24,68 +39,264 @@ func (m api) UpdateModule(ctx context.Context, moduleAddr module.Addr) error {
 	if err != nil {
 		var notFoundError *metadata.ModuleNotFoundError
 		if !errors.As(err, &notFoundError) {
-			return &ModuleUpdateFailedError{
+			return result, &ModuleUpdateFailedError{
 				moduleAddr,
 				err,
 			}
 		}
 		moduleMetadata = module.Metadata{}
 	}
+	forkDecision, err := m.storedForkDecision(moduleMetadata.Fork)
+	if err != nil {
+		return result, &ModuleUpdateFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+	result, err = m.refreshModule(ctx, moduleAddr, moduleMetadata)
+	result.Fork = forkDecision
+	return result, err
+}
 
-	previousSize := len(moduleMetadata.Versions)
-	tags, err := m.vcsClient.ListLatestTags(ctx, getModuleRepo(moduleAddr))
+// refreshModule updates the versions in the given module metadata from the module repository and stores the result.
+func (m api) refreshModule(ctx context.Context, moduleAddr module.Addr, moduleMetadata module.Metadata) (ModuleUpdateResult, error) {
+	result := ModuleUpdateResult{
+		Module: moduleAddr,
+	}
+	repo, err := m.getModuleRepo(moduleAddr, moduleMetadata)
 	if err != nil {
-		return &ModuleUpdateFailedError{
+		return result, &ModuleUpdateFailedError{
 			moduleAddr,
 			err,
 		}
 	}
-	var newVersions module.VersionList
-	for _, tag := range tags {
-		ver, err := module.VersionFromVCS(tag.VersionNumber)
-		if err != nil {
-			continue
+	previousVersions := moduleMetadata.Versions
+
+	// The module file and the details of new versions are written in one transaction so a failed update does not
+	// leave details of unpublished versions behind.
+	tx, err := m.dataAPI.Begin(ctx)
+	if err != nil {
+		return result, &ModuleUpdateFailedError{
+			moduleAddr,
+			err,
 		}
-		newVersions = append(newVersions, module.Version{
-			Version: ver,
-		})
 	}
-	moduleMetadata.Versions = moduleMetadata.Versions.Merge(newVersions)
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
 
-	if len(moduleMetadata.Versions) == previousSize+len(tags) {
-		// No overlap found, do the full query:
-		tags, err = m.vcsClient.ListAllTags(ctx, getModuleRepo(moduleAddr))
+	// Deleted tags can only be detected using the full tag list, so the latest tags are only enough if the published
+	// versions are kept anyway.
+	fullList := m.config.TagChangePolicy != TagChangePolicyKeep
+	var tags map[module.VersionNumber]vcs.Version
+	if !fullList {
+		latestTags, err := m.vcsClient.ListLatestTags(ctx, repo)
+		if err != nil {
+			return result, &ModuleUpdateFailedError{
+				moduleAddr,
+				err,
+			}
+		}
+		tags = moduleTags(moduleMetadata.Source, latestTags)
+		// No overlap found, there may be more new tags than the latest tags contain, do the full query. The full
+		// query is also needed if the latest tags lack the commit of a new version, for example from a feed.
+		fullList = !overlaps(previousVersions, tags) || missesNewCommits(previousVersions, tags)
+	}
+	if fullList {
+		allTags, err := m.vcsClient.ListAllTags(ctx, repo)
 		if err != nil {
-			return &ModuleUpdateFailedError{
+			return result, &ModuleUpdateFailedError{
 				moduleAddr,
 				err,
 			}
 		}
-		newVersions = nil
-		for _, tag := range tags {
-			ver, err := module.VersionFromVCS(tag.VersionNumber)
-			if err != nil {
-				continue
+		tags = moduleTags(moduleMetadata.Source, allTags)
+	}
+
+	// Versions removed by the tag change policy are not published again.
+	for _, removed := range moduleMetadata.RemovedVersions {
+		delete(tags, removed.Normalize())
+	}
+
+	var versions module.VersionList
+	for _, ver := range previousVersions {
+		tag, tagExists := tags[ver.Version.Normalize()]
+		delete(tags, ver.Version.Normalize())
+
+		var change *TagChange
+		switch {
+		case !tagExists && fullList:
+			change = &TagChange{
+				Version:        ver.Version,
+				PreviousCommit: ver.Commit,
+			}
+		case !tagExists:
+		case ver.Commit != "" && tag.Commit != "" && ver.Commit != tag.Commit:
+			change = &TagChange{
+				Version:        ver.Version,
+				PreviousCommit: ver.Commit,
+				Commit:         tag.Commit,
+			}
+		default:
+			ver = backfillModuleVersion(ver, tag)
+		}
+		if change == nil || (ver.Yanked && m.config.TagChangePolicy == TagChangePolicyYank) {
+			versions = append(versions, ver)
+			continue
+		}
+
+		change.Action = m.config.TagChangePolicy
+		result.TagChanges = append(result.TagChanges, *change)
+		switch m.config.TagChangePolicy {
+		case TagChangePolicyKeep:
+			// Record the commit of the moved tag so the change is only reported once.
+			if !change.Deleted() {
+				ver.Commit = change.Commit
+			}
+		case TagChangePolicyRemove:
+			moduleMetadata.RemovedVersions = append(moduleMetadata.RemovedVersions, ver.Version)
+			continue
+		case TagChangePolicyYank:
+			ver.Yanked = true
+			ver.YankReason = change.Reason()
+		}
+		versions = append(versions, ver)
+	}
+	for _, tag := range tags {
+		// The version is stored as tagged, the download address is built from it.
+		version, _ := moduleMetadata.Source.VersionFromTag(tag.VersionNumber)
+		ver := module.Version{
+			Version: version,
+			Tag:     tag.VersionNumber,
+			Commit:  tag.Commit,
+			Created: types.OptionalTime(tag.Created),
+		}
+		if m.config.ModuleDetails {
+			if err := m.storeModuleVersionDetails(ctx, tx, moduleAddr, moduleMetadata.Source, repo, ver); err != nil {
+				return result, &ModuleUpdateFailedError{
+					moduleAddr,
+					err,
+				}
 			}
-			newVersions = append(newVersions, module.Version{
-				Version: ver,
-			})
 		}
-		moduleMetadata.Versions = newVersions
+		versions = append(versions, ver)
 	}
+	versions.Sort()
+	moduleMetadata.Versions = versions
 
-	if err := m.dataAPI.PutModule(ctx, moduleAddr, moduleMetadata); err != nil {
-		return &ModuleAddFailedError{
+	if err := tx.PutModule(ctx, moduleAddr, moduleMetadata); err != nil {
+		return result, &ModuleAddFailedError{
 			moduleAddr,
 			err,
 		}
 	}
-	return nil
+	if err := tx.Commit(ctx); err != nil {
+		return result, &ModuleAddFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+	result.AddedVersions, result.RemovedVersions = diffModuleVersions(previousVersions, moduleMetadata.Versions)
+	return result, nil
 }
 
-func getModuleRepo(module module.Addr) vcs.RepositoryAddr {
-	return vcs.RepositoryAddr{
-		Org:  vcs.OrganizationAddr(module.Namespace),
-		Name: "terraform-" + module.TargetSystem + "-" + module.Name,
+// moduleTags returns the tags that are valid module versions indexed by their normalized version number. The
+// returned tags keep their full name including the tag prefix of the source.
+func moduleTags(source module.Source, tags []vcs.Version) map[module.VersionNumber]vcs.Version {
+	result := make(map[module.VersionNumber]vcs.Version, len(tags))
+	for _, tag := range tags {
+		ver, ok := source.VersionFromTag(tag.VersionNumber)
+		if !ok {
+			continue
+		}
+		result[ver.Normalize()] = tag
+	}
+	return result
+}
+
+func overlaps(versions module.VersionList, tags map[module.VersionNumber]vcs.Version) bool {
+	for _, ver := range versions {
+		if _, ok := tags[ver.Version.Normalize()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// missesNewCommits returns true if any of the tags is not published yet and has no commit.
+func missesNewCommits(versions module.VersionList, tags map[module.VersionNumber]vcs.Version) bool {
+	for key, tag := range tags {
+		if tag.Commit == "" && !slices.ContainsFunc(versions, func(ver module.Version) bool {
+			return ver.Version.Normalize() == key
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// backfillModuleVersion fills in the tag, commit and creation time for versions published before they were recorded.
+func backfillModuleVersion(ver module.Version, tag vcs.Version) module.Version {
+	if ver.Tag == "" {
+		ver.Tag = tag.VersionNumber
+	}
+	if ver.Commit == "" {
+		ver.Commit = tag.Commit
 	}
+	if ver.Created == nil {
+		ver.Created = types.OptionalTime(tag.Created)
+	}
+	return ver
+}
+
+func diffModuleVersions(previous module.VersionList, current module.VersionList) (added []module.VersionNumber, removed []module.VersionNumber) {
+	previousSet := map[module.VersionNumber]struct{}{}
+	for _, ver := range previous {
+		previousSet[ver.Version.Normalize()] = struct{}{}
+	}
+	currentSet := map[module.VersionNumber]struct{}{}
+	for _, ver := range current {
+		currentSet[ver.Version.Normalize()] = struct{}{}
+		if _, ok := previousSet[ver.Version.Normalize()]; !ok {
+			added = append(added, ver.Version)
+		}
+	}
+	for _, ver := range previous {
+		if _, ok := currentSet[ver.Version.Normalize()]; !ok {
+			removed = append(removed, ver.Version)
+		}
+	}
+	return added, removed
+}
+
+// storeModuleVersionDetails checks out a module version and stores the documentation extracted from it.
+func (m api) storeModuleVersionDetails(ctx context.Context, dataAPI metadata.ModuleDataAPI, moduleAddr module.Addr, source module.Source, repo vcs.RepositoryAddr, version module.Version) error {
+	workingCopy, err := m.vcsClient.Checkout(ctx, repo, source.VersionTag(version))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = workingCopy.Close()
+	}()
+	var fsys fs.FS = workingCopy
+	if source.Subdirectory != "" {
+		if fsys, err = fs.Sub(workingCopy, source.Subdirectory); err != nil {
+			return err
+		}
+	}
+	details, err := moduledetails.Extract(fsys)
+	if err != nil {
+		return fmt.Errorf("failed to extract the details of version %s (%w)", version.Version, err)
+	}
+	return dataAPI.PutModuleVersionDetails(ctx, moduleAddr, version.Version, details)
+}
+
+func (m api) getModuleRepo(moduleAddr module.Addr, moduleMetadata module.Metadata) (vcs.RepositoryAddr, error) {
+	if moduleMetadata.CustomRepository != "" {
+		return m.vcsClient.ParseRepositoryAddr(moduleMetadata.CustomRepository)
+	}
+	return vcs.RepositoryAddr{
+		Org:  vcs.OrganizationAddr(moduleAddr.Namespace),
+		Name: "terraform-" + moduleAddr.TargetSystem + "-" + moduleAddr.Name,
+	}, nil
 }
@@ -0,0 +1,146 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opentofu/libregistry/types/module"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultBulkUpdateConcurrency is the default number of modules updated in parallel by UpdateModules and
+// UpdateAllModules.
+const DefaultBulkUpdateConcurrency = 4
+
+// BulkUpdateOpt is a function that modifies the bulk update configuration.
+type BulkUpdateOpt func(config *BulkUpdateConfig) error
+
+// BulkUpdateConfig holds the configuration for UpdateModules and UpdateAllModules.
+type BulkUpdateConfig struct {
+	// Concurrency is the maximum number of modules updated in parallel. Defaults to DefaultBulkUpdateConcurrency.
+	Concurrency int
+}
+
+// ApplyDefaults adds the default values if none are present.
+func (c *BulkUpdateConfig) ApplyDefaults() {
+	if c.Concurrency == 0 {
+		c.Concurrency = DefaultBulkUpdateConcurrency
+	}
+}
+
+// WithConcurrency sets the maximum number of modules updated in parallel. Keep in mind that each update queries the
+// VCS, so a high number may run into rate limits.
+func WithConcurrency(concurrency int) BulkUpdateOpt {
+	return func(config *BulkUpdateConfig) error {
+		if concurrency < 1 {
+			return fmt.Errorf("invalid concurrency: %d", concurrency)
+		}
+		config.Concurrency = concurrency
+		return nil
+	}
+}
+
+// ModuleUpdateReport describes the outcome of a bulk module update.
+type ModuleUpdateReport struct {
+	// Results holds one entry per module in the order the modules were passed in.
+	Results []ModuleUpdateResult
+}
+
+// Failed returns the results of the modules that could not be updated.
+func (r ModuleUpdateReport) Failed() []ModuleUpdateResult {
+	var result []ModuleUpdateResult
+	for _, moduleResult := range r.Results {
+		if moduleResult.Err != nil {
+			result = append(result, moduleResult)
+		}
+	}
+	return result
+}
+
+// ModuleUpdateResult describes the outcome of updating a single module.
+type ModuleUpdateResult struct {
+	Module module.Addr
+	// AddedVersions holds the versions that were not present in the registry before the update.
+	AddedVersions []module.VersionNumber
+	// RemovedVersions holds the versions that were present in the registry before the update, but no longer are.
+	RemovedVersions []module.VersionNumber
+	// TagChanges holds the published versions whose tag was deleted or moved in the module repository.
+	TagChanges []TagChange
+	// Fork holds the decision of the fork policy recorded when the module was added, if the module repository is a
+	// fork and the policy reported on it. The policy is not evaluated again on update.
+	Fork *ForkDecision
+	// Err holds the error if the update failed. The versions are empty in this case.
+	Err error
+}
+
+// TagChange describes a published module version whose tag was deleted or moved in the module repository.
+type TagChange struct {
+	Version module.VersionNumber
+	// PreviousCommit is the commit recorded for the version in the registry. It may be empty for versions published
+	// before commits were recorded.
+	PreviousCommit string
+	// Commit is the commit the tag points to now. It is empty if the tag was deleted.
+	Commit string
+	// Action is the policy that was applied to the version.
+	Action TagChangePolicy
+}
+
+// Deleted returns true if the tag no longer exists in the module repository.
+func (c TagChange) Deleted() bool {
+	return c.Commit == ""
+}
+
+// Reason returns a human-readable description of the change, which is also used as the reason for yanking.
+func (c TagChange) Reason() string {
+	if c.Deleted() {
+		return "The tag " + string(c.Version) + " was deleted from the module repository."
+	}
+	return "The tag " + string(c.Version) + " was moved from commit " + c.PreviousCommit + " to " + c.Commit + "."
+}
+
+func (m api) UpdateModules(ctx context.Context, moduleAddrs []module.Addr, opts ...BulkUpdateOpt) (ModuleUpdateReport, error) {
+	cfg := BulkUpdateConfig{}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return ModuleUpdateReport{}, err
+		}
+	}
+	cfg.ApplyDefaults()
+
+	report := ModuleUpdateReport{
+		Results: make([]ModuleUpdateResult, len(moduleAddrs)),
+	}
+	g := &errgroup.Group{}
+	g.SetLimit(cfg.Concurrency)
+	for i, moduleAddr := range moduleAddrs {
+		i, moduleAddr := i, moduleAddr
+		g.Go(func() error {
+			// Each goroutine only writes its own entry, so no locking is needed.
+			result := &report.Results[i]
+			result.Module = moduleAddr
+			if err := ctx.Err(); err != nil {
+				result.Err = &ModuleUpdateFailedError{
+					moduleAddr,
+					err,
+				}
+				return nil
+			}
+			*result, result.Err = m.updateModule(ctx, moduleAddr)
+			// Individual failures are recorded in the report and do not stop the other updates.
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return report, ctx.Err()
+}
+
+func (m api) UpdateAllModules(ctx context.Context, opts ...BulkUpdateOpt) (ModuleUpdateReport, error) {
+	moduleAddrs, err := m.dataAPI.ListModules(ctx)
+	if err != nil {
+		return ModuleUpdateReport{}, fmt.Errorf("failed to list modules (%w)", err)
+	}
+	return m.UpdateModules(ctx, moduleAddrs, opts...)
+}
@@ -0,0 +1,158 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/opentofu/libregistry"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+)
+
+func TestUpdateModulesContinuesPastFailures(t *testing.T) {
+	ctx := context.Background()
+	inMemoryVCS := fakevcs.New()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := inMemoryVCS.CreateOrganization("test"); err != nil {
+		t.Fatal(err)
+	}
+	var moduleAddrs []module.Addr
+	for i := 0; i < 5; i++ {
+		moduleAddr := module.Addr{
+			Namespace:    "test",
+			Name:         "module" + strconv.Itoa(i),
+			TargetSystem: "aws",
+		}
+		repo := vcs.RepositoryAddr{
+			Org:  "test",
+			Name: "terraform-aws-" + moduleAddr.Name,
+		}
+		if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+			t.Fatal(err)
+		}
+		moduleAddrs = append(moduleAddrs, moduleAddr)
+	}
+	missingModule := module.Addr{
+		Namespace:    "test",
+		Name:         "missing",
+		TargetSystem: "aws",
+	}
+	moduleAddrs = slices.Insert(moduleAddrs, 2, missingModule)
+
+	report, err := registry.UpdateModules(ctx, moduleAddrs, libregistry.WithConcurrency(2))
+	if err != nil {
+		t.Fatalf("❌ Bulk update failed (%v)", err)
+	}
+	if len(report.Results) != len(moduleAddrs) {
+		t.Fatalf("❌ Incorrect number of results: %d", len(report.Results))
+	}
+	for i, result := range report.Results {
+		if result.Module != moduleAddrs[i] {
+			t.Fatalf("❌ Incorrect module in position %d: %s", i, result.Module)
+		}
+		if result.Module == missingModule {
+			var notFound *vcs.RepositoryNotFoundError
+			if !errors.As(result.Err, &notFound) {
+				t.Fatalf("❌ Expected a repository not found error for %s, got: %v", result.Module, result.Err)
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Fatalf("❌ Failed to update %s (%v)", result.Module, result.Err)
+		}
+		if !slices.Equal(result.AddedVersions, []module.VersionNumber{"v1.0.0"}) {
+			t.Fatalf("❌ Incorrect added versions for %s: %v", result.Module, result.AddedVersions)
+		}
+		if _, err := dataAPI.GetModule(ctx, result.Module); err != nil {
+			t.Fatalf("❌ Module %s was not stored (%v)", result.Module, err)
+		}
+	}
+	if failed := report.Failed(); len(failed) != 1 || failed[0].Module != missingModule {
+		t.Fatalf("❌ Incorrect failed modules: %v", failed)
+	}
+	t.Logf("✅ All modules except the missing one were updated.")
+}
+
+func TestUpdateAllModulesReportsChanges(t *testing.T) {
+	ctx := context.Background()
+	inMemoryVCS := fakevcs.New()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI, libregistry.WithTagChangePolicy(libregistry.TagChangePolicyRemove))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "vpc",
+		TargetSystem: "aws",
+	}
+	repo := vcs.RepositoryAddr{
+		Org:  "test",
+		Name: "terraform-aws-vpc",
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i <= 5; i++ {
+		if err := inMemoryVCS.CreateVersion(repo, vcs.VersionNumber("v1.0."+strconv.Itoa(i)), os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Logf("⚙️ Storing a version that no longer exists in the repository...")
+	if err := dataAPI.PutModule(ctx, moduleAddr, module.Metadata{
+		Versions: module.VersionList{
+			{Version: "v0.9.0"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := registry.UpdateAllModules(ctx)
+	if err != nil {
+		t.Fatalf("❌ Bulk update failed (%v)", err)
+	}
+	if len(report.Results) != 1 {
+		t.Fatalf("❌ Incorrect number of results: %d", len(report.Results))
+	}
+	result := report.Results[0]
+	if result.Err != nil {
+		t.Fatalf("❌ Failed to update %s (%v)", result.Module, result.Err)
+	}
+	if len(result.AddedVersions) != 6 {
+		t.Fatalf("❌ Incorrect added versions: %v", result.AddedVersions)
+	}
+	if !slices.Equal(result.RemovedVersions, []module.VersionNumber{"v0.9.0"}) {
+		t.Fatalf("❌ Incorrect removed versions: %v", result.RemovedVersions)
+	}
+	t.Logf("✅ The report contains the added and removed versions.")
+}
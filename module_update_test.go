@@ -5,10 +5,13 @@ package libregistry_test
 
 import (
 	"context"
+	"errors"
 	"io/fs"
 	"os"
+	"slices"
 	"strconv"
 	"testing"
+	"testing/fstest"
 
 	"github.com/opentofu/libregistry"
 	"github.com/opentofu/libregistry/metadata"
@@ -58,7 +61,7 @@ func TestUpdateModuleBackfill(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+	if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", testModuleContents(".")); err != nil {
 		t.Fatal(err)
 	}
 
@@ -93,3 +96,369 @@ func TestUpdateModuleBackfill(t *testing.T) {
 		j++
 	}
 }
+
+func TestUpdateModuleTagChanges(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "vpc",
+		TargetSystem: "aws",
+	}
+	repo := vcs.RepositoryAddr{
+		Org:  "test",
+		Name: "terraform-aws-vpc",
+	}
+
+	for _, tc := range []struct {
+		policy           libregistry.TagChangePolicy
+		expectedVersions []module.VersionNumber
+		expectedChanges  int
+		expectedYanked   []module.VersionNumber
+	}{
+		{
+			// The deleted tag is not detected because the latest tags overlap with the published versions.
+			policy:           libregistry.TagChangePolicyKeep,
+			expectedVersions: []module.VersionNumber{"v1.2.0", "v1.1.0", "v1.0.0"},
+			expectedChanges:  1,
+		},
+		{
+			policy:           libregistry.TagChangePolicyRemove,
+			expectedVersions: []module.VersionNumber{"v1.2.0"},
+			expectedChanges:  2,
+		},
+		{
+			policy:           libregistry.TagChangePolicyYank,
+			expectedVersions: []module.VersionNumber{"v1.2.0", "v1.1.0", "v1.0.0"},
+			expectedChanges:  2,
+			expectedYanked:   []module.VersionNumber{"v1.1.0", "v1.0.0"},
+		},
+	} {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			ctx := context.Background()
+			inMemoryVCS := fakevcs.New()
+			dataAPI, err := metadata.New(memory.New())
+			if err != nil {
+				t.Fatal(err)
+			}
+			registry, err := libregistry.New(inMemoryVCS, dataAPI, libregistry.WithTagChangePolicy(tc.policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+				t.Fatal(err)
+			}
+			if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+				t.Fatal(err)
+			}
+			for _, ver := range []vcs.VersionNumber{"v1.0.0", "v1.1.0", "v1.2.0"} {
+				if err := inMemoryVCS.CreateVersion(repo, ver, testModuleContents(".")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := registry.AddModule(ctx, repo.String()); err != nil {
+				t.Fatal(err)
+			}
+			published, err := dataAPI.GetModule(ctx, moduleAddr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, ver := range published.Versions {
+				if ver.Commit == "" {
+					t.Fatalf("❌ No commit recorded for version %s.", ver.Version)
+				}
+			}
+
+			t.Logf("⚙️ Deleting v1.0.0 and moving v1.1.0...")
+			if err := inMemoryVCS.DeleteVersion(repo, "v1.0.0"); err != nil {
+				t.Fatal(err)
+			}
+			if err := inMemoryVCS.MoveVersion(repo, "v1.1.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+				t.Fatal(err)
+			}
+			movedTag, err := inMemoryVCS.GetTagVersion(ctx, repo, "v1.1.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := registry.UpdateModules(ctx, []module.Addr{moduleAddr})
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := report.Results[0]
+			if result.Err != nil {
+				t.Fatalf("❌ Failed to update the module (%v)", result.Err)
+			}
+			if len(result.TagChanges) != tc.expectedChanges {
+				t.Fatalf("❌ Incorrect tag changes reported: %v", result.TagChanges)
+			}
+			for _, change := range result.TagChanges {
+				if change.Action != tc.policy {
+					t.Fatalf("❌ Incorrect action for %s: %s", change.Version, change.Action)
+				}
+				if change.Version == "v1.1.0" && change.Commit != movedTag.Commit {
+					t.Fatalf("❌ Incorrect new commit for %s: %s", change.Version, change.Commit)
+				}
+			}
+
+			stored, err := dataAPI.GetModule(ctx, moduleAddr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var versions []module.VersionNumber
+			var yanked []module.VersionNumber
+			for _, ver := range stored.Versions {
+				versions = append(versions, ver.Version)
+				if ver.Yanked {
+					if ver.YankReason == "" {
+						t.Fatalf("❌ No yank reason for version %s.", ver.Version)
+					}
+					yanked = append(yanked, ver.Version)
+				}
+				if ver.Version == "v1.1.0" {
+					expectedCommit := published.Versions[1].Commit
+					if tc.policy == libregistry.TagChangePolicyKeep {
+						expectedCommit = movedTag.Commit
+					}
+					if ver.Commit != expectedCommit {
+						t.Fatalf("❌ Incorrect commit stored for %s: %s (expected: %s)", ver.Version, ver.Commit, expectedCommit)
+					}
+				}
+			}
+			if !slices.Equal(versions, tc.expectedVersions) {
+				t.Fatalf("❌ Incorrect versions stored: %v", versions)
+			}
+			if !slices.Equal(yanked, tc.expectedYanked) {
+				t.Fatalf("❌ Incorrect yanked versions: %v", yanked)
+			}
+			if tc.policy == libregistry.TagChangePolicyRemove && len(stored.RemovedVersions) != 2 {
+				t.Fatalf("❌ The removed versions were not recorded: %v", stored.RemovedVersions)
+			}
+			t.Logf("✅ The tag changes were handled according to the %s policy.", tc.policy)
+
+			t.Logf("⚙️ Updating the module again...")
+			report, err = registry.UpdateModules(ctx, []module.Addr{moduleAddr})
+			if err != nil {
+				t.Fatal(err)
+			}
+			result = report.Results[0]
+			if result.Err != nil {
+				t.Fatalf("❌ Failed to update the module again (%v)", result.Err)
+			}
+			if len(result.TagChanges) != 0 || len(result.AddedVersions) != 0 || len(result.RemovedVersions) != 0 {
+				t.Fatalf("❌ The second update changed the module again: %v", result)
+			}
+			updated, err := dataAPI.GetModule(ctx, moduleAddr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !updated.Equals(stored) {
+				t.Fatalf("❌ The second update changed the stored module: %v", updated)
+			}
+			t.Logf("✅ The tag changes were only handled once.")
+		})
+	}
+}
+
+// TestUpdateModuleBackfillsCommits tests that metadata files written before the commit and creation time were
+// recorded can still be read and are filled in on the next update.
+func TestUpdateModuleBackfillsCommits(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "vpc",
+		TargetSystem: "aws",
+	}
+	repo := vcs.RepositoryAddr{
+		Org:  "test",
+		Name: "terraform-aws-vpc",
+	}
+
+	ctx := context.Background()
+	inMemoryVCS := fakevcs.New()
+	storage := memory.New()
+	dataAPI, err := metadata.New(storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, ver := range []vcs.VersionNumber{"v1.0.0", "v1.1.0"} {
+		if err := inMemoryVCS.CreateVersion(repo, ver, os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Logf("⚙️ Writing a metadata file in the old format...")
+	if err := storage.PutFile(ctx, "modules/t/test/vpc/aws.json", []byte(`{"versions":[{"version":"v1.0.0"}]}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := registry.UpdateModule(ctx, moduleAddr); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := dataAPI.GetModule(ctx, moduleAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Versions) != 2 {
+		t.Fatalf("❌ Incorrect number of versions: %d", len(stored.Versions))
+	}
+	for _, ver := range stored.Versions {
+		tag, err := inMemoryVCS.GetTagVersion(ctx, repo, vcs.VersionNumber(ver.Version))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ver.Commit != tag.Commit {
+			t.Fatalf("❌ Incorrect commit for version %s: %s (expected: %s)", ver.Version, ver.Commit, tag.Commit)
+		}
+		if ver.Created == nil || !ver.Created.Equal(tag.Created) {
+			t.Fatalf("❌ Incorrect creation time for version %s: %s (expected: %s)", ver.Version, ver.Created, tag.Created)
+		}
+	}
+	t.Logf("✅ The commit and creation time were filled in for all versions.")
+}
+
+// TestUpdateModuleKeepsVanishedVersions tests that the default tag change policy keeps published versions when the
+// full tag list is fetched and no longer contains them, and that new versions are stored exactly as tagged.
+func TestUpdateModuleKeepsVanishedVersions(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "vpc",
+		TargetSystem: "aws",
+	}
+	repo := vcs.RepositoryAddr{
+		Org:  "test",
+		Name: "terraform-aws-vpc",
+	}
+
+	ctx := context.Background()
+	inMemoryVCS := fakevcs.New()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateVersion(repo, "1.0.0", testModuleContents(".")); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("⚙️ Storing a version that is not in the repository...")
+	if err := dataAPI.PutModule(ctx, moduleAddr, module.Metadata{
+		Versions: module.VersionList{
+			{
+				Version: "v0.9.0",
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := registry.UpdateModules(ctx, []module.Addr{moduleAddr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := dataAPI.GetModule(ctx, moduleAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Versions) != 2 || stored.Versions[0].Version != "1.0.0" || stored.Versions[1].Version != "v0.9.0" {
+		t.Fatalf("❌ Incorrect versions stored: %v", stored.Versions)
+	}
+	if len(report.Results) != 1 || len(report.Results[0].TagChanges) != 1 || !report.Results[0].TagChanges[0].Deleted() {
+		t.Fatalf("❌ The missing tag was not reported: %v", report.Results)
+	}
+	t.Logf("✅ The vanished version was kept and the new version was stored as tagged.")
+}
+
+func TestUpdateModuleExtractsDetails(t *testing.T) {
+	ctx := context.Background()
+	inMemoryVCS := fakevcs.New()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI, libregistry.WithModuleDetails())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "vpc",
+		TargetSystem: "aws",
+	}
+	repo := vcs.RepositoryAddr{
+		Org:  "test",
+		Name: "terraform-aws-vpc",
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateVersion(repo, "1.0.0", fstest.MapFS{
+		"README.md": {Data: []byte("# VPC\n")},
+		"main.tf": {Data: []byte(`
+variable "cidr" {
+  type = string
+}
+
+output "id" {
+  value = "vpc-1"
+}
+`)},
+		"modules/subnet/main.tf": {Data: []byte(`
+variable "vpc_id" {
+  type = string
+}
+`)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("⚙️ Updating module with details extraction enabled...")
+	if err := registry.UpdateModule(ctx, moduleAddr); err != nil {
+		t.Fatalf("❌ Failed to update module (%v)", err)
+	}
+	details, err := dataAPI.GetModuleVersionDetails(ctx, moduleAddr, "v1.0.0")
+	if err != nil {
+		t.Fatalf("❌ Failed to fetch module version details (%v)", err)
+	}
+	if details.Root.README != "# VPC\n" {
+		t.Fatalf("❌ Incorrect README: %q", details.Root.README)
+	}
+	if len(details.Root.Variables) != 1 || details.Root.Variables[0].Name != "cidr" || !details.Root.Variables[0].Required {
+		t.Fatalf("❌ Incorrect variables: %v", details.Root.Variables)
+	}
+	if len(details.Root.Outputs) != 1 || details.Root.Outputs[0].Name != "id" {
+		t.Fatalf("❌ Incorrect outputs: %v", details.Root.Outputs)
+	}
+	if len(details.Submodules) != 1 || details.Submodules[0].Path != "modules/subnet" {
+		t.Fatalf("❌ Incorrect submodules: %v", details.Submodules)
+	}
+	t.Logf("✅ The module version details were stored.")
+
+	_, err = dataAPI.GetModuleVersionDetails(ctx, moduleAddr, "v2.0.0")
+	var notFound *metadata.ModuleVersionDetailsNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("❌ Fetching the details of a missing version did not return the correct error (%v)", err)
+	}
+	t.Logf("✅ Missing version details return a not found error.")
+}
@@ -0,0 +1,146 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// validateModule checks that the repository of a submitted module holds a real module at its latest version. It
+// returns a *ModuleValidationFailedError listing every failed check, or any other error if the checks could not be
+// performed.
+func (m api) validateModule(ctx context.Context, moduleAddr module.Addr, source module.Source) error {
+	repo, err := m.getModuleRepo(moduleAddr, module.Metadata{Source: source})
+	if err != nil {
+		return err
+	}
+	validationErr := &ModuleValidationFailedError{
+		Module: moduleAddr,
+	}
+
+	repoInfo, err := m.vcsClient.GetRepositoryInfo(ctx, repo)
+	if err != nil {
+		return err
+	}
+	if repoInfo.ForkOf != nil && repoInfo.Archived {
+		validationErr.Failures = append(validationErr.Failures, ModuleValidationFailure{
+			Check:   ModuleValidationCheckArchivedFork,
+			Message: "the repository " + repo.String() + " is an archived fork of " + repoInfo.ForkOf.String(),
+		})
+	}
+
+	tags, err := m.vcsClient.ListAllTags(ctx, repo)
+	if err != nil {
+		return err
+	}
+	var latestTag vcs.VersionNumber
+	for _, tag := range tags {
+		ver, ok := source.VersionFromTag(tag.VersionNumber)
+		if !ok {
+			continue
+		}
+		if validationErr.Version == "" || ver.Compare(validationErr.Version) > 0 {
+			validationErr.Version = ver
+			latestTag = tag.VersionNumber
+		}
+	}
+	if latestTag == "" {
+		validationErr.Failures = append(validationErr.Failures, ModuleValidationFailure{
+			Check:   ModuleValidationCheckVersion,
+			Message: "the repository " + repo.String() + " has no tags that are valid module versions",
+		})
+	} else {
+		failures, err := m.validateModuleContents(ctx, repo, latestTag, source.Subdirectory)
+		if err != nil {
+			return err
+		}
+		validationErr.Failures = append(validationErr.Failures, failures...)
+	}
+
+	if len(validationErr.Failures) != 0 {
+		return validationErr
+	}
+	return nil
+}
+
+// validateModuleContents checks out a module version and checks the configuration files in the module root.
+func (m api) validateModuleContents(ctx context.Context, repo vcs.RepositoryAddr, tag vcs.VersionNumber, subdirectory string) ([]ModuleValidationFailure, error) {
+	workingCopy, err := m.vcsClient.Checkout(ctx, repo, tag)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = workingCopy.Close()
+	}()
+	root := "."
+	if subdirectory != "" {
+		root = subdirectory
+	}
+	entries, err := fs.ReadDir(workingCopy, root)
+	if err != nil {
+		return []ModuleValidationFailure{
+			{
+				Check:   ModuleValidationCheckConfigurationFiles,
+				Message: "the module directory " + root + " does not exist at " + string(tag),
+			},
+		}, nil
+	}
+
+	var failures []ModuleValidationFailure
+	configurationFiles := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		parse := configurationFileParser(name)
+		if parse == nil {
+			continue
+		}
+		configurationFiles++
+		file := path.Join(root, name)
+		contents, err := fs.ReadFile(workingCopy, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s (%w)", file, err)
+		}
+		if _, diags := parse(contents, file); diags.HasErrors() {
+			failures = append(failures, ModuleValidationFailure{
+				Check:   ModuleValidationCheckSyntax,
+				Message: diags.Error(),
+			})
+		}
+	}
+	if configurationFiles == 0 {
+		failures = append(failures, ModuleValidationFailure{
+			Check:   ModuleValidationCheckConfigurationFiles,
+			Message: "the module root contains no .tf or .tofu files at " + string(tag),
+		})
+	}
+	return failures, nil
+}
+
+// configurationFileParser returns the parser for an OpenTofu configuration file, or nil if the file is not a
+// configuration file.
+func configurationFileParser(name string) func(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
+	switch {
+	case strings.HasSuffix(name, ".tf"), strings.HasSuffix(name, ".tofu"):
+		return func(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
+			return hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+		}
+	case strings.HasSuffix(name, ".tf.json"), strings.HasSuffix(name, ".tofu.json"):
+		return hcljson.Parse
+	default:
+		return nil
+	}
+}
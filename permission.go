@@ -0,0 +1,30 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"context"
+
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// checkPermission returns a *PermissionDeniedError if the user may not act on behalf of the organization, or a
+// *PermissionCheckFailedError if the VCS could not be asked.
+func (m api) checkPermission(ctx context.Context, username vcs.Username, organization vcs.OrganizationAddr) error {
+	allowed, err := m.vcsClient.HasPermission(ctx, username, organization)
+	if err != nil {
+		return &PermissionCheckFailedError{
+			Username:     username,
+			Organization: organization,
+			Cause:        err,
+		}
+	}
+	if !allowed {
+		return &PermissionDeniedError{
+			Username:     username,
+			Organization: organization,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package protocol
+
+import (
+	"context"
+	"strings"
+
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// ModuleVersionsResponse is the response of the /v1/modules/{namespace}/{name}/{system}/versions endpoint.
+type ModuleVersionsResponse struct {
+	Modules []ModuleVersions `json:"modules"`
+}
+
+// ModuleVersions lists the versions of a single module.
+type ModuleVersions struct {
+	Versions []ModuleVersion `json:"versions"`
+}
+
+// ModuleVersion is a single module version. The version number is returned without the "v" prefix.
+type ModuleVersion struct {
+	Version string `json:"version"`
+}
+
+// ModuleDownloadResponse is the JSON form of the module download response. The protocol returns the location in the
+// X-Terraform-Get header, but static registries that cannot set headers serve this body instead.
+type ModuleDownloadResponse struct {
+	Location string `json:"location"`
+}
+
+// NewModuleVersionsResponse builds the version list response from the module metadata. Yanked versions are left out
+// so they are no longer selected by version constraints, but they can still be downloaded when requested explicitly.
+func NewModuleVersionsResponse(metadata module.Metadata) ModuleVersionsResponse {
+	available := metadata.Versions.WithoutYanked()
+	versions := make([]ModuleVersion, 0, len(available))
+	for _, ver := range available {
+		versions = append(versions, ModuleVersion{
+			Version: strings.TrimPrefix(string(ver.Version), "v"),
+		})
+	}
+	return ModuleVersionsResponse{
+		Modules: []ModuleVersions{
+			{
+				Versions: versions,
+			},
+		},
+	}
+}
+
+// FindModuleVersion returns the stored version matching the requested version number, which may or may not have a
+// "v" prefix.
+func FindModuleVersion(metadata module.Metadata, version module.VersionNumber) (module.Version, bool) {
+	for _, ver := range metadata.Versions {
+		if ver.Version.Normalize() == version.Normalize() {
+			return ver, true
+		}
+	}
+	return module.Version{}, false
+}
+
+// ModuleSourceFunc returns the source address a module version should be downloaded from. The metadata holds the
+// custom repository, tag prefix and subdirectory of modules stored in a shared repository.
+type ModuleSourceFunc func(ctx context.Context, moduleAddr module.Addr, metadata module.Metadata, version module.Version) (string, error)
+
+// GitHubModuleSource returns a git source address pointing to the repository of the module on github.com at the tag
+// of the version. For modules in a shared repository, the address is of the form repo//subdir?ref=prefix/tag. Use it
+// for registries whose modules are all hosted on github.com, such as the OpenTofu registry. Registries using a
+// different VCS or GitHub Enterprise should use VCSModuleSource instead.
+func GitHubModuleSource(_ context.Context, moduleAddr module.Addr, metadata module.Metadata, version module.Version) (string, error) {
+	repository := moduleAddr.ToRepositoryAddr().String()
+	if metadata.CustomRepository != "" {
+		repository = metadata.CustomRepository
+	}
+	return gitModuleSource("https://github.com/"+repository, metadata, version), nil
+}
+
+// VCSModuleSource returns a ModuleSourceFunc that points to the repository address reported by the VCS client, so
+// modules hosted on GitLab, Gitea or other systems are downloaded from the right host. The VCS client must support
+// web access, otherwise the returned function fails with a *vcs.NoWebAccessError.
+func VCSModuleSource(client vcs.Client) ModuleSourceFunc {
+	return func(ctx context.Context, moduleAddr module.Addr, metadata module.Metadata, version module.Version) (string, error) {
+		repository := moduleAddr.ToRepositoryAddr()
+		if metadata.CustomRepository != "" {
+			var err error
+			repository, err = client.ParseRepositoryAddr(metadata.CustomRepository)
+			if err != nil {
+				return "", err
+			}
+		}
+		repositoryURL, err := client.GetRepositoryBrowseURL(ctx, repository)
+		if err != nil {
+			return "", err
+		}
+		return gitModuleSource(repositoryURL, metadata, version), nil
+	}
+}
+
+func gitModuleSource(repositoryURL string, metadata module.Metadata, version module.Version) string {
+	subdirectory := ""
+	if metadata.Subdirectory != "" {
+		subdirectory = "//" + metadata.Subdirectory
+	}
+	return "git::" + repositoryURL + subdirectory + "?ref=" + string(metadata.VersionTag(version))
+}
@@ -0,0 +1,34 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package protocol contains the response types of the Module and Provider Registry Protocols, as well as functions
+// to build them from the stored registry metadata.
+package protocol
+
+// ServiceDiscoveryPath is the path of the service discovery document.
+const ServiceDiscoveryPath = "/.well-known/terraform.json"
+
+// ModulesV1Path is the base path of the Module Registry Protocol endpoints.
+const ModulesV1Path = "/v1/modules/"
+
+// ProvidersV1Path is the base path of the Provider Registry Protocol endpoints.
+const ProvidersV1Path = "/v1/providers/"
+
+// ServiceDiscoveryResponse is the document served at ServiceDiscoveryPath.
+type ServiceDiscoveryResponse struct {
+	ModulesV1   string `json:"modules.v1"`
+	ProvidersV1 string `json:"providers.v1"`
+}
+
+// ErrorResponse is the body returned for failed requests.
+type ErrorResponse struct {
+	Errors []string `json:"errors"`
+}
+
+// NewServiceDiscoveryResponse returns the service discovery document with the default paths.
+func NewServiceDiscoveryResponse() ServiceDiscoveryResponse {
+	return ServiceDiscoveryResponse{
+		ModulesV1:   ModulesV1Path,
+		ProvidersV1: ProvidersV1Path,
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package protocol
+
+import (
+	"strings"
+
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+// ProviderVersionsResponse is the response of the /v1/providers/{namespace}/{type}/versions endpoint.
+type ProviderVersionsResponse struct {
+	Versions []ProviderVersion `json:"versions"`
+	// Warnings are shown to users when installing the provider. They include the deprecation notice of the provider.
+	// The notices of deprecated versions are not included, as they would be shown for every version.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ProviderVersion is a single provider version in the version list. The version number is returned without the "v"
+// prefix.
+type ProviderVersion struct {
+	Version   string             `json:"version"`
+	Protocols []string           `json:"protocols"`
+	Platforms []ProviderPlatform `json:"platforms"`
+}
+
+// ProviderPlatform is a single target platform of a provider version.
+type ProviderPlatform struct {
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+}
+
+// ProviderDownloadResponse is the response of the
+// /v1/providers/{namespace}/{type}/{version}/download/{os}/{arch} endpoint.
+type ProviderDownloadResponse struct {
+	Protocols           []string    `json:"protocols"`
+	OS                  string      `json:"os"`
+	Arch                string      `json:"arch"`
+	Filename            string      `json:"filename"`
+	DownloadURL         string      `json:"download_url"`
+	SHASumsURL          string      `json:"shasums_url"`
+	SHASumsSignatureURL string      `json:"shasums_signature_url"`
+	SHASum              string      `json:"shasum"`
+	SigningKeys         SigningKeys `json:"signing_keys"`
+}
+
+// SigningKeys holds the keys the SHA256SUMS file of a provider version may be signed with.
+type SigningKeys struct {
+	GPGPublicKeys []GPGPublicKey `json:"gpg_public_keys"`
+}
+
+// GPGPublicKey is a single signing key.
+type GPGPublicKey struct {
+	KeyID      string `json:"key_id"`
+	ASCIIArmor string `json:"ascii_armor"`
+}
+
+// NewProviderVersionsResponse builds the version list response from the provider metadata.
+func NewProviderVersionsResponse(metadata provider.Metadata) ProviderVersionsResponse {
+	versions := make([]ProviderVersion, len(metadata.Versions))
+	for i, ver := range metadata.Versions {
+		platforms := make([]ProviderPlatform, len(ver.Targets))
+		for j, target := range ver.Targets {
+			platforms[j] = ProviderPlatform{
+				OS:   target.OS,
+				Arch: target.Arch,
+			}
+		}
+		versions[i] = ProviderVersion{
+			Version:   strings.TrimPrefix(string(ver.Version), "v"),
+			Protocols: ver.Protocols,
+			Platforms: platforms,
+		}
+	}
+	return ProviderVersionsResponse{
+		Versions: versions,
+		Warnings: metadata.ProviderWarnings(),
+	}
+}
+
+// FindProviderTarget returns the version and target matching the requested version number, operating system and
+// architecture.
+func FindProviderTarget(
+	metadata provider.Metadata,
+	version provider.VersionNumber,
+	os string,
+	arch string,
+) (provider.Version, provider.Target, bool) {
+	for _, ver := range metadata.Versions {
+		if ver.Version.Normalize() != version.Normalize() {
+			continue
+		}
+		for _, target := range ver.Targets {
+			if target.OS == os && target.Arch == arch {
+				return ver, target, true
+			}
+		}
+	}
+	return provider.Version{}, provider.Target{}, false
+}
+
+// NewProviderDownloadResponse builds the download response for a single target of a provider version.
+func NewProviderDownloadResponse(version provider.Version, target provider.Target, keys []provider.Key) ProviderDownloadResponse {
+	gpgKeys := make([]GPGPublicKey, len(keys))
+	for i, key := range keys {
+		gpgKeys[i] = GPGPublicKey{
+			KeyID:      key.KeyID,
+			ASCIIArmor: key.ASCIIArmor,
+		}
+	}
+	return ProviderDownloadResponse{
+		Protocols:           version.Protocols,
+		OS:                  target.OS,
+		Arch:                target.Arch,
+		Filename:            target.Filename,
+		DownloadURL:         target.DownloadURL,
+		SHASumsURL:          version.SHASumsURL,
+		SHASumsSignatureURL: version.SHASumsSignatureURL,
+		SHASum:              target.SHASum,
+		SigningKeys: SigningKeys{
+			GPGPublicKeys: gpgKeys,
+		},
+	}
+}
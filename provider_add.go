@@ -0,0 +1,35 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"context"
+
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+func (m api) AddProvider(ctx context.Context, repository string) error {
+	vcsRepository, err := m.vcsClient.ParseRepositoryAddr(repository)
+	if err != nil {
+		return err
+	}
+
+	submitted, err := provider.AddrFromRepository(vcsRepository)
+	if err != nil {
+		return err
+	}
+
+	providers, err := m.dataAPI.ListProviders(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range providers {
+		if p.Equals(submitted) {
+			return &ProviderAlreadyExistsError{submitted}
+		}
+	}
+
+	return m.UpdateProvider(ctx, submitted)
+}
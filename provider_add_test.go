@@ -0,0 +1,268 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/opentofu/libregistry"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/types/provider"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+)
+
+// TestAddProvider tests that a provider, when added from a repository, correctly appears in the metadata storage with
+// the correct version information built from the release assets.
+func TestAddProvider(t *testing.T) {
+	inMemoryVCS := fakevcs.New()
+	storage := memory.New()
+	ctx := context.Background()
+	providerAddr := provider.Addr{
+		Namespace: "test",
+		Name:      "test",
+	}
+
+	dataAPI, err := metadata.New(storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry, err := libregistry.New(
+		inMemoryVCS,
+		dataAPI,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := providerAddr.ToRepositoryAddr()
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	keyRing := createProviderNamespaceKey(t, dataAPI, providerAddr.Namespace)
+	createProviderRelease(t, inMemoryVCS, keyRing, providerAddr, "v1.0.0", []string{"linux_amd64", "darwin_arm64"})
+
+	if err := registry.AddProvider(ctx, repo.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	storedMetadata, err := dataAPI.GetProvider(ctx, providerAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(storedMetadata.Versions) != 1 {
+		t.Fatalf("Incorrect number of versions: %d", len(storedMetadata.Versions))
+	}
+	ver := storedMetadata.Versions[0]
+	if ver.Version != "v1.0.0" {
+		t.Fatalf("Incorrect version stored: %s", ver.Version)
+	}
+	if len(ver.Protocols) != 1 || ver.Protocols[0] != "6.0" {
+		t.Fatalf("Incorrect protocols stored: %v", ver.Protocols)
+	}
+	if !strings.HasSuffix(ver.SHASumsURL, "/terraform-provider-test_1.0.0_SHA256SUMS") {
+		t.Fatalf("Incorrect SHA256SUMS URL: %s", ver.SHASumsURL)
+	}
+	if !strings.HasSuffix(ver.SHASumsSignatureURL, "/terraform-provider-test_1.0.0_SHA256SUMS.sig") {
+		t.Fatalf("Incorrect SHA256SUMS signature URL: %s", ver.SHASumsSignatureURL)
+	}
+	if len(ver.Targets) != 2 {
+		t.Fatalf("Incorrect number of targets: %d", len(ver.Targets))
+	}
+	if ver.Targets[0].OS != "darwin" || ver.Targets[0].Arch != "arm64" {
+		t.Fatalf("Incorrect first target: %s_%s", ver.Targets[0].OS, ver.Targets[0].Arch)
+	}
+	if ver.Targets[1].OS != "linux" || ver.Targets[1].Arch != "amd64" {
+		t.Fatalf("Incorrect second target: %s_%s", ver.Targets[1].OS, ver.Targets[1].Arch)
+	}
+	if ver.Targets[1].Filename != "terraform-provider-test_1.0.0_linux_amd64.zip" {
+		t.Fatalf("Incorrect target file name: %s", ver.Targets[1].Filename)
+	}
+	expectedSum := sha256.Sum256([]byte("linux_amd64"))
+	if ver.Targets[1].SHASum != hex.EncodeToString(expectedSum[:]) {
+		t.Fatalf("Incorrect target checksum: %s", ver.Targets[1].SHASum)
+	}
+
+	err = registry.AddProvider(ctx, repo.String())
+	if err == nil {
+		t.Fatalf("Adding the same provider twice did not result in an error.")
+	}
+	var alreadyExists *libregistry.ProviderAlreadyExistsError
+	if !errors.As(err, &alreadyExists) {
+		t.Fatalf("Incorrect error type returned when adding a provider twice (%T instead of %T)", err, alreadyExists)
+	}
+}
+
+// TestAddProviderNamespaceKeyAs tests that only members of the organization matching the namespace can add keys.
+func TestAddProviderNamespaceKeyAs(t *testing.T) {
+	ctx := context.Background()
+	inMemoryVCS := fakevcs.New()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization("test"); err != nil {
+		t.Fatal(err)
+	}
+	for _, username := range []vcs.Username{"member", "outsider"} {
+		if err := inMemoryVCS.AddUser(username); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := inMemoryVCS.AddMember("test", "member"); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := crypto.GenerateKey("Test", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID := strings.ToUpper(key.GetHexKeyID())
+
+	t.Logf("⚙️ Adding a key as a user outside the organization...")
+	err = registry.AddProviderNamespaceKeyAs(ctx, "outsider", "test", provider.Key{ASCIIArmor: publicKey})
+	var permissionDenied *libregistry.PermissionDeniedError
+	if !errors.As(err, &permissionDenied) {
+		t.Fatalf("❌ Expected a permission denied error, got: %v", err)
+	}
+	if keyIDs, err := dataAPI.ListProviderNamespaceKeyIDs(ctx, "test"); err != nil || len(keyIDs) != 0 {
+		t.Fatalf("❌ The key was stored despite the denied permission: %v (%v)", keyIDs, err)
+	}
+	t.Logf("✅ The outsider was denied.")
+
+	err = registry.AddProviderNamespaceKeyAs(ctx, "member", "nonexistent", provider.Key{ASCIIArmor: publicKey})
+	var checkFailed *libregistry.PermissionCheckFailedError
+	var orgNotFound *vcs.OrganizationNotFoundError
+	if !errors.As(err, &checkFailed) || !errors.As(err, &orgNotFound) {
+		t.Fatalf("❌ Expected a permission check error for a missing organization, got: %v", err)
+	}
+	t.Logf("✅ Permission check errors are wrapped.")
+
+	t.Logf("⚙️ Adding a key as a member of the organization...")
+	if err := registry.AddProviderNamespaceKeyAs(ctx, "member", "test", provider.Key{ASCIIArmor: publicKey}); err != nil {
+		t.Fatalf("❌ Failed to add the key as a member (%v)", err)
+	}
+	stored, err := dataAPI.GetProviderNamespaceKey(ctx, "test", keyID)
+	if err != nil {
+		t.Fatalf("❌ The key was not stored (%v)", err)
+	}
+	if stored.KeyID != keyID {
+		t.Fatalf("❌ Incorrect key ID stored: %s (expected: %s)", stored.KeyID, keyID)
+	}
+	t.Logf("✅ The member added the key.")
+
+	privateKey, err := key.Armor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.AddProviderNamespaceKeyAs(ctx, "member", "test", provider.Key{ASCIIArmor: privateKey}); err == nil {
+		t.Fatalf("❌ Adding a private key did not fail.")
+	}
+	t.Logf("✅ Private keys are rejected.")
+}
+
+// createProviderNamespaceKey generates a signing key and registers its public key for the provider namespace.
+func createProviderNamespaceKey(t *testing.T, dataAPI metadata.API, namespace string) *crypto.KeyRing {
+	t.Helper()
+
+	key, err := crypto.GenerateKey("Test", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dataAPI.PutProviderNamespaceKey(context.Background(), namespace, provider.Key{
+		ASCIIArmor: publicKey,
+		KeyID:      strings.ToUpper(key.GetHexKeyID()),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	keyRing, err := crypto.NewKeyRing(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyRing
+}
+
+// createProviderRelease creates a release in the fake VCS with a SHA256SUMS file signed by the key ring, a manifest
+// and one zip file per platform.
+func createProviderRelease(
+	t *testing.T,
+	inMemoryVCS fakevcs.VCSClient,
+	keyRing *crypto.KeyRing,
+	providerAddr provider.Addr,
+	version vcs.VersionNumber,
+	platforms []string,
+) {
+	t.Helper()
+	createProviderReleaseWithContents(t, inMemoryVCS, keyRing, providerAddr, version, platforms, os.DirFS(t.TempDir()).(fs.ReadDirFS))
+}
+
+// createProviderReleaseWithContents creates a release like createProviderRelease with the given repository contents
+// at the release tag.
+func createProviderReleaseWithContents(
+	t *testing.T,
+	inMemoryVCS fakevcs.VCSClient,
+	keyRing *crypto.KeyRing,
+	providerAddr provider.Addr,
+	version vcs.VersionNumber,
+	platforms []string,
+	contents fs.ReadDirFS,
+) {
+	t.Helper()
+
+	repo := providerAddr.ToRepositoryAddr()
+	prefix := "terraform-provider-" + providerAddr.Name + "_" + strings.TrimPrefix(string(version), "v")
+
+	if err := inMemoryVCS.CreateVersion(repo, version, contents); err != nil {
+		t.Fatal(err)
+	}
+
+	shaSums := ""
+	for _, platform := range platforms {
+		fileName := prefix + "_" + platform + ".zip"
+		contents := []byte(platform)
+		if err := inMemoryVCS.AddAsset(repo, version, vcs.AssetName(fileName), contents); err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256(contents)
+		shaSums += hex.EncodeToString(sum[:]) + "  " + fileName + "\n"
+	}
+	if err := inMemoryVCS.AddAsset(repo, version, vcs.AssetName(prefix+"_SHA256SUMS"), []byte(shaSums)); err != nil {
+		t.Fatal(err)
+	}
+	signature, err := keyRing.SignDetached(crypto.NewPlainMessage([]byte(shaSums)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.AddAsset(repo, version, vcs.AssetName(prefix+"_SHA256SUMS.sig"), signature.GetBinary()); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.AddAsset(repo, version, vcs.AssetName(prefix+"_manifest.json"), []byte(`{"version":1,"metadata":{"protocol_versions":["6.0"]}}`)); err != nil {
+		t.Fatal(err)
+	}
+}
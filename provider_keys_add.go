@@ -0,0 +1,59 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/opentofu/libregistry/types/provider"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+func (m api) AddProviderNamespaceKeyAs(ctx context.Context, username vcs.Username, namespace string, key provider.Key) error {
+	if err := m.checkPermission(ctx, username, vcs.OrganizationAddr(namespace)); err != nil {
+		return err
+	}
+	return m.addProviderNamespaceKey(ctx, namespace, key)
+}
+
+// addProviderNamespaceKey checks that the key is a valid public key matching its key ID and stores it.
+func (m api) addProviderNamespaceKey(ctx context.Context, namespace string, key provider.Key) error {
+	if err := vcs.OrganizationAddr(namespace).Validate(); err != nil {
+		return &ProviderNamespaceKeyAddFailedError{
+			namespace,
+			err,
+		}
+	}
+	pgpKey, err := crypto.NewKeyFromArmored(key.ASCIIArmor)
+	if err != nil {
+		return &ProviderNamespaceKeyAddFailedError{
+			namespace,
+			fmt.Errorf("failed to parse key (%w)", err),
+		}
+	}
+	if pgpKey.IsPrivate() {
+		return &ProviderNamespaceKeyAddFailedError{
+			namespace,
+			fmt.Errorf("the key is a private key, only public keys are accepted"),
+		}
+	}
+	keyID := strings.ToUpper(pgpKey.GetHexKeyID())
+	if key.KeyID != "" && strings.ToUpper(key.KeyID) != keyID {
+		return &ProviderNamespaceKeyAddFailedError{
+			namespace,
+			fmt.Errorf("the key ID %s does not match the key (%s)", key.KeyID, keyID),
+		}
+	}
+	key.KeyID = keyID
+	if err := m.dataAPI.PutProviderNamespaceKey(ctx, namespace, key); err != nil {
+		return &ProviderNamespaceKeyAddFailedError{
+			namespace,
+			err,
+		}
+	}
+	return nil
+}
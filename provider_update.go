@@ -0,0 +1,476 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/opentofu/libregistry/internal/providerdocs"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/types"
+	"github.com/opentofu/libregistry/types/provider"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// defaultProviderProtocols are the protocol versions assumed when a release does not contain a manifest file.
+var defaultProviderProtocols = []string{"5.0"}
+
+func (m api) UpdateProvider(ctx context.Context, providerAddr provider.Addr) error {
+	if err := providerAddr.Validate(); err != nil {
+		return &ProviderUpdateFailedError{
+			providerAddr,
+			err,
+		}
+	}
+	providerAddr = providerAddr.Normalize()
+
+	providerMetadata, err := m.dataAPI.GetProvider(ctx, providerAddr, false)
+	if err != nil {
+		var notFoundError *metadata.ProviderNotFoundError
+		if !errors.As(err, &notFoundError) {
+			return &ProviderUpdateFailedError{
+				providerAddr,
+				err,
+			}
+		}
+		providerMetadata = provider.Metadata{}
+	}
+
+	repo, err := m.getProviderRepo(providerAddr, providerMetadata)
+	if err != nil {
+		return &ProviderUpdateFailedError{
+			providerAddr,
+			err,
+		}
+	}
+
+	existingVersions := provider.VersionList(providerMetadata.Versions)
+	releases, err := m.vcsClient.ListLatestReleases(ctx, repo)
+	if err != nil {
+		return &ProviderUpdateFailedError{
+			providerAddr,
+			err,
+		}
+	}
+	if !providerReleasesOverlap(existingVersions, releases) {
+		// No overlap found, do the full query:
+		releases, err = m.vcsClient.ListAllReleases(ctx, repo)
+		if err != nil {
+			return &ProviderUpdateFailedError{
+				providerAddr,
+				err,
+			}
+		}
+	}
+
+	// The provider file and the docs of new versions are written in one transaction so a failed update does not
+	// leave docs of unpublished versions behind.
+	tx, err := m.dataAPI.Begin(ctx)
+	if err != nil {
+		return &ProviderUpdateFailedError{
+			providerAddr,
+			err,
+		}
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var newVersions provider.VersionList
+	var rejected []error
+	var docsErrs []error
+	// tagCommits is only filled when the first new version is found as listing all tags may be expensive.
+	var tagCommits map[vcs.VersionNumber]string
+	keysChecked := false
+	for _, release := range releases {
+		ver, err := provider.VersionFromVCS(release.VersionNumber)
+		if err != nil {
+			continue
+		}
+		if providerVersionExists(existingVersions, ver) {
+			continue
+		}
+		if !keysChecked {
+			// Without keys every release would be downloaded only to be rejected, so fail early instead.
+			if err := m.checkProviderNamespaceKeys(ctx, providerAddr); err != nil {
+				return &ProviderUpdateFailedError{
+					providerAddr,
+					err,
+				}
+			}
+			keysChecked = true
+		}
+		providerVersion, err := m.getProviderVersion(ctx, providerAddr, repo, release.VersionNumber)
+		if err != nil {
+			var incompleteErr *ProviderReleaseIncompleteError
+			if errors.As(err, &incompleteErr) {
+				// Releases without a checksum file are not provider releases, skip them.
+				continue
+			}
+			var signatureErr *ProviderSignatureInvalidError
+			if errors.As(err, &signatureErr) {
+				rejected = append(rejected, err)
+				continue
+			}
+			return &ProviderUpdateFailedError{
+				providerAddr,
+				err,
+			}
+		}
+		providerVersion.Created = types.OptionalTime(release.Created)
+		providerVersion.Commit = release.Commit
+		if providerVersion.Commit == "" {
+			if tagCommits == nil {
+				tagCommits, err = m.getTagCommits(ctx, repo)
+				if err != nil {
+					return &ProviderUpdateFailedError{
+						providerAddr,
+						err,
+					}
+				}
+			}
+			providerVersion.Commit = tagCommits[release.VersionNumber]
+		}
+		if m.config.ProviderDocs {
+			// Broken docs must not block the release, so the version is published without them.
+			if err := m.storeProviderDocs(ctx, tx, providerAddr, repo, release.VersionNumber); err != nil {
+				docsErrs = append(docsErrs, &ProviderDocsFailedError{
+					Provider: providerAddr,
+					Version:  release.VersionNumber,
+					Cause:    err,
+				})
+			}
+		}
+		newVersions = append(newVersions, providerVersion)
+	}
+	providerMetadata.Versions = existingVersions.Merge(newVersions)
+
+	if err := tx.PutProvider(ctx, providerAddr, providerMetadata); err != nil {
+		return &ProviderUpdateFailedError{
+			providerAddr,
+			err,
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return &ProviderUpdateFailedError{
+			providerAddr,
+			err,
+		}
+	}
+	if len(rejected) != 0 || len(docsErrs) != 0 {
+		// The valid versions are stored, but the caller needs to know about the rejected ones and missing docs.
+		return &ProviderUpdateFailedError{
+			providerAddr,
+			errors.Join(append(rejected, docsErrs...)...),
+		}
+	}
+	return nil
+}
+
+// getProviderVersion assembles the full version entry for a single release from its assets.
+func (m api) getProviderVersion(
+	ctx context.Context,
+	providerAddr provider.Addr,
+	repo vcs.RepositoryAddr,
+	releaseVersion vcs.VersionNumber,
+) (provider.Version, error) {
+	assets, err := m.vcsClient.ListAssets(ctx, repo, releaseVersion)
+	if err != nil {
+		return provider.Version{}, err
+	}
+
+	versionWithoutPrefix := strings.TrimPrefix(string(releaseVersion), "v")
+	var shaSumsAsset, shaSumsSignatureAsset, manifestAsset vcs.AssetName
+	assetSet := make(map[vcs.AssetName]struct{}, len(assets))
+	for _, asset := range assets {
+		assetSet[asset] = struct{}{}
+		switch {
+		case strings.HasSuffix(string(asset), "_"+versionWithoutPrefix+"_SHA256SUMS"):
+			shaSumsAsset = asset
+		case strings.HasSuffix(string(asset), "_"+versionWithoutPrefix+"_SHA256SUMS.sig"):
+			shaSumsSignatureAsset = asset
+		case strings.HasSuffix(string(asset), "_"+versionWithoutPrefix+"_manifest.json"):
+			manifestAsset = asset
+		}
+	}
+	if shaSumsAsset == "" {
+		return provider.Version{}, &ProviderReleaseIncompleteError{
+			Provider: providerAddr,
+			Version:  releaseVersion,
+			Asset:    "SHA256SUMS",
+		}
+	}
+	if shaSumsSignatureAsset == "" {
+		return provider.Version{}, &ProviderReleaseIncompleteError{
+			Provider: providerAddr,
+			Version:  releaseVersion,
+			Asset:    "SHA256SUMS.sig",
+		}
+	}
+
+	shaSums, err := m.vcsClient.DownloadAsset(ctx, repo, releaseVersion, shaSumsAsset)
+	if err != nil {
+		return provider.Version{}, err
+	}
+	shaSumsSignature, err := m.vcsClient.DownloadAsset(ctx, repo, releaseVersion, shaSumsSignatureAsset)
+	if err != nil {
+		return provider.Version{}, err
+	}
+	if err := m.verifyProviderSignature(ctx, providerAddr, releaseVersion, shaSums, shaSumsSignature); err != nil {
+		return provider.Version{}, err
+	}
+
+	protocols := defaultProviderProtocols
+	if manifestAsset != "" {
+		manifest, err := m.vcsClient.DownloadAsset(ctx, repo, releaseVersion, manifestAsset)
+		if err != nil {
+			return provider.Version{}, err
+		}
+		protocols, err = parseProviderManifest(manifest)
+		if err != nil {
+			return provider.Version{}, fmt.Errorf("failed to parse %s (%w)", manifestAsset, err)
+		}
+	}
+
+	shaSumsURL, err := m.vcsClient.GetAssetDownloadURL(ctx, repo, releaseVersion, shaSumsAsset)
+	if err != nil {
+		return provider.Version{}, err
+	}
+	shaSumsSignatureURL, err := m.vcsClient.GetAssetDownloadURL(ctx, repo, releaseVersion, shaSumsSignatureAsset)
+	if err != nil {
+		return provider.Version{}, err
+	}
+
+	targets, err := m.getProviderTargets(ctx, repo, releaseVersion, shaSums, assetSet)
+	if err != nil {
+		return provider.Version{}, err
+	}
+
+	return provider.Version{
+		Version:             provider.VersionNumber(releaseVersion),
+		Protocols:           protocols,
+		SHASumsURL:          shaSumsURL,
+		SHASumsSignatureURL: shaSumsSignatureURL,
+		Targets:             targets,
+	}, nil
+}
+
+// storeProviderDocs checks out a provider version and stores the documentation pages found in it.
+func (m api) storeProviderDocs(ctx context.Context, dataAPI metadata.ProviderDataAPI, providerAddr provider.Addr, repo vcs.RepositoryAddr, releaseVersion vcs.VersionNumber) error {
+	workingCopy, err := m.vcsClient.Checkout(ctx, repo, releaseVersion)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = workingCopy.Close()
+	}()
+	docs, err := providerdocs.Extract(workingCopy)
+	if err != nil {
+		return fmt.Errorf("failed to collect the docs of version %s (%w)", releaseVersion, err)
+	}
+	for i := range docs {
+		docs[i].URL, err = m.vcsClient.GetFileViewURL(ctx, repo, releaseVersion, docs[i].Path)
+		if err != nil {
+			var noWebAccessErr *vcs.NoWebAccessError
+			if !errors.As(err, &noWebAccessErr) {
+				return err
+			}
+			docs[i].URL = ""
+		}
+	}
+	return dataAPI.PutProviderDocs(ctx, providerAddr, provider.VersionNumber(releaseVersion), docs)
+}
+
+// checkProviderNamespaceKeys returns a *ProviderNamespaceKeysMissingError if no signing keys are registered for the
+// provider namespace.
+func (m api) checkProviderNamespaceKeys(ctx context.Context, providerAddr provider.Addr) error {
+	keyIDs, err := m.dataAPI.ListProviderNamespaceKeyIDs(ctx, providerAddr.Namespace)
+	if err != nil {
+		return err
+	}
+	if len(keyIDs) == 0 {
+		return &ProviderNamespaceKeysMissingError{
+			Provider: providerAddr,
+		}
+	}
+	return nil
+}
+
+// verifyProviderSignature checks the detached signature of the SHA256SUMS file against all keys registered for the
+// provider namespace. The signature is accepted if any of the keys validates it.
+func (m api) verifyProviderSignature(
+	ctx context.Context,
+	providerAddr provider.Addr,
+	releaseVersion vcs.VersionNumber,
+	shaSums []byte,
+	shaSumsSignature []byte,
+) error {
+	keyIDs, err := m.dataAPI.ListProviderNamespaceKeyIDs(ctx, providerAddr.Namespace)
+	if err != nil {
+		return err
+	}
+
+	var signature *crypto.PGPSignature
+	if bytes.HasPrefix(bytes.TrimSpace(shaSumsSignature), []byte("-----BEGIN")) {
+		signature, err = crypto.NewPGPSignatureFromArmored(string(shaSumsSignature))
+		if err != nil {
+			return &ProviderSignatureInvalidError{
+				Provider: providerAddr,
+				Version:  releaseVersion,
+				KeyIDs:   keyIDs,
+				Cause:    err,
+			}
+		}
+	} else {
+		signature = crypto.NewPGPSignature(shaSumsSignature)
+	}
+
+	var lastErr error
+	for _, keyID := range keyIDs {
+		key, err := m.dataAPI.GetProviderNamespaceKey(ctx, providerAddr.Namespace, keyID)
+		if err != nil {
+			return err
+		}
+		pgpKey, err := crypto.NewKeyFromArmored(key.ASCIIArmor)
+		if err != nil {
+			return fmt.Errorf("failed to parse key %s for namespace %s (%w)", keyID, providerAddr.Namespace, err)
+		}
+		keyRing, err := crypto.NewKeyRing(pgpKey)
+		if err != nil {
+			return fmt.Errorf("failed to create key ring for key %s (%w)", keyID, err)
+		}
+		lastErr = keyRing.VerifyDetached(crypto.NewPlainMessage(shaSums), signature, crypto.GetUnixTime())
+		if lastErr == nil {
+			return nil
+		}
+	}
+	return &ProviderSignatureInvalidError{
+		Provider: providerAddr,
+		Version:  releaseVersion,
+		KeyIDs:   keyIDs,
+		Cause:    lastErr,
+	}
+}
+
+var providerTargetRe = regexp.MustCompile(`^.+_(?P<OS>[a-z0-9]+)_(?P<Arch>[a-z0-9]+)\.zip$`)
+
+// getProviderTargets creates the target list from the SHA256SUMS file. Only files that are actually present in the
+// release are included.
+func (m api) getProviderTargets(
+	ctx context.Context,
+	repo vcs.RepositoryAddr,
+	releaseVersion vcs.VersionNumber,
+	shaSums []byte,
+	assets map[vcs.AssetName]struct{},
+) ([]provider.Target, error) {
+	versionWithoutPrefix := strings.TrimPrefix(string(releaseVersion), "v")
+	var targets []provider.Target
+	scanner := bufio.NewScanner(bytes.NewReader(shaSums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		shaSum := fields[0]
+		fileName := vcs.AssetName(fields[1])
+		if _, ok := assets[fileName]; !ok {
+			continue
+		}
+		if !strings.Contains(string(fileName), "_"+versionWithoutPrefix+"_") {
+			continue
+		}
+		match := providerTargetRe.FindStringSubmatch(string(fileName))
+		if match == nil {
+			continue
+		}
+		downloadURL, err := m.vcsClient.GetAssetDownloadURL(ctx, repo, releaseVersion, fileName)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, provider.Target{
+			OS:          match[providerTargetRe.SubexpIndex("OS")],
+			Arch:        match[providerTargetRe.SubexpIndex("Arch")],
+			Filename:    string(fileName),
+			DownloadURL: downloadURL,
+			SHASum:      shaSum,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read SHA256SUMS file (%w)", err)
+	}
+	slices.SortFunc(targets, func(a, b provider.Target) int {
+		if c := strings.Compare(a.OS, b.OS); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Arch, b.Arch)
+	})
+	return targets, nil
+}
+
+func parseProviderManifest(manifest []byte) ([]string, error) {
+	var data struct {
+		Metadata struct {
+			ProtocolVersions []string `json:"protocol_versions"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(manifest, &data); err != nil {
+		return nil, err
+	}
+	if len(data.Metadata.ProtocolVersions) == 0 {
+		return defaultProviderProtocols, nil
+	}
+	return data.Metadata.ProtocolVersions, nil
+}
+
+// getTagCommits returns the commit of each tag in the repository. Release feeds and APIs do not reliably contain the
+// commit, so it is resolved from the tags instead.
+func (m api) getTagCommits(ctx context.Context, repo vcs.RepositoryAddr) (map[vcs.VersionNumber]string, error) {
+	tags, err := m.vcsClient.ListAllTags(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[vcs.VersionNumber]string, len(tags))
+	for _, tag := range tags {
+		result[tag.VersionNumber] = tag.Commit
+	}
+	return result, nil
+}
+
+func providerReleasesOverlap(existingVersions provider.VersionList, releases []vcs.Version) bool {
+	for _, release := range releases {
+		ver, err := provider.VersionFromVCS(release.VersionNumber)
+		if err != nil {
+			continue
+		}
+		if providerVersionExists(existingVersions, ver) {
+			return true
+		}
+	}
+	return false
+}
+
+func providerVersionExists(versions provider.VersionList, ver provider.VersionNumber) bool {
+	for _, existing := range versions {
+		if existing.Version.Normalize() == ver.Normalize() {
+			return true
+		}
+	}
+	return false
+}
+
+func (m api) getProviderRepo(providerAddr provider.Addr, providerMetadata provider.Metadata) (vcs.RepositoryAddr, error) {
+	if providerMetadata.CustomRepository != "" {
+		return m.vcsClient.ParseRepositoryAddr(providerMetadata.CustomRepository)
+	}
+	return providerAddr.ToRepositoryAddr(), nil
+}
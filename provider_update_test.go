@@ -0,0 +1,419 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opentofu/libregistry"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/types/provider"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+)
+
+// TestUpdateProvider tests that new releases are added to an existing provider and releases without a checksum file
+// are skipped.
+func TestUpdateProvider(t *testing.T) {
+	providerAddr := provider.Addr{
+		Namespace: "test",
+		Name:      "test",
+	}
+	repo := providerAddr.ToRepositoryAddr()
+
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(
+		inMemoryVCS,
+		dataAPI,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	keyRing := createProviderNamespaceKey(t, dataAPI, providerAddr.Namespace)
+	createProviderRelease(t, inMemoryVCS, keyRing, providerAddr, "v1.0.0", []string{"linux_amd64"})
+
+	if err := registry.UpdateProvider(ctx, providerAddr); err != nil {
+		t.Fatal(err)
+	}
+
+	createProviderRelease(t, inMemoryVCS, keyRing, providerAddr, "v1.1.0", []string{"linux_amd64"})
+	// This release has no assets and should be skipped.
+	if err := inMemoryVCS.CreateVersion(repo, "v1.2.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := registry.UpdateProvider(ctx, providerAddr); err != nil {
+		t.Fatal(err)
+	}
+
+	storedMetadata, err := dataAPI.GetProvider(ctx, providerAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(storedMetadata.Versions) != 2 {
+		t.Fatalf("Incorrect number of versions: %d", len(storedMetadata.Versions))
+	}
+	if ver := storedMetadata.Versions[0].Version; ver != "v1.1.0" {
+		t.Fatalf("Incorrect version in position 0: %s", ver)
+	}
+	if ver := storedMetadata.Versions[1].Version; ver != "v1.0.0" {
+		t.Fatalf("Incorrect version in position 1: %s", ver)
+	}
+	for _, ver := range storedMetadata.Versions {
+		tag, err := inMemoryVCS.GetTagVersion(ctx, repo, vcs.VersionNumber(ver.Version))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ver.Commit != tag.Commit {
+			t.Fatalf("Incorrect commit for version %s: %s (expected: %s)", ver.Version, ver.Commit, tag.Commit)
+		}
+		if ver.Created == nil || !ver.Created.Equal(tag.Created) {
+			t.Fatalf("Incorrect creation time for version %s: %s (expected: %s)", ver.Version, ver.Created, tag.Created)
+		}
+	}
+}
+
+// TestUpdateProviderCustomRepository tests that the custom repository in the provider metadata is used instead of
+// the repository derived from the provider address.
+func TestUpdateProviderCustomRepository(t *testing.T) {
+	providerAddr := provider.Addr{
+		Namespace: "test",
+		Name:      "test",
+	}
+	customProviderAddr := provider.Addr{
+		Namespace: "other",
+		Name:      "test",
+	}
+	customRepo := customProviderAddr.ToRepositoryAddr()
+
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(
+		inMemoryVCS,
+		dataAPI,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := inMemoryVCS.CreateOrganization(customRepo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(customRepo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	keyRing := createProviderNamespaceKey(t, dataAPI, providerAddr.Namespace)
+	createProviderRelease(t, inMemoryVCS, keyRing, customProviderAddr, "v1.0.0", []string{"linux_amd64"})
+
+	if err := dataAPI.PutProvider(ctx, providerAddr, provider.Metadata{
+		CustomRepository: customRepo.String(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := registry.UpdateProvider(ctx, providerAddr); err != nil {
+		t.Fatal(err)
+	}
+
+	storedMetadata, err := dataAPI.GetProvider(ctx, providerAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storedMetadata.CustomRepository != customRepo.String() {
+		t.Fatalf("The custom repository was not preserved: %s", storedMetadata.CustomRepository)
+	}
+	if len(storedMetadata.Versions) != 1 {
+		t.Fatalf("Incorrect number of versions: %d", len(storedMetadata.Versions))
+	}
+}
+
+// TestUpdateProviderInvalidSignature tests that versions signed with a key that is not registered for the namespace
+// are rejected with a *libregistry.ProviderSignatureInvalidError while valid versions are still stored.
+func TestUpdateProviderInvalidSignature(t *testing.T) {
+	providerAddr := provider.Addr{
+		Namespace: "test",
+		Name:      "test",
+	}
+	repo := providerAddr.ToRepositoryAddr()
+
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(
+		inMemoryVCS,
+		dataAPI,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+
+	validKeyRing := createProviderNamespaceKey(t, dataAPI, providerAddr.Namespace)
+	// This key is only registered for a different namespace.
+	invalidKeyRing := createProviderNamespaceKey(t, dataAPI, "other")
+	createProviderRelease(t, inMemoryVCS, validKeyRing, providerAddr, "v1.0.0", []string{"linux_amd64"})
+	createProviderRelease(t, inMemoryVCS, invalidKeyRing, providerAddr, "v1.1.0", []string{"linux_amd64"})
+
+	err = registry.UpdateProvider(ctx, providerAddr)
+	if err == nil {
+		t.Fatalf("Updating a provider with an invalid signature did not return an error.")
+	}
+	var signatureErr *libregistry.ProviderSignatureInvalidError
+	if !errors.As(err, &signatureErr) {
+		t.Fatalf("Incorrect error type returned (%T instead of %T)", err, signatureErr)
+	}
+	if signatureErr.Version != "v1.1.0" {
+		t.Fatalf("Incorrect version rejected: %s", signatureErr.Version)
+	}
+	keyIDs, err := dataAPI.ListProviderNamespaceKeyIDs(ctx, providerAddr.Namespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signatureErr.KeyIDs) != 1 || signatureErr.KeyIDs[0] != keyIDs[0] {
+		t.Fatalf("Incorrect key IDs in the error: %v", signatureErr.KeyIDs)
+	}
+
+	storedMetadata, err := dataAPI.GetProvider(ctx, providerAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(storedMetadata.Versions) != 1 {
+		t.Fatalf("Incorrect number of versions: %d", len(storedMetadata.Versions))
+	}
+	if ver := storedMetadata.Versions[0].Version; ver != "v1.0.0" {
+		t.Fatalf("Incorrect version stored: %s", ver)
+	}
+}
+
+// TestUpdateProviderInvalidAddr tests that an invalid provider address is rejected before it is used.
+func TestUpdateProviderInvalidAddr(t *testing.T) {
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(fakevcs.New(), dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = registry.UpdateProvider(ctx, provider.Addr{
+		Namespace: "test",
+		Name:      "../test",
+	})
+	var invalidErr *provider.InvalidProviderAddrError
+	if !errors.As(err, &invalidErr) {
+		t.Fatalf("Incorrect error returned for an invalid provider address (%v)", err)
+	}
+	providers, err := dataAPI.ListProviders(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(providers) != 0 {
+		t.Fatalf("A provider was stored for an invalid address: %v", providers)
+	}
+}
+
+// TestUpdateProviderNoKeys tests that updating a provider in a namespace without signing keys returns a
+// *libregistry.ProviderNamespaceKeysMissingError instead of rejecting each release.
+func TestUpdateProviderNoKeys(t *testing.T) {
+	providerAddr := provider.Addr{
+		Namespace: "test",
+		Name:      "test",
+	}
+	repo := providerAddr.ToRepositoryAddr()
+
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	// The key is only registered for a different namespace.
+	keyRing := createProviderNamespaceKey(t, dataAPI, "other")
+	createProviderRelease(t, inMemoryVCS, keyRing, providerAddr, "v1.0.0", []string{"linux_amd64"})
+
+	err = registry.UpdateProvider(ctx, providerAddr)
+	var keysMissingErr *libregistry.ProviderNamespaceKeysMissingError
+	if !errors.As(err, &keysMissingErr) {
+		t.Fatalf("Incorrect error returned for a namespace without keys (%v)", err)
+	}
+	var signatureErr *libregistry.ProviderSignatureInvalidError
+	if errors.As(err, &signatureErr) {
+		t.Fatalf("The release was checked against an empty key list (%v)", err)
+	}
+	if _, err := dataAPI.GetProvider(ctx, providerAddr, false); err == nil {
+		t.Fatalf("The provider was stored without any verified versions.")
+	}
+}
+
+// TestUpdateProviderDocs tests that the documentation of new provider versions is stored when enabled.
+func TestUpdateProviderDocs(t *testing.T) {
+	providerAddr := provider.Addr{
+		Namespace: "test",
+		Name:      "test",
+	}
+	repo := providerAddr.ToRepositoryAddr()
+
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI, libregistry.WithProviderDocs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	keyRing := createProviderNamespaceKey(t, dataAPI, providerAddr.Namespace)
+	createProviderReleaseWithContents(t, inMemoryVCS, keyRing, providerAddr, "v1.0.0", []string{"linux_amd64"}, fstest.MapFS{
+		"docs/index.md":           {Data: []byte("---\npage_title: \"Provider: Test\"\n---\n# Test provider\n")},
+		"docs/resources/thing.md": {Data: []byte("---\nsubcategory: \"Things\"\npage_title: \"test_thing Resource\"\n---\n# test_thing\n")},
+	})
+
+	t.Logf("⚙️ Updating provider with docs collection enabled...")
+	if err := registry.UpdateProvider(ctx, providerAddr); err != nil {
+		t.Fatalf("❌ Failed to update provider (%v)", err)
+	}
+
+	index, err := dataAPI.GetProviderDocsIndex(ctx, providerAddr, "v1.0.0")
+	if err != nil {
+		t.Fatalf("❌ Failed to fetch docs index (%v)", err)
+	}
+	if len(index.Items) != 2 {
+		t.Fatalf("❌ Incorrect number of docs: %d", len(index.Items))
+	}
+	resources := index.ByType(provider.DocTypeResource)
+	if len(resources) != 1 || resources[0].Slug != "thing" || resources[0].Subcategory != "Things" {
+		t.Fatalf("❌ Incorrect resources in the index: %v", resources)
+	}
+	t.Logf("✅ The docs index lists the pages.")
+
+	doc, err := dataAPI.GetProviderDoc(ctx, providerAddr, "v1.0.0", provider.DocTypeResource, "thing")
+	if err != nil {
+		t.Fatalf("❌ Failed to fetch doc (%v)", err)
+	}
+	if doc.Title != "test_thing Resource" || doc.Content != "# test_thing\n" || doc.Path != "docs/resources/thing.md" {
+		t.Fatalf("❌ Incorrect doc: %v", doc)
+	}
+	t.Logf("✅ The doc contents were stored.")
+
+	_, err = dataAPI.GetProviderDoc(ctx, providerAddr, "v1.0.0", provider.DocTypeDataSource, "thing")
+	var notFound *metadata.ProviderDocNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("❌ Fetching a missing doc did not return the correct error (%v)", err)
+	}
+	t.Logf("✅ Missing docs return a not found error.")
+}
+
+// TestUpdateProviderBrokenDocs tests that a release with docs that cannot be stored is published without docs and
+// does not block the other releases.
+func TestUpdateProviderBrokenDocs(t *testing.T) {
+	providerAddr := provider.Addr{
+		Namespace: "test",
+		Name:      "test",
+	}
+	repo := providerAddr.ToRepositoryAddr()
+
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI, libregistry.WithProviderDocs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	keyRing := createProviderNamespaceKey(t, dataAPI, providerAddr.Namespace)
+	// The page has no name, so it results in an invalid slug.
+	createProviderReleaseWithContents(t, inMemoryVCS, keyRing, providerAddr, "v1.0.0", []string{"linux_amd64"}, fstest.MapFS{
+		"docs/resources/.md": {Data: []byte("# test_thing\n")},
+	})
+	createProviderReleaseWithContents(t, inMemoryVCS, keyRing, providerAddr, "v1.1.0", []string{"linux_amd64"}, fstest.MapFS{
+		"docs/resources/thing.md": {Data: []byte("# test_thing\n")},
+	})
+
+	t.Logf("⚙️ Updating provider with a broken doc page...")
+	err = registry.UpdateProvider(ctx, providerAddr)
+	var docsErr *libregistry.ProviderDocsFailedError
+	if !errors.As(err, &docsErr) {
+		t.Fatalf("❌ The broken docs were not reported (%v)", err)
+	}
+	if docsErr.Version != "v1.0.0" {
+		t.Fatalf("❌ Incorrect version reported for the broken docs: %s", docsErr.Version)
+	}
+
+	storedMetadata, err := dataAPI.GetProvider(ctx, providerAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(storedMetadata.Versions) != 2 {
+		t.Fatalf("❌ Incorrect number of versions: %d", len(storedMetadata.Versions))
+	}
+	var notFound *metadata.ProviderDocsNotFoundError
+	if _, err := dataAPI.GetProviderDocsIndex(ctx, providerAddr, "v1.0.0"); !errors.As(err, &notFound) {
+		t.Fatalf("❌ Docs were stored for the version with the broken page (%v)", err)
+	}
+	if _, err := dataAPI.GetProviderDoc(ctx, providerAddr, "v1.1.0", provider.DocTypeResource, "thing"); err != nil {
+		t.Fatalf("❌ The docs of the other version were not stored (%v)", err)
+	}
+	t.Logf("✅ Both versions were published despite the broken docs.")
+}
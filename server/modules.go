@@ -0,0 +1,71 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/opentofu/libregistry/protocol"
+	"github.com/opentofu/libregistry/types/module"
+)
+
+// serveModule handles the following paths below protocol.ModulesV1Path:
+//
+//   - {namespace}/{name}/{system}/versions
+//   - {namespace}/{name}/{system}/{version}/download
+func (s server) serveModule(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 4 && parts[3] == "versions":
+		s.serveModuleVersions(w, r, module.Addr{
+			Namespace:    parts[0],
+			Name:         parts[1],
+			TargetSystem: parts[2],
+		})
+	case len(parts) == 5 && parts[4] == "download":
+		s.serveModuleDownload(w, r, module.Addr{
+			Namespace:    parts[0],
+			Name:         parts[1],
+			TargetSystem: parts[2],
+		}, module.VersionNumber(parts[3]))
+	default:
+		s.writeError(w, http.StatusNotFound)
+	}
+}
+
+func (s server) serveModuleVersions(w http.ResponseWriter, r *http.Request, moduleAddr module.Addr) {
+	if err := moduleAddr.Validate(); err != nil {
+		s.writeError(w, http.StatusNotFound)
+		return
+	}
+	moduleMetadata, err := s.dataAPI.GetModule(r.Context(), moduleAddr)
+	if err != nil {
+		s.handleError(w, r, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, protocol.NewModuleVersionsResponse(moduleMetadata))
+}
+
+func (s server) serveModuleDownload(w http.ResponseWriter, r *http.Request, moduleAddr module.Addr, version module.VersionNumber) {
+	if err := moduleAddr.Validate(); err != nil {
+		s.writeError(w, http.StatusNotFound)
+		return
+	}
+	moduleMetadata, err := s.dataAPI.GetModule(r.Context(), moduleAddr)
+	if err != nil {
+		s.handleError(w, r, err)
+		return
+	}
+	ver, ok := protocol.FindModuleVersion(moduleMetadata, version)
+	if !ok {
+		s.writeError(w, http.StatusNotFound)
+		return
+	}
+	source, err := s.config.ModuleSource(r.Context(), moduleAddr.Normalize(), moduleMetadata, ver)
+	if err != nil {
+		s.handleError(w, r, err)
+		return
+	}
+	w.Header().Set("X-Terraform-Get", source)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package server
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/opentofu/libregistry/protocol"
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+// providerAddrPartRe limits the characters in provider namespaces and names to prevent requests from escaping the
+// providers directory in the storage.
+var providerAddrPartRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// serveProvider handles the following paths below protocol.ProvidersV1Path:
+//
+//   - {namespace}/{type}/versions
+//   - {namespace}/{type}/{version}/download/{os}/{arch}
+func (s server) serveProvider(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 3 && parts[2] == "versions":
+		s.serveProviderVersions(w, r, provider.Addr{
+			Namespace: parts[0],
+			Name:      parts[1],
+		})
+	case len(parts) == 6 && parts[3] == "download":
+		s.serveProviderDownload(w, r, provider.Addr{
+			Namespace: parts[0],
+			Name:      parts[1],
+		}, provider.VersionNumber(parts[2]), parts[4], parts[5])
+	default:
+		s.writeError(w, http.StatusNotFound)
+	}
+}
+
+func (s server) serveProviderVersions(w http.ResponseWriter, r *http.Request, providerAddr provider.Addr) {
+	providerMetadata, _, ok := s.getProvider(w, r, providerAddr)
+	if !ok {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, protocol.NewProviderVersionsResponse(providerMetadata))
+}
+
+func (s server) serveProviderDownload(
+	w http.ResponseWriter,
+	r *http.Request,
+	providerAddr provider.Addr,
+	version provider.VersionNumber,
+	os string,
+	arch string,
+) {
+	providerMetadata, canonicalAddr, ok := s.getProvider(w, r, providerAddr)
+	if !ok {
+		return
+	}
+	ver, target, ok := protocol.FindProviderTarget(providerMetadata, version, os, arch)
+	if !ok {
+		s.writeError(w, http.StatusNotFound)
+		return
+	}
+
+	keyIDs, err := s.dataAPI.ListProviderNamespaceKeyIDs(r.Context(), canonicalAddr.Namespace)
+	if err != nil {
+		s.handleError(w, r, err)
+		return
+	}
+	keys := make([]provider.Key, len(keyIDs))
+	for i, keyID := range keyIDs {
+		keys[i], err = s.dataAPI.GetProviderNamespaceKey(r.Context(), canonicalAddr.Namespace, keyID)
+		if err != nil {
+			s.handleError(w, r, err)
+			return
+		}
+	}
+	s.writeJSON(w, http.StatusOK, protocol.NewProviderDownloadResponse(ver, target, keys))
+}
+
+// getProvider resolves the aliases of the provider address and returns the metadata of the canonical provider. If
+// the lookup fails, the error response is written and ok is false.
+func (s server) getProvider(
+	w http.ResponseWriter,
+	r *http.Request,
+	providerAddr provider.Addr,
+) (providerMetadata provider.Metadata, canonicalAddr provider.Addr, ok bool) {
+	if !providerAddrPartRe.MatchString(providerAddr.Namespace) || !providerAddrPartRe.MatchString(providerAddr.Name) {
+		s.writeError(w, http.StatusNotFound)
+		return provider.Metadata{}, provider.Addr{}, false
+	}
+	canonicalAddr, err := s.dataAPI.GetProviderCanonicalAddr(r.Context(), providerAddr)
+	if err != nil {
+		s.handleError(w, r, err)
+		return provider.Metadata{}, provider.Addr{}, false
+	}
+	providerMetadata, err = s.dataAPI.GetProvider(r.Context(), canonicalAddr, false)
+	if err != nil {
+		s.handleError(w, r, err)
+		return provider.Metadata{}, provider.Addr{}, false
+	}
+	return providerMetadata, canonicalAddr, true
+}
@@ -0,0 +1,81 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package server serves the Module and Provider Registry Protocols from a metadata.API.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/protocol"
+)
+
+// New creates an HTTP handler serving the registry protocols from the data API.
+func New(dataAPI metadata.API, options ...Opt) (http.Handler, error) {
+	config := Config{}
+	for _, opt := range options {
+		if err := opt(&config); err != nil {
+			return nil, err
+		}
+	}
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &server{
+		config:  config,
+		dataAPI: dataAPI,
+	}, nil
+}
+
+type server struct {
+	config  Config
+	dataAPI metadata.API
+}
+
+func (s server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.writeError(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch {
+	case r.URL.Path == protocol.ServiceDiscoveryPath:
+		s.writeJSON(w, http.StatusOK, protocol.NewServiceDiscoveryResponse())
+	case strings.HasPrefix(r.URL.Path, protocol.ModulesV1Path):
+		s.serveModule(w, r, strings.Split(strings.TrimPrefix(r.URL.Path, protocol.ModulesV1Path), "/"))
+	case strings.HasPrefix(r.URL.Path, protocol.ProvidersV1Path):
+		s.serveProvider(w, r, strings.Split(strings.TrimPrefix(r.URL.Path, protocol.ProvidersV1Path), "/"))
+	default:
+		s.writeError(w, http.StatusNotFound)
+	}
+}
+
+// handleError writes the response for an error returned by the data API.
+func (s server) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	var moduleNotFound *metadata.ModuleNotFoundError
+	var providerNotFound *metadata.ProviderNotFoundError
+	if errors.As(err, &moduleNotFound) || errors.As(err, &providerNotFound) {
+		s.writeError(w, http.StatusNotFound)
+		return
+	}
+	s.config.Logger.Error(r.Context(), "Failed to serve %s (%v)", r.URL.Path, err)
+	s.writeError(w, http.StatusInternalServerError)
+}
+
+func (s server) writeError(w http.ResponseWriter, status int) {
+	s.writeJSON(w, status, protocol.ErrorResponse{
+		Errors: []string{http.StatusText(status)},
+	})
+}
+
+func (s server) writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,210 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/protocol"
+	"github.com/opentofu/libregistry/server"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
+	"github.com/opentofu/libregistry/vcs/gitlab"
+)
+
+func TestServiceDiscovery(t *testing.T) {
+	srv := newTestServer(t)
+
+	var response protocol.ServiceDiscoveryResponse
+	getJSON(t, srv.URL+"/.well-known/terraform.json", http.StatusOK, &response)
+	if response.ModulesV1 != "/v1/modules/" || response.ProvidersV1 != "/v1/providers/" {
+		t.Fatalf("Incorrect service discovery response: %v", response)
+	}
+}
+
+func TestModules(t *testing.T) {
+	srv := newTestServer(t)
+
+	var versions protocol.ModuleVersionsResponse
+	getJSON(t, srv.URL+"/v1/modules/opentofu/test/aws/versions", http.StatusOK, &versions)
+	if len(versions.Modules) != 1 || len(versions.Modules[0].Versions) != 1 {
+		t.Fatalf("Incorrect module versions response: %v", versions)
+	}
+	if ver := versions.Modules[0].Versions[0].Version; ver != "1.0.0" {
+		t.Fatalf("Incorrect module version: %s", ver)
+	}
+
+	resp, err := http.Get(srv.URL + "/v1/modules/opentofu/test/aws/1.0.0/download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Incorrect status code for module download: %d", resp.StatusCode)
+	}
+	if location := resp.Header.Get("X-Terraform-Get"); location != "git::https://github.com/opentofu/terraform-aws-test?ref=v1.0.0" {
+		t.Fatalf("Incorrect module download location: %s", location)
+	}
+
+	getJSON(t, srv.URL+"/v1/modules/opentofu/test/aws/2.0.0/download", http.StatusNotFound, nil)
+	getJSON(t, srv.URL+"/v1/modules/opentofu/nonexistent/aws/versions", http.StatusNotFound, nil)
+}
+
+func TestModulesVCSSource(t *testing.T) {
+	gitlabClient, err := gitlab.New(gitlab.WithBaseURL("https://gitlab.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestServer(t, server.WithModuleSource(protocol.VCSModuleSource(gitlabClient)))
+
+	resp, err := http.Get(srv.URL + "/v1/modules/opentofu/test/aws/1.0.0/download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if location := resp.Header.Get("X-Terraform-Get"); location != "git::https://gitlab.example.com/opentofu/terraform-aws-test?ref=v1.0.0" {
+		t.Fatalf("Incorrect module download location: %s", location)
+	}
+}
+
+func TestModuleSourceRequired(t *testing.T) {
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.New(dataAPI); err == nil {
+		t.Fatalf("Creating a server without a module source did not fail.")
+	}
+}
+
+func TestProviders(t *testing.T) {
+	srv := newTestServer(t)
+
+	for _, namespace := range []string{"opentofu", "hashicorp"} {
+		var versions protocol.ProviderVersionsResponse
+		getJSON(t, srv.URL+"/v1/providers/"+namespace+"/test/versions", http.StatusOK, &versions)
+		if len(versions.Versions) != 1 || versions.Versions[0].Version != "1.0.0" {
+			t.Fatalf("Incorrect provider versions response for namespace %s: %v", namespace, versions)
+		}
+		if len(versions.Versions[0].Platforms) != 1 || versions.Versions[0].Platforms[0].OS != "linux" {
+			t.Fatalf("Incorrect provider platforms for namespace %s: %v", namespace, versions.Versions[0].Platforms)
+		}
+
+		var download protocol.ProviderDownloadResponse
+		getJSON(t, srv.URL+"/v1/providers/"+namespace+"/test/1.0.0/download/linux/amd64", http.StatusOK, &download)
+		if download.Filename != "terraform-provider-test_1.0.0_linux_amd64.zip" {
+			t.Fatalf("Incorrect provider download file name: %s", download.Filename)
+		}
+		if len(download.SigningKeys.GPGPublicKeys) != 1 || download.SigningKeys.GPGPublicKeys[0].ASCIIArmor == "" {
+			t.Fatalf("Incorrect signing keys: %v", download.SigningKeys)
+		}
+	}
+
+	getJSON(t, srv.URL+"/v1/providers/opentofu/test/1.0.0/download/darwin/amd64", http.StatusNotFound, nil)
+	getJSON(t, srv.URL+"/v1/providers/opentofu/nonexistent/versions", http.StatusNotFound, nil)
+	getJSON(t, srv.URL+"/v1/providers/../test/versions", http.StatusNotFound, nil)
+}
+
+func newTestServer(t *testing.T, opts ...server.Opt) *httptest.Server {
+	t.Helper()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dataAPI.PutModule(ctx, module.Addr{
+		Namespace:    "opentofu",
+		Name:         "test",
+		TargetSystem: "aws",
+	}, module.Metadata{
+		Versions: []module.Version{
+			{
+				Version: "v1.0.0",
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	providerAddr := provider.Addr{
+		Namespace: "opentofu",
+		Name:      "test",
+	}
+	if err := dataAPI.PutProvider(ctx, providerAddr, provider.Metadata{
+		Versions: []provider.Version{
+			{
+				Version:             "v1.0.0",
+				Protocols:           []string{"5.0"},
+				SHASumsURL:          "https://localhost/terraform-provider-test_1.0.0_SHA256SUMS",
+				SHASumsSignatureURL: "https://localhost/terraform-provider-test_1.0.0_SHA256SUMS.sig",
+				Targets: []provider.Target{
+					{
+						OS:          "linux",
+						Arch:        "amd64",
+						Filename:    "terraform-provider-test_1.0.0_linux_amd64.zip",
+						DownloadURL: "https://localhost/terraform-provider-test_1.0.0_linux_amd64.zip",
+						SHASum:      "c0535e4be2b79ffd93291305436bf889314e4a3faec05ecffcbb7df31ad9e51a",
+					},
+				},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataAPI.PutProviderNamespaceAlias(ctx, "hashicorp", "opentofu"); err != nil {
+		t.Fatal(err)
+	}
+	key, err := crypto.GenerateKey("Test", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dataAPI.PutProviderNamespaceKey(ctx, "opentofu", provider.Key{
+		ASCIIArmor: publicKey,
+		KeyID:      strings.ToUpper(key.GetHexKeyID()),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Options passed by the test override the GitHub module source.
+	opts = append([]server.Opt{server.WithModuleSource(protocol.GitHubModuleSource)}, opts...)
+	handler, err := server.New(dataAPI, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func getJSON(t *testing.T, url string, expectedStatus int, target any) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != expectedStatus {
+		t.Fatalf("Incorrect status code for %s: %d instead of %d", url, resp.StatusCode, expectedStatus)
+	}
+	if target != nil {
+		if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+			t.Fatalf("Failed to decode response from %s (%v)", url, err)
+		}
+	}
+}
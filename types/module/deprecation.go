@@ -0,0 +1,44 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package module
+
+import (
+	"strings"
+	"time"
+
+	"github.com/opentofu/libregistry/types"
+)
+
+// Deprecation marks a module or a single module version as deprecated. Deprecated modules and versions remain
+// available, but users should be told to move on.
+type Deprecation struct {
+	// Reason is a human-readable explanation why the module or version is deprecated.
+	Reason string `json:"reason"`
+	// SuccessorAddr optionally points users to the module they should use instead.
+	SuccessorAddr *Addr `json:"successor_addr,omitempty"`
+	// Since is the time the module or version was deprecated.
+	Since *time.Time `json:"since,omitempty"`
+}
+
+// Equals returns true if both deprecations are nil or hold the same values.
+func (d *Deprecation) Equals(other *Deprecation) bool {
+	if d == nil || other == nil {
+		return d == other
+	}
+	if (d.SuccessorAddr == nil) != (other.SuccessorAddr == nil) {
+		return false
+	}
+	if d.SuccessorAddr != nil && !d.SuccessorAddr.Equals(*other.SuccessorAddr) {
+		return false
+	}
+	return d.Reason == other.Reason && types.EqualTimes(d.Since, other.Since)
+}
+
+// String returns the reason and the successor, if any, as a human-readable message.
+func (d Deprecation) String() string {
+	if d.SuccessorAddr == nil {
+		return d.Reason
+	}
+	return strings.TrimSpace(d.Reason + " Use " + d.SuccessorAddr.String() + " instead.")
+}
@@ -0,0 +1,65 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package module
+
+// VersionDetails holds the documentation of a single module version as extracted from its source code.
+type VersionDetails struct {
+	// Root describes the module itself.
+	Root Details `json:"root"`
+	// Submodules describes the modules in the modules/ directory of the module.
+	Submodules []Details `json:"submodules,omitempty"`
+	// Examples describes the examples in the examples/ directory of the module.
+	Examples []Details `json:"examples,omitempty"`
+}
+
+// Details describes the interface of a single directory of a module.
+type Details struct {
+	// Path is the directory relative to the module root. It is empty for the module itself.
+	Path string `json:"path,omitempty"`
+	// README holds the contents of the README.md file in the directory, if any.
+	README string `json:"readme,omitempty"`
+	// Variables holds the input variables in the order of their declaration.
+	Variables []Variable `json:"variables,omitempty"`
+	// Outputs holds the outputs in the order of their declaration.
+	Outputs []Output `json:"outputs,omitempty"`
+	// RequiredProviders holds the providers declared in the required_providers blocks, sorted by name.
+	RequiredProviders []RequiredProvider `json:"required_providers,omitempty"`
+	// RequiredVersion holds the required_version constraints of all terraform blocks.
+	RequiredVersion []string `json:"required_version,omitempty"`
+	// ParseErrors holds the errors of the files that could not be parsed. The details above are incomplete if this
+	// is not empty.
+	ParseErrors []string `json:"parse_errors,omitempty"`
+}
+
+// Variable describes an input variable of a module.
+type Variable struct {
+	Name string `json:"name"`
+	// Type is the type constraint as written in the source code, for example list(string). It is empty if the
+	// variable accepts any type.
+	Type string `json:"type,omitempty"`
+	// Default is the default value as written in the source code. It is empty if the variable is required.
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description,omitempty"`
+	Sensitive   bool   `json:"sensitive,omitempty"`
+	// Required is true if the variable has no default value.
+	Required bool `json:"required"`
+}
+
+// Output describes an output of a module.
+type Output struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Sensitive   bool   `json:"sensitive,omitempty"`
+}
+
+// RequiredProvider describes a provider a module depends on.
+type RequiredProvider struct {
+	// Name is the local name of the provider within the module.
+	Name string `json:"name"`
+	// Source is the provider address, for example hashicorp/aws. It is empty if the module relies on the default
+	// source for the local name.
+	Source string `json:"source,omitempty"`
+	// VersionConstraints holds the version constraints for the provider from all required_providers blocks.
+	VersionConstraints []string `json:"version_constraints,omitempty"`
+}
@@ -18,3 +18,12 @@ func (i InvalidModuleAddrError) Error() string {
 func (i InvalidModuleAddrError) Unwrap() error {
 	return i.Cause
 }
+
+type InvalidSourceError struct {
+	Source Source
+	Reason string
+}
+
+func (i InvalidSourceError) Error() string {
+	return "Invalid module source: " + i.Reason
+}
@@ -0,0 +1,23 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package module
+
+// ForkNotice records the fork policy decision for a module whose repository is a fork and that was accepted when it
+// was added, so updates can report it without querying the VCS again.
+type ForkNotice struct {
+	// Policy is the fork policy that was applied.
+	Policy string `json:"policy"`
+	// ForkOf is the parent repository of the module repository.
+	ForkOf string `json:"fork_of"`
+	// Reason is a human-readable explanation of the decision.
+	Reason string `json:"reason"`
+}
+
+// Equals returns true if both notices are nil or hold the same values.
+func (f *ForkNotice) Equals(other *ForkNotice) bool {
+	if f == nil || other == nil {
+		return f == other
+	}
+	return *f == *other
+}
@@ -3,13 +3,40 @@
 
 package module
 
+import (
+	"slices"
+)
+
 // Metadata represents all the metadata for a module. This includes the list of versions available for the module.
 // This structure represents the file in modules/o/opentofu/somemodule/platform.json.
 type Metadata struct {
+	// Source is only set for modules that do not follow the repository and tag naming conventions.
+	Source
 	// Versions lists all available versions of a Namespace-Name-TargetSystem combination.
 	Versions VersionList `json:"versions"`
+	// Deprecation marks the whole module as deprecated if set.
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+	// Fork is set if the module repository is a fork and the fork policy reported on it when the module was added.
+	Fork *ForkNotice `json:"fork,omitempty"`
+	// RemovedVersions lists the versions the tag change policy removed because their tag was deleted or moved. They
+	// are not published again on update, even if the tag is recreated. Remove a version from this list to allow
+	// publishing it again.
+	RemovedVersions []VersionNumber `json:"removed_versions,omitempty"`
 }
 
 func (m Metadata) Equals(other Metadata) bool {
-	return m.Versions.Equals(other.Versions)
+	return m.Source == other.Source && m.Versions.Equals(other.Versions) && m.Deprecation.Equals(other.Deprecation) &&
+		m.Fork.Equals(other.Fork) && slices.Equal(m.RemovedVersions, other.RemovedVersions)
+}
+
+// Warnings returns the deprecation notices of the module and its versions.
+func (m Metadata) Warnings() []string {
+	var result []string
+	if m.Deprecation != nil {
+		result = append(result, "This module is deprecated. "+m.Deprecation.String())
+	}
+	for _, ver := range m.Versions {
+		result = append(result, ver.Warnings()...)
+	}
+	return result
 }
@@ -0,0 +1,68 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package module
+
+import (
+	"path"
+	"strings"
+
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// Source describes where a module is stored if it does not follow the one module per terraform-<system>-<name>
+// repository convention, for example when many modules share a repository. The zero value means the convention
+// applies.
+type Source struct {
+	// CustomRepository is the repository holding the module in the format accepted by the VCS client. Defaults to the
+	// repository derived from the module address.
+	CustomRepository string `json:"repository,omitempty"`
+	// TagPrefix is the part of the tags that precedes the version number, for example "vpc/" for "vpc/v1.2.0". Only
+	// tags with this prefix are considered versions of the module.
+	TagPrefix string `json:"tag_prefix,omitempty"`
+	// Subdirectory is the directory within the repository holding the module, without leading or trailing slashes.
+	Subdirectory string `json:"subdirectory,omitempty"`
+}
+
+// Validate checks that the subdirectory stays within the repository and the tag prefix can be part of a tag.
+func (s Source) Validate() error {
+	if s.Subdirectory != "" {
+		cleaned := path.Clean(s.Subdirectory)
+		if cleaned != s.Subdirectory || path.IsAbs(cleaned) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return &InvalidSourceError{Source: s, Reason: "invalid subdirectory: " + s.Subdirectory}
+		}
+	}
+	if s.TagPrefix != "" {
+		if err := vcs.VersionNumber(s.TagPrefix + "v0.0.0").Validate(); err != nil {
+			return &InvalidSourceError{Source: s, Reason: "invalid tag prefix: " + s.TagPrefix}
+		}
+	}
+	return nil
+}
+
+// VersionFromTag strips the tag prefix and returns the module version of a tag. It returns false if the tag does not
+// belong to the module.
+func (s Source) VersionFromTag(tag vcs.VersionNumber) (VersionNumber, bool) {
+	if !strings.HasPrefix(string(tag), s.TagPrefix) {
+		return "", false
+	}
+	ver, err := VersionFromVCS(tag[len(s.TagPrefix):])
+	if err != nil {
+		return "", false
+	}
+	return ver, true
+}
+
+// Tag returns the tag of a module version in the repository.
+func (s Source) Tag(version VersionNumber) vcs.VersionNumber {
+	return vcs.VersionNumber(s.TagPrefix) + version.ToVCSVersion()
+}
+
+// VersionTag returns the tag a stored version was published from. Versions published before the tag was recorded
+// fall back to the tag built from the version number.
+func (s Source) VersionTag(version Version) vcs.VersionNumber {
+	if version.Tag != "" {
+		return version.Tag
+	}
+	return s.Tag(version.Version)
+}
@@ -3,20 +3,56 @@
 
 package module
 
+import (
+	"time"
+
+	"github.com/opentofu/libregistry/types"
+	"github.com/opentofu/libregistry/vcs"
+)
+
 // Version represents a single version of a module.
 type Version struct {
 	// Version number of the provider. Correlates to a tag in the module repository.
 	Version VersionNumber `json:"version"`
+	// Tag is the tag of the version in the module repository, including the tag prefix of the source. Empty for
+	// versions published before the tag was recorded, use Source.VersionTag to read it.
+	Tag vcs.VersionNumber `json:"tag,omitempty"`
+	// Commit is the SHA of the commit the tag pointed to when the version was published. It is used to detect tags
+	// that were moved in the module repository. Empty for versions published before the commit was recorded.
+	Commit string `json:"commit,omitempty"`
+	// Created is the time the tag was created. Nil for versions published before the creation time was recorded.
+	Created *time.Time `json:"created,omitempty"`
+	// Yanked indicates that the version is broken at the source and must no longer be selected, for example because
+	// its tag was deleted or moved. It is set by the tag change policy on update, and yanked versions are left out
+	// of the version list. Use Deprecation instead to discourage a version that still works.
+	Yanked bool `json:"yanked,omitempty"`
+	// YankReason is a human-readable explanation why the version was yanked.
+	YankReason string `json:"yank_reason,omitempty"`
+	// Deprecation marks this version as deprecated if set by the registry maintainers. Unlike yanked versions,
+	// deprecated versions are still offered to users, only with a warning.
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
 }
 
 func (v Version) Normalize() Version {
 	return Version{
-		Version: v.Version.Normalize(),
+		Version:     v.Version.Normalize(),
+		Tag:         v.Tag,
+		Commit:      v.Commit,
+		Created:     v.Created,
+		Yanked:      v.Yanked,
+		YankReason:  v.YankReason,
+		Deprecation: v.Deprecation,
 	}
 }
 
 func (v Version) Equals(other Version) bool {
-	return v.Version.Normalize() == other.Version.Normalize()
+	return v.Version.Normalize() == other.Version.Normalize() &&
+		v.Tag == other.Tag &&
+		v.Commit == other.Commit &&
+		types.EqualTimes(v.Created, other.Created) &&
+		v.Yanked == other.Yanked &&
+		v.YankReason == other.YankReason &&
+		v.Deprecation.Equals(other.Deprecation)
 }
 
 func (v Version) Compare(other Version) int {
@@ -26,3 +62,19 @@ func (v Version) Compare(other Version) int {
 func (v Version) Validate() error {
 	return v.Version.Validate()
 }
+
+// Warnings returns human-readable notices for users of this version, such as why it was yanked or deprecated.
+func (v Version) Warnings() []string {
+	var result []string
+	if v.Yanked {
+		msg := "Version " + string(v.Version) + " was yanked."
+		if v.YankReason != "" {
+			msg += " " + v.YankReason
+		}
+		result = append(result, msg)
+	}
+	if v.Deprecation != nil {
+		result = append(result, "Version "+string(v.Version)+" is deprecated. "+v.Deprecation.String())
+	}
+	return result
+}
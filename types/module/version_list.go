@@ -48,3 +48,25 @@ func (v VersionList) Equals(other VersionList) bool {
 	}
 	return true
 }
+
+// WithoutYanked returns the versions that are not yanked in their original order.
+func (v VersionList) WithoutYanked() VersionList {
+	result := make(VersionList, 0, len(v))
+	for _, ver := range v {
+		if !ver.Yanked {
+			result = append(result, ver)
+		}
+	}
+	return result
+}
+
+// Deprecated returns the versions that are yanked or deprecated in their original order.
+func (v VersionList) Deprecated() VersionList {
+	var result VersionList
+	for _, ver := range v {
+		if ver.Yanked || ver.Deprecation != nil {
+			result = append(result, ver)
+		}
+	}
+	return result
+}
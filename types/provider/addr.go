@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/opentofu/libregistry/vcs"
+	regaddr "github.com/opentofu/registry-address"
 )
 
 // Addr represents a full provider address (NAMESPACE/NAME). It currently translates to
@@ -18,6 +19,17 @@ type Addr struct {
 	Name      string `json:"-"`
 }
 
+func (a Addr) Validate() error {
+	_, err := regaddr.ParseProviderSource(fmt.Sprintf("%s/%s", a.Namespace, a.Name))
+	if err != nil {
+		return &InvalidProviderAddrError{
+			a,
+			err,
+		}
+	}
+	return nil
+}
+
 func (a Addr) MarshalJSON() ([]byte, error) {
 	// Note: this intentionally doesn't have a pointer receiver! Don't add one!
 	normalized := a.Normalize()
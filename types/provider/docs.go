@@ -0,0 +1,75 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+)
+
+// DocType describes the kind of page in the provider documentation.
+type DocType string
+
+const (
+	// DocTypeOverview is the index page of the provider documentation.
+	DocTypeOverview DocType = "overview"
+	// DocTypeResource documents a managed resource.
+	DocTypeResource DocType = "resources"
+	// DocTypeDataSource documents a data source.
+	DocTypeDataSource DocType = "data-sources"
+	// DocTypeGuide is a free-form guide, such as an upgrade guide.
+	DocTypeGuide DocType = "guides"
+	// DocTypeFunction documents a provider-defined function.
+	DocTypeFunction DocType = "functions"
+)
+
+// Validate returns an error if the doc type is not one of the known types.
+func (t DocType) Validate() error {
+	switch t {
+	case DocTypeOverview, DocTypeResource, DocTypeDataSource, DocTypeGuide, DocTypeFunction:
+		return nil
+	default:
+		return fmt.Errorf("invalid doc type: %s", t)
+	}
+}
+
+// DocItem describes a single page of the provider documentation without its contents.
+type DocItem struct {
+	Type DocType `json:"type"`
+	// Slug identifies the page within its type. It is the file name without extensions, for example "instance" for
+	// docs/resources/instance.md.
+	Slug string `json:"slug"`
+	// Title is the page_title from the frontmatter, if any.
+	Title string `json:"title,omitempty"`
+	// Subcategory is the subcategory from the frontmatter, if any.
+	Subcategory string `json:"subcategory,omitempty"`
+	// Description is the description from the frontmatter, if any.
+	Description string `json:"description,omitempty"`
+	// Path is the path of the file within the provider repository.
+	Path string `json:"path"`
+	// URL is the address the file can be viewed at in the VCS. It is empty if the VCS has no web access.
+	URL string `json:"url,omitempty"`
+}
+
+// Doc is a single page of the provider documentation.
+type Doc struct {
+	DocItem
+	// Content holds the Markdown contents of the page without the frontmatter.
+	Content string `json:"content"`
+}
+
+// DocsIndex lists the documentation pages of a single provider version.
+type DocsIndex struct {
+	Items []DocItem `json:"items"`
+}
+
+// ByType returns the pages of the given type.
+func (d DocsIndex) ByType(docType DocType) []DocItem {
+	var result []DocItem
+	for _, item := range d.Items {
+		if item.Type == docType {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,20 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+type InvalidProviderAddrError struct {
+	Addr  Addr
+	Cause error
+}
+
+func (i InvalidProviderAddrError) Error() string {
+	if i.Cause != nil {
+		return "Invalid provider address: " + i.Addr.String() + " (" + i.Cause.Error() + ")"
+	}
+	return "Invalid provider address: " + i.Addr.String()
+}
+
+func (i InvalidProviderAddrError) Unwrap() error {
+	return i.Cause
+}
@@ -3,17 +3,25 @@
 
 package provider
 
+import (
+	"slices"
+)
+
 // Metadata contains information about the provider.
 type Metadata struct {
-	CustomRepository string    `json:"repository,omitempty"` // Optional. Custom repository from which to fetch the provider's metadata.
-	Versions         []Version `json:"versions"`             // A list of version data, for each supported provider version.
-	Warnings         []string  `json:"warnings,omitempty"`   // Warnings for this provider.
+	CustomRepository string       `json:"repository,omitempty"`  // Optional. Custom repository from which to fetch the provider's metadata.
+	Versions         []Version    `json:"versions"`              // A list of version data, for each supported provider version.
+	Warnings         []string     `json:"warnings,omitempty"`    // Warnings for this provider.
+	Deprecation      *Deprecation `json:"deprecation,omitempty"` // Marks the whole provider as deprecated if set.
 }
 
 func (m Metadata) Equals(other Metadata) bool {
 	if m.CustomRepository != other.CustomRepository {
 		return false
 	}
+	if !m.Deprecation.Equals(other.Deprecation) {
+		return false
+	}
 	if len(m.Versions) != len(other.Versions) {
 		return false
 	}
@@ -24,3 +32,22 @@ func (m Metadata) Equals(other Metadata) bool {
 	}
 	return true
 }
+
+// ProviderWarnings returns the provider warnings together with the deprecation notice of the provider, but not the
+// notices of individual versions.
+func (m Metadata) ProviderWarnings() []string {
+	result := slices.Clone(m.Warnings)
+	if m.Deprecation != nil {
+		result = append(result, "This provider is deprecated. "+m.Deprecation.String())
+	}
+	return result
+}
+
+// AllWarnings returns the provider warnings together with the deprecation notices of the provider and its versions.
+func (m Metadata) AllWarnings() []string {
+	result := m.ProviderWarnings()
+	for _, ver := range m.Versions {
+		result = append(result, ver.Warnings()...)
+	}
+	return result
+}
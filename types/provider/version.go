@@ -3,6 +3,12 @@
 
 package provider
 
+import (
+	"time"
+
+	"github.com/opentofu/libregistry/types"
+)
+
 // Version contains information about a specific provider version.
 type Version struct {
 	Version             VersionNumber `json:"version"`               // The version number of the provider.
@@ -10,6 +16,9 @@ type Version struct {
 	SHASumsURL          string        `json:"shasums_url"`           // The URL to the SHA checksums file.
 	SHASumsSignatureURL string        `json:"shasums_signature_url"` // The URL to the GPG signature of the SHA checksums file.
 	Targets             []Target      `json:"targets"`               // A list of target platforms for which this provider version is available.
+	Commit              string        `json:"commit,omitempty"`      // The SHA of the commit the release tag points to, if known.
+	Created             *time.Time    `json:"created,omitempty"`     // The time the release was published, if known.
+	Deprecation         *Deprecation  `json:"deprecation,omitempty"` // Marks this version as deprecated if set.
 }
 
 func (v Version) Normalize() Version {
@@ -19,6 +28,9 @@ func (v Version) Normalize() Version {
 		SHASumsURL:          v.SHASumsURL,
 		SHASumsSignatureURL: v.SHASumsSignatureURL,
 		Targets:             v.Targets,
+		Commit:              v.Commit,
+		Created:             v.Created,
+		Deprecation:         v.Deprecation,
 	}
 }
 
@@ -40,6 +52,9 @@ func (v Version) Equals(other Version) bool {
 	if v.SHASumsSignatureURL != other.SHASumsSignatureURL {
 		return false
 	}
+	if v.Commit != other.Commit || !types.EqualTimes(v.Created, other.Created) || !v.Deprecation.Equals(other.Deprecation) {
+		return false
+	}
 	if len(v.Targets) != len(other.Targets) {
 		return false
 	}
@@ -58,3 +73,11 @@ func (v Version) Compare(other Version) int {
 func (v Version) Validate() error {
 	return v.Version.Validate()
 }
+
+// Warnings returns human-readable notices for users of this version, such as why it is deprecated.
+func (v Version) Warnings() []string {
+	if v.Deprecation == nil {
+		return nil
+	}
+	return []string{"Version " + string(v.Version) + " is deprecated. " + v.Deprecation.String()}
+}
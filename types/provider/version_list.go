@@ -48,3 +48,14 @@ func (v VersionList) Equals(other VersionList) bool {
 	}
 	return true
 }
+
+// Deprecated returns the deprecated versions in their original order.
+func (v VersionList) Deprecated() VersionList {
+	var result VersionList
+	for _, ver := range v {
+		if ver.Deprecation != nil {
+			result = append(result, ver)
+		}
+	}
+	return result
+}
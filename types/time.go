@@ -0,0 +1,25 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package types
+
+import (
+	"time"
+)
+
+// OptionalTime returns a pointer to the given time, or nil if it is the zero time. Optional times are stored as
+// pointers so they can be left out of the JSON files.
+func OptionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// EqualTimes returns true if both times are nil or represent the same instant.
+func EqualTimes(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
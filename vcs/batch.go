@@ -0,0 +1,23 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package vcs
+
+import (
+	"context"
+)
+
+// BatchTagLister is implemented by VCS clients that can list the tags of many repositories with a few requests.
+// Callers should type-assert the Client for this interface and fall back to ListAllTags if it is not implemented.
+type BatchTagLister interface {
+	// ListAllTagsBatch lists all tags of the given repositories. The result contains an entry for every repository,
+	// which holds either the tags or the error that occurred for that repository, such as a
+	// *RepositoryNotFoundError. The returned error is only set if the batch failed as a whole.
+	ListAllTagsBatch(ctx context.Context, repositories []RepositoryAddr) (map[RepositoryAddr]BatchTagResult, error)
+}
+
+// BatchTagResult is the result of listing the tags of a single repository in a batch.
+type BatchTagResult struct {
+	Tags []Version
+	Err  error
+}
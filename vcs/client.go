@@ -56,6 +56,10 @@ type Client interface {
 	// DownloadAsset downloads a given asset from a release in a repository.
 	DownloadAsset(ctx context.Context, repository RepositoryAddr, version VersionNumber, asset AssetName) ([]byte, error)
 
+	// GetAssetDownloadURL returns the public URL a given asset of a release can be downloaded from. The existence of
+	// the asset is not verified.
+	GetAssetDownloadURL(ctx context.Context, repository RepositoryAddr, version VersionNumber, asset AssetName) (string, error)
+
 	// HasPermission returns true if the user has permission to act on behalf of an organization.
 	HasPermission(ctx context.Context, username Username, organization OrganizationAddr) (bool, error)
 
@@ -106,4 +110,6 @@ type RepositoryInfo struct {
 	ForkOf *RepositoryAddr `json:"fork_of,omitempty"`
 	// ForkCount exposes the amount of copies/forks present in the VCS.
 	ForkCount int
+	// Archived indicates that the repository is read-only and no longer maintained.
+	Archived bool `json:"archived,omitempty"`
 }
@@ -3,6 +3,8 @@
 
 package vcs
 
+import "time"
+
 type RequestFailedError struct {
 	Cause error
 	Body  []byte
@@ -24,3 +26,31 @@ type NoWebAccessError struct{}
 func (r NoWebAccessError) Error() string {
 	return "The VCS system does not support web access."
 }
+
+// NotSupportedError indicates that the VCS system does not support the requested operation, for example listing
+// releases on a plain git server.
+type NotSupportedError struct {
+	Operation string
+}
+
+func (r NotSupportedError) Error() string {
+	return "The VCS system does not support " + r.Operation + "."
+}
+
+// RateLimitedError indicates that the VCS system rejected a request because the rate limit was exceeded. Requests
+// can be retried after ResetAt.
+type RateLimitedError struct {
+	ResetAt time.Time
+	Cause   error
+}
+
+func (r RateLimitedError) Error() string {
+	if r.Cause != nil {
+		return "VCS rate limit exceeded, resets at " + r.ResetAt.Format(time.RFC3339) + " (" + r.Cause.Error() + ")"
+	}
+	return "VCS rate limit exceeded, resets at " + r.ResetAt.Format(time.RFC3339)
+}
+
+func (r RateLimitedError) Unwrap() error {
+	return r.Cause
+}
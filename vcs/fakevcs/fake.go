@@ -61,8 +61,15 @@ type VCSClient interface {
 	vcs.Client
 
 	CreateOrganization(organization vcs.OrganizationAddr) error
+	// CreateRepository creates a repository with the given info. If the info marks the repository as a fork of an
+	// existing repository, the tags of the parent are copied.
 	CreateRepository(repository vcs.RepositoryAddr, info vcs.RepositoryInfo) error
 	CreateVersion(repository vcs.RepositoryAddr, version vcs.VersionNumber, content fs.ReadDirFS) error
+	// DeleteVersion removes the tag of a version, as if it had been deleted upstream.
+	DeleteVersion(repository vcs.RepositoryAddr, version vcs.VersionNumber) error
+	// MoveVersion points an existing version to a new commit with the given contents, as if the tag had been
+	// force-pushed.
+	MoveVersion(repository vcs.RepositoryAddr, version vcs.VersionNumber, content fs.ReadDirFS) error
 	AddAsset(repository vcs.RepositoryAddr, version vcs.VersionNumber, name vcs.AssetName, data []byte) error
 	AddUser(username vcs.Username) error
 	AddMember(organization vcs.OrganizationAddr, username vcs.Username) error
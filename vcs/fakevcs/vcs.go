@@ -5,6 +5,7 @@ package fakevcs
 
 import (
 	"context"
+	"crypto/sha1" //nolint:gosec // Only used to generate fake commit IDs.
 	"fmt"
 	"io/fs"
 	"strings"
@@ -16,6 +17,7 @@ type inMemoryVCS struct {
 	config        Config
 	users         map[vcs.Username]struct{}
 	organizations map[vcs.OrganizationAddr]*org
+	commits       int
 }
 
 func (i *inMemoryVCS) GetTagVersion(ctx context.Context, repositoryAddr vcs.RepositoryAddr, version vcs.VersionNumber) (vcs.Version, error) {
@@ -41,6 +43,7 @@ func (i *inMemoryVCS) GetTagVersion(ctx context.Context, repositoryAddr vcs.Repo
 			return vcs.Version{
 				VersionNumber: ver.name,
 				Created:       ver.created,
+				Commit:        ver.commit,
 			}, nil
 		}
 	}
@@ -110,6 +113,7 @@ func (i *inMemoryVCS) ListAllReleases(_ context.Context, repositoryAddr vcs.Repo
 		result[i] = vcs.Version{
 			VersionNumber: ver.name,
 			Created:       ver.created,
+			Commit:        ver.commit,
 		}
 	}
 	return result, nil
@@ -160,6 +164,7 @@ func (i *inMemoryVCS) ListAllTags(_ context.Context, repositoryAddr vcs.Reposito
 		result[i] = vcs.Version{
 			VersionNumber: ver.name,
 			Created:       ver.created,
+			Commit:        ver.commit,
 		}
 	}
 	return result, nil
@@ -237,6 +242,19 @@ func (i *inMemoryVCS) DownloadAsset(_ context.Context, repositoryAddr vcs.Reposi
 	}
 }
 
+func (i *inMemoryVCS) GetAssetDownloadURL(_ context.Context, repositoryAddr vcs.RepositoryAddr, version vcs.VersionNumber, asset vcs.AssetName) (string, error) {
+	if err := repositoryAddr.Validate(); err != nil {
+		return "", err
+	}
+	if err := version.Validate(); err != nil {
+		return "", err
+	}
+	if err := asset.Validate(); err != nil {
+		return "", err
+	}
+	return "https://localhost/" + repositoryAddr.String() + "/releases/download/" + string(version) + "/" + string(asset), nil
+}
+
 func (i *inMemoryVCS) HasPermission(_ context.Context, username vcs.Username, organization vcs.OrganizationAddr) (bool, error) {
 	if err := organization.Validate(); err != nil {
 		return false, err
@@ -282,7 +300,22 @@ func (i *inMemoryVCS) CreateRepository(repositoryAddr vcs.RepositoryAddr, reposi
 			repositoryAddr,
 		}
 	}
-	i.organizations[repositoryAddr.Org].repositories[repositoryAddr] = &repository{}
+	repo := &repository{
+		info: repositoryInfo,
+	}
+	if repositoryInfo.ForkOf != nil {
+		// Like a real fork, the fork starts out with the tags of its parent pointing to the same commits. Release
+		// assets are not copied.
+		if parentOrg, ok := i.organizations[repositoryInfo.ForkOf.Org]; ok {
+			if parent, ok := parentOrg.repositories[*repositoryInfo.ForkOf]; ok {
+				for _, ver := range parent.versions {
+					ver.assets = map[vcs.AssetName][]byte{}
+					repo.versions = append(repo.versions, ver)
+				}
+			}
+		}
+	}
+	i.organizations[repositoryAddr.Org].repositories[repositoryAddr] = repo
 	return nil
 }
 
@@ -317,6 +350,7 @@ func (i *inMemoryVCS) CreateVersion(repositoryAddr vcs.RepositoryAddr, versionNa
 		{
 			name:     versionName,
 			created:  i.config.TimeSource(),
+			commit:   i.newCommit(repositoryAddr, versionName),
 			assets:   map[vcs.AssetName][]byte{},
 			contents: contents,
 		},
@@ -324,6 +358,58 @@ func (i *inMemoryVCS) CreateVersion(repositoryAddr vcs.RepositoryAddr, versionNa
 	return nil
 }
 
+func (i *inMemoryVCS) DeleteVersion(repositoryAddr vcs.RepositoryAddr, versionName vcs.VersionNumber) error {
+	repo, index, err := i.findVersion(repositoryAddr, versionName)
+	if err != nil {
+		return err
+	}
+	repo.versions = append(repo.versions[:index], repo.versions[index+1:]...)
+	return nil
+}
+
+func (i *inMemoryVCS) MoveVersion(repositoryAddr vcs.RepositoryAddr, versionName vcs.VersionNumber, contents fs.ReadDirFS) error {
+	repo, index, err := i.findVersion(repositoryAddr, versionName)
+	if err != nil {
+		return err
+	}
+	repo.versions[index].commit = i.newCommit(repositoryAddr, versionName)
+	repo.versions[index].contents = contents
+	return nil
+}
+
+func (i *inMemoryVCS) findVersion(repositoryAddr vcs.RepositoryAddr, versionName vcs.VersionNumber) (*repository, int, error) {
+	if err := repositoryAddr.Validate(); err != nil {
+		return nil, 0, err
+	}
+	org, ok := i.organizations[repositoryAddr.Org]
+	if !ok {
+		return nil, 0, &vcs.RepositoryNotFoundError{
+			RepositoryAddr: repositoryAddr,
+		}
+	}
+	repo, ok := org.repositories[repositoryAddr]
+	if !ok {
+		return nil, 0, &vcs.RepositoryNotFoundError{
+			RepositoryAddr: repositoryAddr,
+		}
+	}
+	for index, ver := range repo.versions {
+		if ver.name == versionName {
+			return repo, index, nil
+		}
+	}
+	return nil, 0, &vcs.VersionNotFoundError{
+		RepositoryAddr: repositoryAddr,
+		Version:        versionName,
+	}
+}
+
+// newCommit returns a unique, SHA-like commit ID for a version.
+func (i *inMemoryVCS) newCommit(repositoryAddr vcs.RepositoryAddr, versionName vcs.VersionNumber) string {
+	i.commits++
+	return fmt.Sprintf("%x", sha1.Sum([]byte(fmt.Sprintf("%s@%s#%d", repositoryAddr, versionName, i.commits))))
+}
+
 func (i *inMemoryVCS) AddAsset(repositoryAddr vcs.RepositoryAddr, versionName vcs.VersionNumber, assetName vcs.AssetName, assetData []byte) error {
 	if err := repositoryAddr.Validate(); err != nil {
 		return err
@@ -0,0 +1,116 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opentofu/libregistry/internal/gitcli"
+	"github.com/opentofu/libregistry/logger"
+)
+
+// OrgPlaceholder is replaced with the organization in the URL template.
+const OrgPlaceholder = "{org}"
+
+// RepoPlaceholder is replaced with the repository name in the URL template.
+const RepoPlaceholder = "{repo}"
+
+// Opt is a function that modifies the config.
+type Opt func(config *Config) error
+
+// Config holds the configuration for the plain git client.
+type Config struct {
+	// URLTemplate is the template for the remote URL of a repository, for example
+	// https://git.example.com/{org}/{repo}.git or file:///srv/git/{org}/{repo}.git. There is no default, the template
+	// must always be set.
+	URLTemplate string
+	// CheckoutRootDirectory is the root directory where repositories should be checked out. Defaults to the OS' temp
+	// directory.
+	CheckoutRootDirectory string
+	// SkipCleanupWorkingCopyOnClose indicates that the working copy should not be cleaned up when it is closed.
+	// Defaults to false, cleaning up the working copy.
+	SkipCleanupWorkingCopyOnClose bool
+	// GitPath holds the path to the git binary. Defaults to looking up the "git" or "git.exe" binaries in the path.
+	GitPath string
+
+	// Logger holds the logger to write any logs to.
+	Logger logger.Logger
+}
+
+// ApplyDefaults adds the default values if none are present.
+func (c *Config) ApplyDefaults() {
+	if c.CheckoutRootDirectory == "" {
+		c.CheckoutRootDirectory = os.TempDir()
+	}
+
+	if c.GitPath == "" {
+		c.GitPath = gitcli.DefaultGitPath
+	}
+
+	if c.Logger == nil {
+		c.Logger = logger.NewNoopLogger()
+	}
+}
+
+// WithURLTemplate sets the template for the remote URL of a repository. The template must contain the {org} and
+// {repo} placeholders.
+func WithURLTemplate(template string) Opt {
+	return func(config *Config) error {
+		if !strings.Contains(template, OrgPlaceholder) || !strings.Contains(template, RepoPlaceholder) {
+			return fmt.Errorf("invalid git URL template: %s (the template must contain %s and %s)", template, OrgPlaceholder, RepoPlaceholder)
+		}
+		config.URLTemplate = template
+		return nil
+	}
+}
+
+// WithCheckoutRootDirectory sets a directory to use for repository checkouts.
+func WithCheckoutRootDirectory(rootDir string) Opt {
+	return func(config *Config) error {
+		stat, err := os.Stat(rootDir)
+		if err != nil {
+			return fmt.Errorf("unusable checkout root directory (%w)", err)
+		}
+		if !stat.IsDir() {
+			return fmt.Errorf("unusable checkout root directory (not a directory)")
+		}
+		rootDir, err = filepath.Abs(rootDir)
+		if err != nil {
+			return fmt.Errorf("failed to determine absolute path for %s (%v)", rootDir, err)
+		}
+		config.CheckoutRootDirectory = rootDir
+		return nil
+	}
+}
+
+// WithSkipCleanupWorkingCopyOnClose skips cleaning up the working directory when it is closed. This is useful when
+// wanting to re-use the working directory and skip re-cloning the repository.
+func WithSkipCleanupWorkingCopyOnClose(skip bool) Opt {
+	return func(config *Config) error {
+		config.SkipCleanupWorkingCopyOnClose = skip
+		return nil
+	}
+}
+
+// WithGitPath sets the path to the Git binary. Defaults to looking up the "git" or "git.exe" binaries in the path.
+func WithGitPath(path string) Opt {
+	return func(config *Config) error {
+		if err := gitcli.ValidateGitPath(path); err != nil {
+			return err
+		}
+		config.GitPath = path
+		return nil
+	}
+}
+
+// WithLogger sets a logger to use for writing trace and debug information.
+func WithLogger(logger logger.Logger) Opt {
+	return func(config *Config) error {
+		config.Logger = logger.WithName("Git")
+		return nil
+	}
+}
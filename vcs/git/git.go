@@ -0,0 +1,225 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package git implements the vcs.Client interface for plain git remotes without a hosting API. Tags are listed using
+// git ls-remote and checkouts are performed by cloning. Releases, assets, permissions and web access are not
+// supported.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/opentofu/libregistry/internal/gitcli"
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// New creates a new plain git VCS client. The WithURLTemplate option is required.
+func New(
+	options ...Opt,
+) (vcs.Client, error) {
+	config := Config{}
+	for _, opt := range options {
+		if err := opt(&config); err != nil {
+			return nil, err
+		}
+	}
+	config.ApplyDefaults()
+	if config.URLTemplate == "" {
+		return nil, fmt.Errorf("no git URL template configured")
+	}
+
+	return &client{
+		config: config,
+		git: gitcli.New(gitcli.Config{
+			GitPath:                       config.GitPath,
+			CheckoutRootDirectory:         config.CheckoutRootDirectory,
+			SkipCleanupWorkingCopyOnClose: config.SkipCleanupWorkingCopyOnClose,
+			Logger:                        config.Logger,
+		}),
+	}, nil
+}
+
+type client struct {
+	config Config
+	git    *gitcli.Git
+}
+
+func (c client) ParseRepositoryAddr(ref string) (vcs.RepositoryAddr, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 {
+		return vcs.RepositoryAddr{}, &vcs.InvalidRepositoryAddrError{
+			RepositoryString: ref,
+		}
+	}
+	result := vcs.RepositoryAddr{
+		Org:  vcs.OrganizationAddr(parts[0]),
+		Name: parts[1],
+	}
+	return result, result.Validate()
+}
+
+// GetRepositoryInfo returns an empty repository info after checking that the repository exists since plain git
+// remotes carry no repository metadata.
+func (c client) GetRepositoryInfo(ctx context.Context, repository vcs.RepositoryAddr) (vcs.RepositoryInfo, error) {
+	if _, err := c.lsRemoteTags(ctx, repository); err != nil {
+		return vcs.RepositoryInfo{}, err
+	}
+	return vcs.RepositoryInfo{}, nil
+}
+
+// ListLatestTags returns all tags since git ls-remote cannot limit the output. The tags do not contain a creation
+// date, use GetTagVersion to get it.
+func (c client) ListLatestTags(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	return c.ListAllTags(ctx, repository)
+}
+
+// ListAllTags returns all tags using git ls-remote. The tags do not contain a creation date, use GetTagVersion to get
+// it.
+func (c client) ListAllTags(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	logger.LogTrace(ctx, c.config.Logger, "Listing all tags for repository %s...", repository)
+	tags, err := c.lsRemoteTags(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	var result []vcs.Version
+	for _, tag := range tags {
+		if err := tag.Validate(); err != nil {
+			c.config.Logger.Debug(ctx, "Skipping tag %s in repository %s because it does not match the naming rules.", tag.VersionNumber, repository)
+			continue
+		}
+		result = append(result, tag)
+	}
+	return result, nil
+}
+
+// GetTagVersion checks the tag using git ls-remote and reads the creation date of the tag from a clone of the
+// repository.
+func (c client) GetTagVersion(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (vcs.Version, error) {
+	if err := version.Validate(); err != nil {
+		return vcs.Version{}, err
+	}
+	tags, err := c.lsRemoteTags(ctx, repository)
+	if err != nil {
+		return vcs.Version{}, err
+	}
+	found := false
+	for _, tag := range tags {
+		if tag.VersionNumber.Equals(version) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return vcs.Version{}, &vcs.VersionNotFoundError{
+			RepositoryAddr: repository,
+			Version:        version,
+		}
+	}
+
+	wc, err := c.git.Open(ctx, c, repository, c.remoteURL(repository), nil)
+	if err != nil {
+		return vcs.Version{}, err
+	}
+	defer func() {
+		_ = wc.Close()
+	}()
+	return wc.GetTag(ctx, version)
+}
+
+func (c client) ListLatestReleases(_ context.Context, _ vcs.RepositoryAddr) ([]vcs.Version, error) {
+	return nil, &vcs.NotSupportedError{Operation: "releases"}
+}
+
+func (c client) ListAllReleases(_ context.Context, _ vcs.RepositoryAddr) ([]vcs.Version, error) {
+	return nil, &vcs.NotSupportedError{Operation: "releases"}
+}
+
+func (c client) ListAssets(_ context.Context, _ vcs.RepositoryAddr, _ vcs.VersionNumber) ([]vcs.AssetName, error) {
+	return nil, &vcs.NotSupportedError{Operation: "release assets"}
+}
+
+func (c client) DownloadAsset(_ context.Context, _ vcs.RepositoryAddr, _ vcs.VersionNumber, _ vcs.AssetName) ([]byte, error) {
+	return nil, &vcs.NotSupportedError{Operation: "release assets"}
+}
+
+func (c client) GetAssetDownloadURL(_ context.Context, _ vcs.RepositoryAddr, _ vcs.VersionNumber, _ vcs.AssetName) (string, error) {
+	return "", &vcs.NotSupportedError{Operation: "release assets"}
+}
+
+func (c client) HasPermission(_ context.Context, _ vcs.Username, _ vcs.OrganizationAddr) (bool, error) {
+	return false, &vcs.NotSupportedError{Operation: "permission checks"}
+}
+
+func (c client) Checkout(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (vcs.WorkingCopy, error) {
+	if err := repository.Validate(); err != nil {
+		return nil, err
+	}
+	return c.git.Checkout(ctx, c, repository, c.remoteURL(repository), nil, version)
+}
+
+func (c client) GetRepositoryBrowseURL(_ context.Context, _ vcs.RepositoryAddr) (string, error) {
+	return "", &vcs.NoWebAccessError{}
+}
+
+func (c client) GetVersionBrowseURL(_ context.Context, _ vcs.RepositoryAddr, _ vcs.VersionNumber) (string, error) {
+	return "", &vcs.NoWebAccessError{}
+}
+
+func (c client) GetFileViewURL(_ context.Context, _ vcs.RepositoryAddr, _ vcs.VersionNumber, _ string) (string, error) {
+	return "", &vcs.NoWebAccessError{}
+}
+
+func (c client) remoteURL(repository vcs.RepositoryAddr) string {
+	return strings.NewReplacer(
+		OrgPlaceholder, string(repository.Org),
+		RepoPlaceholder, repository.Name,
+	).Replace(c.config.URLTemplate)
+}
+
+// lsRemoteTags returns the names of all tags in the remote repository.
+func (c client) lsRemoteTags(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	if err := repository.Validate(); err != nil {
+		return nil, err
+	}
+	stdout := &bytes.Buffer{}
+	if err := c.git.Run(ctx, c.config.CheckoutRootDirectory, stdout, "ls-remote", "--tags", c.remoteURL(repository)); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 128 {
+			return nil, &vcs.RepositoryNotFoundError{RepositoryAddr: repository, Cause: err}
+		}
+		return nil, err
+	}
+
+	var result []vcs.Version
+	indexes := map[vcs.VersionNumber]int{}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("failed to parse git ls-remote output: %s", line)
+		}
+		tag := vcs.VersionNumber(strings.TrimPrefix(parts[1], "refs/tags/"))
+		// Annotated tags are listed a second time with the ^{} suffix pointing to the tagged commit.
+		if peeled, ok := strings.CutSuffix(string(tag), "^{}"); ok {
+			if i, ok := indexes[vcs.VersionNumber(peeled)]; ok {
+				result[i].Commit = parts[0]
+			}
+			continue
+		}
+		indexes[tag] = len(result)
+		result = append(result, vcs.Version{
+			VersionNumber: tag,
+			Commit:        parts[0],
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,137 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package git_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opentofu/libregistry/internal/gittest"
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/git"
+)
+
+var testRepo = vcs.RepositoryAddr{
+	Org:  "opentofu",
+	Name: "terraform-aws-test",
+}
+
+func newTestClient(t *testing.T) vcs.Client {
+	t.Helper()
+	root := t.TempDir()
+	gittest.CreateRepository(t, root, testRepo, map[string]string{
+		"main.tf": "# Test module",
+	}, "v1.0.0", "v1.1.0")
+	// Add an annotated tag as well, which ls-remote lists twice.
+	gittest.Run(t, filepath.Join(root, "opentofu", "terraform-aws-test.git"), "tag", "-a", "-m", "Release v1.2.0", "v1.2.0", "v1.1.0")
+
+	client, err := git.New(
+		git.WithURLTemplate("file://"+filepath.ToSlash(root)+"/{org}/{repo}.git"),
+		git.WithCheckoutRootDirectory(t.TempDir()),
+		git.WithLogger(logger.NewTestLogger(t)),
+	)
+	if err != nil {
+		t.Fatalf("❌ Failed to initialize git client (%v)", err)
+	}
+	return client
+}
+
+func TestConfig(t *testing.T) {
+	if _, err := git.New(); err == nil {
+		t.Fatalf("❌ Creating a git client without a URL template did not fail.")
+	}
+	if _, err := git.New(git.WithURLTemplate("https://git.example.com/repo.git")); err == nil {
+		t.Fatalf("❌ Creating a git client with a URL template without placeholders did not fail.")
+	}
+}
+
+func TestTags(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	tags, err := client.ListAllTags(ctx, testRepo)
+	if err != nil {
+		t.Fatalf("❌ Failed to list tags (%v)", err)
+	}
+	if len(tags) != 3 {
+		t.Fatalf("❌ Incorrect number of tags returned: %v", tags)
+	}
+	for i, expected := range []vcs.VersionNumber{"v1.0.0", "v1.1.0", "v1.2.0"} {
+		if tags[i].VersionNumber != expected {
+			t.Fatalf("❌ Incorrect tag at position %d: %s (expected: %s)", i, tags[i].VersionNumber, expected)
+		}
+	}
+
+	ver, err := client.GetTagVersion(ctx, testRepo, "v1.2.0")
+	if err != nil {
+		t.Fatalf("❌ Failed to get tag version (%v)", err)
+	}
+	if ver.Created.IsZero() {
+		t.Fatalf("❌ No creation date returned for tag %s.", ver.VersionNumber)
+	}
+	if ver.Commit == "" || ver.Commit != tags[2].Commit {
+		t.Fatalf("❌ Incorrect commit returned for tag %s: %s (expected: %s)", ver.VersionNumber, ver.Commit, tags[2].Commit)
+	}
+
+	_, err = client.GetTagVersion(ctx, testRepo, "v2.0.0")
+	var versionNotFound *vcs.VersionNotFoundError
+	if !errors.As(err, &versionNotFound) {
+		t.Fatalf("❌ Expected a VersionNotFoundError, got: %v", err)
+	}
+
+	_, err = client.ListAllTags(ctx, vcs.RepositoryAddr{Org: "opentofu", Name: "nonexistent"})
+	var repoNotFound *vcs.RepositoryNotFoundError
+	if !errors.As(err, &repoNotFound) {
+		t.Fatalf("❌ Expected a RepositoryNotFoundError, got: %v", err)
+	}
+}
+
+func TestCheckout(t *testing.T) {
+	client := newTestClient(t)
+
+	wc, err := client.Checkout(context.Background(), testRepo, "v1.0.0")
+	if err != nil {
+		t.Fatalf("❌ Failed to check out repository (%v)", err)
+	}
+	defer func() {
+		if err := wc.Close(); err != nil {
+			t.Fatalf("❌ Failed to close working copy (%v)", err)
+		}
+	}()
+	dir, err := wc.RawDirectory()
+	if err != nil {
+		t.Fatalf("❌ Failed to get working copy directory (%v)", err)
+	}
+	contents, err := os.ReadFile(filepath.Join(dir, "main.tf"))
+	if err != nil {
+		t.Fatalf("❌ Failed to read checked out file (%v)", err)
+	}
+	if string(contents) != "# Test module" {
+		t.Fatalf("❌ Incorrect file contents: %s", contents)
+	}
+}
+
+func TestNotSupported(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	var notSupported *vcs.NotSupportedError
+	if _, err := client.ListAllReleases(ctx, testRepo); !errors.As(err, &notSupported) {
+		t.Fatalf("❌ Expected a NotSupportedError for releases, got: %v", err)
+	}
+	if _, err := client.ListAssets(ctx, testRepo, "v1.0.0"); !errors.As(err, &notSupported) {
+		t.Fatalf("❌ Expected a NotSupportedError for assets, got: %v", err)
+	}
+	if _, err := client.HasPermission(ctx, "janedoe", "opentofu"); !errors.As(err, &notSupported) {
+		t.Fatalf("❌ Expected a NotSupportedError for permissions, got: %v", err)
+	}
+	var noWebAccess *vcs.NoWebAccessError
+	if _, err := client.GetRepositoryBrowseURL(ctx, testRepo); !errors.As(err, &noWebAccess) {
+		t.Fatalf("❌ Expected a NoWebAccessError, got: %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package gitea
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opentofu/libregistry/internal/gitcli"
+	"github.com/opentofu/libregistry/logger"
+)
+
+// Opt is a function that modifies the config.
+type Opt func(config *Config) error
+
+// Config holds the configuration for Gitea.
+type Config struct {
+	// BaseURL is the web address of the Gitea or Forgejo instance, without a trailing slash. The API is expected at
+	// BaseURL/api/v1. There is no default, the base URL must always be set.
+	BaseURL string
+	// Token is the Gitea access token to use when accessing the Gitea API and cloning.
+	Token string
+	// CheckoutRootDirectory is the root directory where repositories should be checked out. Defaults to the OS' temp
+	// directory.
+	CheckoutRootDirectory string
+	// SkipCleanupWorkingCopyOnClose indicates that the working copy should not be cleaned up when it is closed.
+	// Defaults to false, cleaning up the working copy.
+	SkipCleanupWorkingCopyOnClose bool
+	// GitPath holds the path to the git binary. Defaults to looking up the "git" or "git.exe" binaries in the path.
+	GitPath string
+
+	// DisableFeeds disables using the Atom feeds for ListLatestTags and uses the API instead. This is useful for
+	// private repositories, as the feeds do not accept access tokens.
+	DisableFeeds bool
+
+	// Logger holds the logger to write any logs to.
+	Logger logger.Logger
+	// HTTPClient holds the HTTP client to use for API requests. Note that this only affects API requests, but not git
+	// clone commands as those are done using the command line.
+	HTTPClient *http.Client
+}
+
+// ApplyDefaults adds the default values if none are present.
+func (c *Config) ApplyDefaults() {
+	if c.CheckoutRootDirectory == "" {
+		c.CheckoutRootDirectory = os.TempDir()
+	}
+
+	if c.GitPath == "" {
+		c.GitPath = gitcli.DefaultGitPath
+	}
+
+	if c.Logger == nil {
+		c.Logger = logger.NewNoopLogger()
+	}
+
+	if c.HTTPClient == nil {
+		c.HTTPClient = http.DefaultClient
+	}
+}
+
+// WithBaseURL sets the web address of the Gitea or Forgejo instance, for example https://codeberg.org.
+func WithBaseURL(baseURL string) Opt {
+	return func(config *Config) error {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return fmt.Errorf("invalid Gitea base URL: %s (%w)", baseURL, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("invalid Gitea base URL: %s (the scheme must be http or https)", baseURL)
+		}
+		config.BaseURL = strings.TrimSuffix(baseURL, "/")
+		return nil
+	}
+}
+
+// WithToken sets the Gitea access token to use for authentication against the API and for cloning.
+func WithToken(token string) Opt {
+	return func(config *Config) error {
+		config.Token = token
+		return nil
+	}
+}
+
+// WithCheckoutRootDirectory sets a directory to use for repository checkouts.
+func WithCheckoutRootDirectory(rootDir string) Opt {
+	return func(config *Config) error {
+		stat, err := os.Stat(rootDir)
+		if err != nil {
+			return fmt.Errorf("unusable checkout root directory (%w)", err)
+		}
+		if !stat.IsDir() {
+			return fmt.Errorf("unusable checkout root directory (not a directory)")
+		}
+		rootDir, err = filepath.Abs(rootDir)
+		if err != nil {
+			return fmt.Errorf("failed to determine absolute path for %s (%v)", rootDir, err)
+		}
+		config.CheckoutRootDirectory = rootDir
+		return nil
+	}
+}
+
+// WithSkipCleanupWorkingCopyOnClose skips cleaning up the working directory when it is closed. This is useful when
+// wanting to re-use the working directory and skip re-cloning the repository.
+func WithSkipCleanupWorkingCopyOnClose(skip bool) Opt {
+	return func(config *Config) error {
+		config.SkipCleanupWorkingCopyOnClose = skip
+		return nil
+	}
+}
+
+// WithGitPath sets the path to the Git binary. Defaults to looking up the "git" or "git.exe" binaries in the path.
+func WithGitPath(path string) Opt {
+	return func(config *Config) error {
+		if err := gitcli.ValidateGitPath(path); err != nil {
+			return err
+		}
+		config.GitPath = path
+		return nil
+	}
+}
+
+// WithDisableFeeds disables using the Atom feeds for listing the latest tags.
+func WithDisableFeeds(disable bool) Opt {
+	return func(config *Config) error {
+		config.DisableFeeds = disable
+		return nil
+	}
+}
+
+// WithLogger sets a logger to use for writing trace and debug information.
+func WithLogger(logger logger.Logger) Opt {
+	return func(config *Config) error {
+		config.Logger = logger.WithName("Gitea")
+		return nil
+	}
+}
+
+// WithHTTPClient sets an HTTP client to use for API queries.
+func WithHTTPClient(client *http.Client) Opt {
+	return func(config *Config) error {
+		config.HTTPClient = client
+		return nil
+	}
+}
@@ -0,0 +1,642 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package gitea implements the vcs.Client interface on top of the Gitea v1 API. Forgejo speaks the same API, so this
+// client works with Forgejo instances, such as Codeberg, as well.
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentofu/libregistry/internal/gitcli"
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// pageSize is the number of items requested per page. Gitea limits the page size to 50 items by default.
+const pageSize = 50
+
+// New creates a new Gitea VCS client. The WithBaseURL option is required.
+func New(
+	options ...Opt,
+) (vcs.Client, error) {
+	config := Config{}
+	for _, opt := range options {
+		if err := opt(&config); err != nil {
+			return nil, err
+		}
+	}
+	config.ApplyDefaults()
+	if config.BaseURL == "" {
+		return nil, fmt.Errorf("no Gitea base URL configured")
+	}
+
+	return &gitea{
+		config: config,
+		git: gitcli.New(gitcli.Config{
+			GitPath:                       config.GitPath,
+			CheckoutRootDirectory:         config.CheckoutRootDirectory,
+			SkipCleanupWorkingCopyOnClose: config.SkipCleanupWorkingCopyOnClose,
+			Logger:                        config.Logger,
+		}),
+	}, nil
+}
+
+type gitea struct {
+	config Config
+	git    *gitcli.Git
+}
+
+func (g gitea) ParseRepositoryAddr(ref string) (vcs.RepositoryAddr, error) {
+	ref = strings.TrimPrefix(ref, g.config.BaseURL+"/")
+	if baseURL, err := url.Parse(g.config.BaseURL); err == nil {
+		ref = strings.TrimPrefix(ref, baseURL.Host+"/")
+	}
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 {
+		return vcs.RepositoryAddr{}, &vcs.InvalidRepositoryAddrError{
+			RepositoryString: ref,
+		}
+	}
+	result := vcs.RepositoryAddr{
+		Org:  vcs.OrganizationAddr(parts[0]),
+		Name: parts[1],
+	}
+	return result, result.Validate()
+}
+
+func (g gitea) GetRepositoryInfo(ctx context.Context, repository vcs.RepositoryAddr) (vcs.RepositoryInfo, error) {
+	if err := repository.Validate(); err != nil {
+		return vcs.RepositoryInfo{}, err
+	}
+	type repoResponse struct {
+		Description string `json:"description"`
+		StarsCount  int    `json:"stars_count"`
+		ForksCount  int    `json:"forks_count"`
+		Fork        bool   `json:"fork"`
+		Archived    bool   `json:"archived"`
+		Parent      *struct {
+			FullName string `json:"full_name"`
+		} `json:"parent"`
+	}
+
+	var response repoResponse
+	if err := g.request(ctx, g.repoURL(repository), &response); err != nil {
+		return vcs.RepositoryInfo{}, g.repositoryError(repository, err)
+	}
+
+	repoInfo := vcs.RepositoryInfo{
+		Description: response.Description,
+		Popularity:  response.StarsCount,
+		ForkCount:   response.ForksCount,
+		Archived:    response.Archived,
+	}
+	if response.Fork && response.Parent != nil {
+		parent, err := g.ParseRepositoryAddr(response.Parent.FullName)
+		if err != nil {
+			return vcs.RepositoryInfo{}, fmt.Errorf("invalid parent repository %s for %s (%w)", response.Parent.FullName, repository, err)
+		}
+		repoInfo.ForkOf = &parent
+	}
+	return repoInfo, nil
+}
+
+type atomFeed struct {
+	Entry []struct {
+		Title   string `xml:"title"`
+		Updated string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+func (g gitea) ListLatestTags(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	logger.LogTrace(ctx, g.config.Logger, "Requesting latest tags for repository %s...", repository)
+	if err := repository.Validate(); err != nil {
+		return nil, err
+	}
+	if !g.config.DisableFeeds {
+		result, err := g.listLatestTagsFromFeed(ctx, repository)
+		if err == nil {
+			return result, nil
+		}
+		// The feed is not available for private repositories and on instances with feeds disabled, fall back to the
+		// API in this case.
+		g.config.Logger.Debug(ctx, "Failed to fetch the tags feed for repository %s, falling back to the API (%v)", repository, err)
+	}
+	return g.listTags(ctx, repository, false)
+}
+
+func (g gitea) listLatestTagsFromFeed(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	feedURL := g.webURL(repository) + "/tags.atom"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, &vcs.RequestFailedError{
+			Cause: fmt.Errorf("invalid HTTP request (%w)", err),
+		}
+	}
+	logger.LogTrace(ctx, g.config.Logger, "Sending GET request to %s...", feedURL)
+	resp, err := g.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, &vcs.RequestFailedError{
+			Cause: err,
+		}
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &vcs.RequestFailedError{
+			Cause: &InvalidStatusCodeError{resp.StatusCode},
+			Body:  body,
+		}
+	}
+
+	decoder := xml.NewDecoder(resp.Body)
+	response := atomFeed{}
+	if err := decoder.Decode(&response); err != nil {
+		return nil, &vcs.RequestFailedError{
+			Cause: fmt.Errorf("failed to decode Atom feed (%w)", err),
+		}
+	}
+
+	var result []vcs.Version
+	for _, entry := range response.Entry {
+		versionNumber := vcs.VersionNumber(entry.Title)
+		if err := versionNumber.Validate(); err != nil {
+			g.config.Logger.Debug(ctx, "Skipping invalid version %s in the tags feed of repository %s", versionNumber, repository)
+			continue
+		}
+		versionCreated, err := time.Parse(time.RFC3339, entry.Updated)
+		if err != nil {
+			g.config.Logger.Debug(ctx, "Skipping invalid version creation time %s in the tags feed of repository %s", entry.Updated, repository)
+			continue
+		}
+		result = append(result, vcs.Version{
+			VersionNumber: versionNumber,
+			Created:       versionCreated,
+		})
+	}
+	return result, nil
+}
+
+func (g gitea) ListAllTags(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	logger.LogTrace(ctx, g.config.Logger, "Requesting all tags for repository %s...", repository)
+	if err := repository.Validate(); err != nil {
+		return nil, err
+	}
+	return g.listTags(ctx, repository, true)
+}
+
+type tagResponse struct {
+	Name   vcs.VersionNumber `json:"name"`
+	Commit struct {
+		SHA     string `json:"sha"`
+		Created string `json:"created"`
+	} `json:"commit"`
+}
+
+func (t tagResponse) toVersion() (vcs.Version, error) {
+	created, err := time.Parse(time.RFC3339, t.Commit.Created)
+	if err != nil {
+		return vcs.Version{}, err
+	}
+	return vcs.Version{
+		VersionNumber: t.Name,
+		Created:       created,
+		Commit:        t.Commit.SHA,
+	}, nil
+}
+
+func (g gitea) listTags(ctx context.Context, repository vcs.RepositoryAddr, all bool) ([]vcs.Version, error) {
+	var tags []tagResponse
+	if err := g.list(ctx, repository, g.repoURL(repository)+"/tags", all, &tags); err != nil {
+		return nil, err
+	}
+	var result []vcs.Version
+	for _, tag := range tags {
+		if err := tag.Name.Validate(); err != nil {
+			g.config.Logger.Debug(ctx, "Skipping invalid tag %s in repository %s", tag.Name, repository)
+			continue
+		}
+		ver, err := tag.toVersion()
+		if err != nil {
+			g.config.Logger.Debug(ctx, "Skipping tag %s with invalid creation date in repository %s (%v)", tag.Name, repository, err)
+			continue
+		}
+		result = append(result, ver)
+	}
+	return result, nil
+}
+
+func (g gitea) GetTagVersion(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (vcs.Version, error) {
+	if err := repository.Validate(); err != nil {
+		return vcs.Version{}, err
+	}
+	if err := version.Validate(); err != nil {
+		return vcs.Version{}, err
+	}
+	var tag tagResponse
+	if err := g.request(ctx, g.repoURL(repository)+"/tags/"+url.PathEscape(string(version)), &tag); err != nil {
+		return vcs.Version{}, g.versionError(repository, version, err)
+	}
+	ver, err := tag.toVersion()
+	if err != nil {
+		return vcs.Version{}, fmt.Errorf("invalid creation date for tag %s in repository %s (%w)", version, repository, err)
+	}
+	return ver, nil
+}
+
+type releaseResponse struct {
+	TagName     vcs.VersionNumber `json:"tag_name"`
+	Draft       bool              `json:"draft"`
+	PublishedAt string            `json:"published_at"`
+	Assets      []releaseAsset    `json:"assets"`
+}
+
+type releaseAsset struct {
+	Name               vcs.AssetName `json:"name"`
+	BrowserDownloadURL string        `json:"browser_download_url"`
+}
+
+func (g gitea) ListLatestReleases(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	logger.LogTrace(ctx, g.config.Logger, "Requesting latest releases for repository %s...", repository)
+	return g.listReleases(ctx, repository, false)
+}
+
+func (g gitea) ListAllReleases(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	logger.LogTrace(ctx, g.config.Logger, "Requesting all releases for repository %s...", repository)
+	return g.listReleases(ctx, repository, true)
+}
+
+func (g gitea) listReleases(ctx context.Context, repository vcs.RepositoryAddr, all bool) ([]vcs.Version, error) {
+	if err := repository.Validate(); err != nil {
+		return nil, err
+	}
+	var releases []releaseResponse
+	if err := g.list(ctx, repository, g.repoURL(repository)+"/releases", all, &releases); err != nil {
+		return nil, err
+	}
+	var result []vcs.Version
+	for _, release := range releases {
+		if release.Draft {
+			continue
+		}
+		if err := release.TagName.Validate(); err != nil {
+			g.config.Logger.Debug(ctx, "Skipping invalid release %s in repository %s", release.TagName, repository)
+			continue
+		}
+		created, err := time.Parse(time.RFC3339, release.PublishedAt)
+		if err != nil {
+			g.config.Logger.Debug(ctx, "Skipping invalid release creation date (%s) for %s in repository %s", release.PublishedAt, release.TagName, repository)
+			continue
+		}
+		result = append(result, vcs.Version{
+			VersionNumber: release.TagName,
+			Created:       created,
+		})
+	}
+	return result, nil
+}
+
+func (g gitea) getRelease(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (releaseResponse, error) {
+	if err := repository.Validate(); err != nil {
+		return releaseResponse{}, err
+	}
+	if err := version.Validate(); err != nil {
+		return releaseResponse{}, err
+	}
+	var release releaseResponse
+	if err := g.request(ctx, g.repoURL(repository)+"/releases/tags/"+url.PathEscape(string(version)), &release); err != nil {
+		return releaseResponse{}, g.versionError(repository, version, err)
+	}
+	return release, nil
+}
+
+func (g gitea) ListAssets(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) ([]vcs.AssetName, error) {
+	logger.LogTrace(ctx, g.config.Logger, "Listing assets for repository %s version %s", repository, version)
+	release, err := g.getRelease(ctx, repository, version)
+	if err != nil {
+		return nil, err
+	}
+	var result []vcs.AssetName
+	for _, asset := range release.Assets {
+		if err := asset.Name.Validate(); err != nil {
+			g.config.Logger.Debug(ctx, "Skipping invalid asset named %s in repository %s release %s", asset.Name, repository, version)
+			continue
+		}
+		result = append(result, asset.Name)
+	}
+	return result, nil
+}
+
+func (g gitea) DownloadAsset(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, asset vcs.AssetName) ([]byte, error) {
+	logger.LogTrace(ctx, g.config.Logger, "Downloading asset %s for repository %s version %s", asset, repository, version)
+	assetURL, err := g.GetAssetDownloadURL(ctx, repository, version, asset)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, assetURL, nil)
+	if err != nil {
+		return nil, &vcs.RequestFailedError{
+			Cause: fmt.Errorf("invalid HTTP request (%w)", err),
+		}
+	}
+	// Only send the token to the Gitea instance itself.
+	if g.config.Token != "" && strings.HasPrefix(assetURL, g.config.BaseURL+"/") {
+		req.Header.Set("Authorization", "token "+g.config.Token)
+	}
+	logger.LogTrace(ctx, g.config.Logger, "Sending GET request to %s...", assetURL)
+	resp, err := g.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, &vcs.RequestFailedError{
+			Cause: err,
+		}
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		err = &InvalidStatusCodeError{resp.StatusCode}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, &vcs.AssetNotFoundError{
+				RepositoryAddr: repository,
+				Version:        version,
+				Asset:          asset,
+				Cause:          err,
+			}
+		}
+		return nil, &vcs.RequestFailedError{
+			Cause: err,
+		}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &vcs.RequestFailedError{
+			Cause: err,
+		}
+	}
+	return body, nil
+}
+
+func (g gitea) GetAssetDownloadURL(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, asset vcs.AssetName) (string, error) {
+	if err := asset.Validate(); err != nil {
+		return "", err
+	}
+	release, err := g.getRelease(ctx, repository, version)
+	if err != nil {
+		return "", err
+	}
+	for _, releaseAsset := range release.Assets {
+		if releaseAsset.Name == asset {
+			return releaseAsset.BrowserDownloadURL, nil
+		}
+	}
+	return "", &vcs.AssetNotFoundError{
+		RepositoryAddr: repository,
+		Version:        version,
+		Asset:          asset,
+	}
+}
+
+func (g gitea) HasPermission(ctx context.Context, username vcs.Username, organization vcs.OrganizationAddr) (bool, error) {
+	if err := organization.Validate(); err != nil {
+		return false, err
+	}
+	if err := username.Validate(); err != nil {
+		return false, err
+	}
+	logger.LogTrace(ctx, g.config.Logger, "Checking if user %s has permissions for the organization %s...", username, organization)
+	orgURL := g.config.BaseURL + "/api/v1/orgs/" + url.PathEscape(string(organization))
+	var org any
+	if err := g.request(ctx, orgURL, &org); err != nil {
+		var statusCodeErr *InvalidStatusCodeError
+		if errors.As(err, &statusCodeErr) && statusCodeErr.StatusCode == http.StatusNotFound {
+			return false, &vcs.OrganizationNotFoundError{
+				OrganizationAddr: organization,
+				Cause:            err,
+			}
+		}
+		return false, err
+	}
+
+	// The membership endpoint returns 204 for members and 404 for everyone else.
+	statusCode, err := g.requestStatus(ctx, orgURL+"/members/"+url.PathEscape(string(username)))
+	if err != nil {
+		return false, err
+	}
+	switch statusCode {
+	case http.StatusNoContent:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, &vcs.RequestFailedError{
+			Cause: &InvalidStatusCodeError{statusCode},
+		}
+	}
+}
+
+func (g gitea) Checkout(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (vcs.WorkingCopy, error) {
+	if err := repository.Validate(); err != nil {
+		return nil, err
+	}
+	cloneURL, err := url.Parse(g.webURL(repository) + ".git")
+	if err != nil {
+		return nil, fmt.Errorf("invalid clone URL for repository %s (%w)", repository, err)
+	}
+	if g.config.Token != "" {
+		cloneURL.User = url.UserPassword("oauth2", g.config.Token)
+	}
+	return g.git.Checkout(ctx, g, repository, cloneURL.String(), func(ctx context.Context) (bool, error) {
+		return g.repositoryExists(ctx, repository)
+	}, version)
+}
+
+func (g gitea) GetRepositoryBrowseURL(_ context.Context, repository vcs.RepositoryAddr) (string, error) {
+	if err := repository.Validate(); err != nil {
+		return "", err
+	}
+	return g.webURL(repository), nil
+}
+
+func (g gitea) GetVersionBrowseURL(_ context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (string, error) {
+	if err := repository.Validate(); err != nil {
+		return "", err
+	}
+	if err := version.Validate(); err != nil {
+		return "", err
+	}
+	return g.webURL(repository) + "/src/tag/" + url.PathEscape(string(version)), nil
+}
+
+func (g gitea) GetFileViewURL(_ context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, file string) (string, error) {
+	if err := repository.Validate(); err != nil {
+		return "", err
+	}
+	if err := version.Validate(); err != nil {
+		return "", err
+	}
+	if file == "" {
+		return "", fmt.Errorf("empty file name passed")
+	}
+	fileParts := strings.Split(file, "/")
+	for i, part := range fileParts {
+		fileParts[i] = url.PathEscape(part)
+	}
+	return g.webURL(repository) + "/src/tag/" + url.PathEscape(string(version)) + "/" + strings.Join(fileParts, "/"), nil
+}
+
+func (g gitea) webURL(repository vcs.RepositoryAddr) string {
+	return g.config.BaseURL + "/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name)
+}
+
+func (g gitea) repoURL(repository vcs.RepositoryAddr) string {
+	return g.config.BaseURL + "/api/v1/repos/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name)
+}
+
+func (g gitea) repositoryExists(ctx context.Context, repository vcs.RepositoryAddr) (bool, error) {
+	statusCode, err := g.requestStatus(ctx, g.repoURL(repository))
+	if err != nil {
+		return false, err
+	}
+	switch statusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, &vcs.RequestFailedError{
+			Cause: &InvalidStatusCodeError{statusCode},
+		}
+	}
+}
+
+// list requests a paginated list endpoint. If all is false, only the first page with the latest items is requested.
+// The items of all pages are decoded into the response slice.
+func (g gitea) list(ctx context.Context, repository vcs.RepositoryAddr, reqURL string, all bool, response any) error {
+	var items []json.RawMessage
+	for page := 1; ; page++ {
+		var pageItems []json.RawMessage
+		if err := g.request(ctx, reqURL+"?limit="+strconv.Itoa(pageSize)+"&page="+strconv.Itoa(page), &pageItems); err != nil {
+			return g.repositoryError(repository, err)
+		}
+		items = append(items, pageItems...)
+		if !all || len(pageItems) < pageSize {
+			break
+		}
+	}
+	marshalled, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(marshalled, response)
+}
+
+func (g gitea) newRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, &vcs.RequestFailedError{
+			Cause: fmt.Errorf("invalid HTTP request (%w)", err),
+		}
+	}
+	if g.config.Token != "" {
+		req.Header.Set("Authorization", "token "+g.config.Token)
+	}
+	return req, nil
+}
+
+// requestStatus sends a GET request to the API and returns the status code only.
+func (g gitea) requestStatus(ctx context.Context, url string) (int, error) {
+	req, err := g.newRequest(ctx, url)
+	if err != nil {
+		return 0, err
+	}
+	logger.LogTrace(ctx, g.config.Logger, "Sending GET request to %s...", url)
+	resp, err := g.config.HTTPClient.Do(req)
+	if err != nil {
+		return 0, &vcs.RequestFailedError{
+			Cause: err,
+		}
+	}
+	_ = resp.Body.Close()
+	logger.LogTrace(ctx, g.config.Logger, "GET request to %s returned status code %d", url, resp.StatusCode)
+	return resp.StatusCode, nil
+}
+
+// request sends a GET request to the API and decodes the JSON response.
+func (g gitea) request(ctx context.Context, url string, response any) error {
+	req, err := g.newRequest(ctx, url)
+	if err != nil {
+		return err
+	}
+	logger.LogTrace(ctx, g.config.Logger, "Sending GET request to %s...", url)
+	resp, err := g.config.HTTPClient.Do(req)
+	if err != nil {
+		logger.LogTrace(ctx, g.config.Logger, "GET request to %s failed (%v)", url, err)
+		return &vcs.RequestFailedError{
+			Cause: err,
+		}
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	logger.LogTrace(ctx, g.config.Logger, "GET request to %s returned status code %d", url, resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return &vcs.RequestFailedError{
+			Cause: &InvalidStatusCodeError{resp.StatusCode},
+			Body:  body,
+		}
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	if err := decoder.Decode(&response); err != nil {
+		g.config.Logger.Warn(ctx, "Gitea returned an invalid JSON when requesting %s (%v)", url, err)
+		return &vcs.RequestFailedError{
+			Cause: fmt.Errorf("failed to decode response (%w)", err),
+		}
+	}
+	return nil
+}
+
+func (g gitea) repositoryError(repository vcs.RepositoryAddr, err error) error {
+	var statusCodeErr *InvalidStatusCodeError
+	if errors.As(err, &statusCodeErr) && statusCodeErr.StatusCode == http.StatusNotFound {
+		return &vcs.RepositoryNotFoundError{
+			RepositoryAddr: repository,
+			Cause:          err,
+		}
+	}
+	return err
+}
+
+func (g gitea) versionError(repository vcs.RepositoryAddr, version vcs.VersionNumber, err error) error {
+	var statusCodeErr *InvalidStatusCodeError
+	if errors.As(err, &statusCodeErr) && statusCodeErr.StatusCode == http.StatusNotFound {
+		return &vcs.VersionNotFoundError{
+			RepositoryAddr: repository,
+			Version:        version,
+			Cause:          err,
+		}
+	}
+	return err
+}
+
+type InvalidStatusCodeError struct {
+	StatusCode int
+}
+
+func (i InvalidStatusCodeError) Error() string {
+	return "Invalid status code: " + strconv.Itoa(i.StatusCode)
+}
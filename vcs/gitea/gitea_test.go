@@ -0,0 +1,355 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package gitea_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/libregistry/internal/gittest"
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/gitea"
+)
+
+const testToken = "gitea-test"
+
+var testRepo = vcs.RepositoryAddr{
+	Org:  "opentofu",
+	Name: "terraform-aws-test",
+}
+
+const testFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Tags for opentofu/terraform-aws-test</title>
+  <entry>
+    <title>v1.1.0</title>
+    <updated>2024-02-01T10:00:00Z</updated>
+  </entry>
+</feed>`
+
+// newFakeGitea creates a fake of the Gitea v1 API serving a single repository. Requests for paths containing ".git/"
+// are served from the local git repositories in gitRoot.
+func newFakeGitea(t *testing.T, gitRoot string, serveFeed bool) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	var gitHandler http.Handler
+	if gitRoot != "" {
+		gitHandler = gittest.NewHandler(t, gitRoot)
+	}
+	repoPath := "/api/v1/repos/opentofu/terraform-aws-test"
+	mux := http.NewServeMux()
+	handle := func(path string, response func() any) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "token "+testToken {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(response())
+		})
+	}
+	handle(repoPath, func() any {
+		return map[string]any{
+			"description": "Test module",
+			"stars_count": 42,
+			"forks_count": 3,
+			"fork":        true,
+			"archived":    true,
+			"parent": map[string]any{
+				"full_name": "upstream/terraform-aws-test",
+			},
+		}
+	})
+	tag := func(name string, created string) map[string]any {
+		return map[string]any{
+			"name":   name,
+			"commit": map[string]any{"created": created},
+		}
+	}
+	handle(repoPath+"/tags", func() any {
+		return []any{
+			tag("v1.1.0", "2024-02-01T10:00:00Z"),
+			tag("invalid tag", "2024-01-15T10:00:00Z"),
+			tag("v1.0.0", "2024-01-01T10:00:00+02:00"),
+		}
+	})
+	handle(repoPath+"/tags/v1.0.0", func() any {
+		return tag("v1.0.0", "2024-01-01T10:00:00+02:00")
+	})
+	release := func() any {
+		return map[string]any{
+			"tag_name":     "v1.0.0",
+			"published_at": "2024-01-01T12:00:00Z",
+			"assets": []any{
+				map[string]any{
+					"name":                 "test.zip",
+					"browser_download_url": srv.URL + "/opentofu/terraform-aws-test/releases/download/v1.0.0/test.zip",
+				},
+			},
+		}
+	}
+	handle(repoPath+"/releases", func() any {
+		return []any{
+			release(),
+			map[string]any{
+				"tag_name":     "v2.0.0",
+				"draft":        true,
+				"published_at": "2024-03-01T12:00:00Z",
+			},
+		}
+	})
+	handle(repoPath+"/releases/tags/v1.0.0", release)
+	mux.HandleFunc("/opentofu/terraform-aws-test/releases/download/v1.0.0/test.zip", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("Hello world!"))
+	})
+	if serveFeed {
+		mux.HandleFunc("/opentofu/terraform-aws-test/tags.atom", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/atom+xml")
+			_, _ = w.Write([]byte(testFeed))
+		})
+	}
+	handle("/api/v1/orgs/opentofu", func() any {
+		return map[string]any{"username": "opentofu"}
+	})
+	mux.HandleFunc("/api/v1/orgs/opentofu/members/janedoe", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if gitHandler != nil && strings.Contains(r.URL.Path, ".git/") {
+			gitHandler.ServeHTTP(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(t *testing.T, srv *httptest.Server, opts ...gitea.Opt) vcs.Client {
+	t.Helper()
+	opts = append([]gitea.Opt{
+		gitea.WithBaseURL(srv.URL),
+		gitea.WithToken(testToken),
+		gitea.WithHTTPClient(srv.Client()),
+		gitea.WithLogger(logger.NewTestLogger(t)),
+	}, opts...)
+	client, err := gitea.New(opts...)
+	if err != nil {
+		t.Fatalf("❌ Failed to initialize Gitea client (%v)", err)
+	}
+	return client
+}
+
+func TestNoBaseURL(t *testing.T) {
+	if _, err := gitea.New(); err == nil {
+		t.Fatalf("❌ Creating a Gitea client without a base URL did not fail.")
+	}
+}
+
+func TestRepoInfo(t *testing.T) {
+	srv := newFakeGitea(t, "", true)
+	client := newTestClient(t, srv)
+
+	info, err := client.GetRepositoryInfo(context.Background(), testRepo)
+	if err != nil {
+		t.Fatalf("❌ Failed to fetch repository info (%v)", err)
+	}
+	if info.Description != "Test module" || info.Popularity != 42 || info.ForkCount != 3 || !info.Archived {
+		t.Fatalf("❌ Incorrect repository info returned: %v", info)
+	}
+	if info.ForkOf == nil || info.ForkOf.String() != "upstream/terraform-aws-test" {
+		t.Fatalf("❌ Incorrect parent repository returned: %v", info.ForkOf)
+	}
+
+	_, err = client.GetRepositoryInfo(context.Background(), vcs.RepositoryAddr{Org: "opentofu", Name: "nonexistent"})
+	var notFound *vcs.RepositoryNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("❌ Expected a RepositoryNotFoundError, got: %v", err)
+	}
+}
+
+func TestTags(t *testing.T) {
+	srv := newFakeGitea(t, "", true)
+	client := newTestClient(t, srv)
+	ctx := context.Background()
+
+	all, err := client.ListAllTags(ctx, testRepo)
+	if err != nil {
+		t.Fatalf("❌ Failed to list all tags (%v)", err)
+	}
+	if len(all) != 2 || all[1].VersionNumber != "v1.0.0" {
+		t.Fatalf("❌ Incorrect tags returned: %v", all)
+	}
+
+	ver, err := client.GetTagVersion(ctx, testRepo, "v1.0.0")
+	if err != nil {
+		t.Fatalf("❌ Failed to get tag (%v)", err)
+	}
+	if !ver.Created.Equal(all[1].Created) {
+		t.Fatalf("❌ Incorrect creation date for tag: %v", ver.Created)
+	}
+
+	_, err = client.GetTagVersion(ctx, testRepo, "v2.0.0")
+	var notFound *vcs.VersionNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("❌ Expected a VersionNotFoundError, got: %v", err)
+	}
+}
+
+func TestLatestTags(t *testing.T) {
+	for name, serveFeed := range map[string]bool{"feed": true, "api-fallback": false} {
+		t.Run(name, func(t *testing.T) {
+			srv := newFakeGitea(t, "", serveFeed)
+			client := newTestClient(t, srv)
+
+			latest, err := client.ListLatestTags(context.Background(), testRepo)
+			if err != nil {
+				t.Fatalf("❌ Failed to list latest tags (%v)", err)
+			}
+			if len(latest) == 0 || latest[0].VersionNumber != "v1.1.0" {
+				t.Fatalf("❌ Incorrect latest tags returned: %v", latest)
+			}
+			// The feed only contains one tag, the API returns all of them.
+			if serveFeed != (len(latest) == 1) {
+				t.Fatalf("❌ Incorrect number of latest tags returned: %v", latest)
+			}
+		})
+	}
+}
+
+func TestReleases(t *testing.T) {
+	srv := newFakeGitea(t, "", true)
+	client := newTestClient(t, srv)
+	ctx := context.Background()
+
+	releases, err := client.ListLatestReleases(ctx, testRepo)
+	if err != nil {
+		t.Fatalf("❌ Failed to list releases (%v)", err)
+	}
+	if len(releases) != 1 || releases[0].VersionNumber != "v1.0.0" {
+		t.Fatalf("❌ Incorrect releases returned, draft releases should be skipped: %v", releases)
+	}
+
+	assets, err := client.ListAssets(ctx, testRepo, "v1.0.0")
+	if err != nil {
+		t.Fatalf("❌ Failed to list assets (%v)", err)
+	}
+	if len(assets) != 1 || assets[0] != "test.zip" {
+		t.Fatalf("❌ Incorrect assets returned: %v", assets)
+	}
+
+	contents, err := client.DownloadAsset(ctx, testRepo, "v1.0.0", "test.zip")
+	if err != nil {
+		t.Fatalf("❌ Failed to download asset (%v)", err)
+	}
+	if string(contents) != "Hello world!" {
+		t.Fatalf("❌ Incorrect asset contents: %s", contents)
+	}
+
+	_, err = client.DownloadAsset(ctx, testRepo, "v1.0.0", "nonexistent.zip")
+	var notFound *vcs.AssetNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("❌ Expected an AssetNotFoundError, got: %v", err)
+	}
+}
+
+func TestHasPermission(t *testing.T) {
+	srv := newFakeGitea(t, "", true)
+	client := newTestClient(t, srv)
+	ctx := context.Background()
+
+	hasPermission, err := client.HasPermission(ctx, "janedoe", "opentofu")
+	if err != nil {
+		t.Fatalf("❌ Failed to check permissions (%v)", err)
+	}
+	if !hasPermission {
+		t.Fatalf("❌ Organization member does not have permission.")
+	}
+	hasPermission, err = client.HasPermission(ctx, "johndoe", "opentofu")
+	if err != nil {
+		t.Fatalf("❌ Failed to check permissions (%v)", err)
+	}
+	if hasPermission {
+		t.Fatalf("❌ Non-member has permission.")
+	}
+
+	_, err = client.HasPermission(ctx, "janedoe", "nonexistent")
+	var notFound *vcs.OrganizationNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("❌ Expected an OrganizationNotFoundError, got: %v", err)
+	}
+}
+
+func TestCheckout(t *testing.T) {
+	gitRoot := t.TempDir()
+	gittest.CreateRepository(t, gitRoot, testRepo, map[string]string{
+		"main.tf": "# Test module",
+	}, "v1.0.0")
+	srv := newFakeGitea(t, gitRoot, true)
+	client := newTestClient(t, srv, gitea.WithCheckoutRootDirectory(t.TempDir()))
+
+	wc, err := client.Checkout(context.Background(), testRepo, "v1.0.0")
+	if err != nil {
+		t.Fatalf("❌ Failed to check out repository (%v)", err)
+	}
+	defer func() {
+		if err := wc.Close(); err != nil {
+			t.Fatalf("❌ Failed to close working copy (%v)", err)
+		}
+	}()
+	dir, err := wc.RawDirectory()
+	if err != nil {
+		t.Fatalf("❌ Failed to get working copy directory (%v)", err)
+	}
+	contents, err := os.ReadFile(filepath.Join(dir, "main.tf"))
+	if err != nil {
+		t.Fatalf("❌ Failed to read checked out file (%v)", err)
+	}
+	if string(contents) != "# Test module" {
+		t.Fatalf("❌ Incorrect file contents: %s", contents)
+	}
+}
+
+func TestURLs(t *testing.T) {
+	client, err := gitea.New(gitea.WithBaseURL("https://codeberg.example.com/"))
+	if err != nil {
+		t.Fatalf("❌ Failed to initialize Gitea client (%v)", err)
+	}
+	ctx := context.Background()
+
+	addr, err := client.ParseRepositoryAddr("https://codeberg.example.com/opentofu/terraform-aws-test")
+	if err != nil {
+		t.Fatalf("❌ Failed to parse repository address (%v)", err)
+	}
+	if addr != testRepo {
+		t.Fatalf("❌ Incorrect repository address: %v", addr)
+	}
+
+	browseURL, err := client.GetRepositoryBrowseURL(ctx, testRepo)
+	if err != nil || browseURL != "https://codeberg.example.com/opentofu/terraform-aws-test" {
+		t.Fatalf("❌ Incorrect repository browse URL: %s (%v)", browseURL, err)
+	}
+	versionURL, err := client.GetVersionBrowseURL(ctx, testRepo, "v1.0.0")
+	if err != nil || versionURL != "https://codeberg.example.com/opentofu/terraform-aws-test/src/tag/v1.0.0" {
+		t.Fatalf("❌ Incorrect version browse URL: %s (%v)", versionURL, err)
+	}
+	fileURL, err := client.GetFileViewURL(ctx, testRepo, "v1.0.0", "modules/test/main.tf")
+	if err != nil || fileURL != "https://codeberg.example.com/opentofu/terraform-aws-test/src/tag/v1.0.0/modules/test/main.tf" {
+		t.Fatalf("❌ Incorrect file view URL: %s (%v)", fileURL, err)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// appTokenRefreshMargin is the time before the expiry of an installation token when a new token is requested.
+const appTokenRefreshMargin = 5 * time.Minute
+
+// appJWTLifetime is the lifetime of the JWT used to request installation tokens. GitHub allows at most 10 minutes.
+const appJWTLifetime = 9 * time.Minute
+
+// AppCredentials holds the credentials of a GitHub App installation.
+type AppCredentials struct {
+	// AppID is the numeric ID of the GitHub App.
+	AppID int64
+	// InstallationID is the numeric ID of the installation of the app in an organization or user account.
+	InstallationID int64
+	// PrivateKey is the private key of the app used for signing the JWT.
+	PrivateKey *rsa.PrivateKey
+}
+
+func parseAppPrivateKey(privateKeyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key (%w)", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// appTokenSource mints installation tokens for a GitHub App and caches them until shortly before they expire.
+type appTokenSource struct {
+	credentials AppCredentials
+	apiBaseURL  string
+	httpClient  *http.Client
+	logger      logger.Logger
+
+	lock      sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// Token returns a valid installation token, requesting a new one if the cached token is about to expire.
+func (a *appTokenSource) Token(ctx context.Context) (string, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.token != "" && time.Now().Add(appTokenRefreshMargin).Before(a.expiresAt) {
+		return a.token, nil
+	}
+
+	jwt, err := a.jwt()
+	if err != nil {
+		return "", err
+	}
+	tokenURL := a.apiBaseURL + "/app/installations/" + strconv.FormatInt(a.credentials.InstallationID, 10) + "/access_tokens"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, nil)
+	if err != nil {
+		return "", &vcs.RequestFailedError{
+			Cause: fmt.Errorf("invalid HTTP request (%w)", err),
+		}
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	logger.LogTrace(ctx, a.logger, "Requesting an installation token for app %d from %s...", a.credentials.AppID, tokenURL)
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", &vcs.RequestFailedError{
+			Cause: err,
+		}
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return "", &vcs.RequestFailedError{
+			Cause: &InvalidStatusCodeError{resp.StatusCode},
+			Body:  body,
+		}
+	}
+	var response struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", &vcs.RequestFailedError{
+			Cause: fmt.Errorf("failed to decode installation token response (%w)", err),
+		}
+	}
+	if response.Token == "" {
+		return "", &vcs.RequestFailedError{
+			Cause: fmt.Errorf("no installation token returned"),
+		}
+	}
+	a.token = response.Token
+	a.expiresAt = response.ExpiresAt
+	return a.token, nil
+}
+
+// jwt creates a JWT signed with the app's private key as described in
+// https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/generating-a-json-web-token-jwt-for-a-github-app
+func (a *appTokenSource) jwt() (string, error) {
+	now := time.Now()
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		// Backdate the token to allow for clock drift.
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(a.credentials.AppID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.credentials.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT (%w)", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,149 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package github_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/github"
+)
+
+const testAppID = 1234
+const testInstallationID = 5678
+
+// verifyJWT checks the signature and issuer of a GitHub App JWT.
+func verifyJWT(t *testing.T, publicKey *rsa.PublicKey, jwt string) {
+	t.Helper()
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Errorf("❌ Invalid JWT: %s", jwt)
+		return
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Errorf("❌ Invalid JWT signature encoding (%v)", err)
+		return
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("❌ Invalid JWT signature (%v)", err)
+		return
+	}
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Errorf("❌ Invalid JWT claims encoding (%v)", err)
+		return
+	}
+	var claims struct {
+		Issuer string `json:"iss"`
+	}
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		t.Errorf("❌ Invalid JWT claims (%v)", err)
+		return
+	}
+	if claims.Issuer != fmt.Sprintf("%d", testAppID) {
+		t.Errorf("❌ Incorrect JWT issuer: %s", claims.Issuer)
+	}
+}
+
+// newAppGitHub starts a fake GitHub API with an installation token endpoint. The tokens returned expire after the
+// given lifetime. The returned counter holds the number of tokens issued.
+func newAppGitHub(t *testing.T, tokenLifetime time.Duration) (vcs.Client, *atomic.Int32) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+
+	issued := &atomic.Int32{}
+	mux := http.NewServeMux()
+	mux.HandleFunc(fmt.Sprintf("/app/installations/%d/access_tokens", testInstallationID), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		verifyJWT(t, &privateKey.PublicKey, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		n := issued.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token":      fmt.Sprintf("ghs_%d", n),
+			"expires_at": time.Now().Add(tokenLifetime).UTC().Format(time.RFC3339),
+		})
+	})
+	mux.HandleFunc("/repos/opentofu/opentofu", func(w http.ResponseWriter, r *http.Request) {
+		expected := fmt.Sprintf("Bearer ghs_%d", issued.Load())
+		if r.Header.Get("Authorization") != expected {
+			t.Errorf("❌ Incorrect authorization header: %s (expected: %s)", r.Header.Get("Authorization"), expected)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"description": "OpenTofu"})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	gh, err := github.New(
+		github.WithAPIBaseURL(srv.URL),
+		github.WithHTTPClient(srv.Client()),
+		github.WithAppCredentials(testAppID, testInstallationID, privateKeyPEM),
+		github.WithLogger(logger.NewTestLogger(t)),
+	)
+	if err != nil {
+		t.Fatalf("❌ Failed to initialize GitHub client (%v)", err)
+	}
+	return gh, issued
+}
+
+func TestAppTokenCache(t *testing.T) {
+	gh, issued := newAppGitHub(t, time.Hour)
+	for i := 0; i < 3; i++ {
+		if _, err := gh.GetRepositoryInfo(context.Background(), vcs.RepositoryAddr{Org: "opentofu", Name: "opentofu"}); err != nil {
+			t.Fatalf("❌ Failed to fetch repository info (%v)", err)
+		}
+	}
+	if issued.Load() != 1 {
+		t.Fatalf("❌ The installation token was not cached, %d tokens issued.", issued.Load())
+	}
+	t.Logf("✅ The installation token was requested once and cached.")
+}
+
+func TestAppTokenRefresh(t *testing.T) {
+	// Tokens expiring within the refresh margin are refreshed on every use.
+	gh, issued := newAppGitHub(t, time.Minute)
+	for i := 0; i < 2; i++ {
+		if _, err := gh.GetRepositoryInfo(context.Background(), vcs.RepositoryAddr{Org: "opentofu", Name: "opentofu"}); err != nil {
+			t.Fatalf("❌ Failed to fetch repository info (%v)", err)
+		}
+	}
+	if issued.Load() != 2 {
+		t.Fatalf("❌ The expiring installation token was not refreshed, %d tokens issued.", issued.Load())
+	}
+	t.Logf("✅ The expiring installation token was refreshed.")
+}
+
+func TestAppCredentialsValidation(t *testing.T) {
+	if _, err := github.New(github.WithAppCredentials(testAppID, testInstallationID, []byte("not a key"))); err == nil {
+		t.Fatalf("❌ An invalid private key did not return an error.")
+	}
+}
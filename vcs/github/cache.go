@@ -0,0 +1,55 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package github
+
+import (
+	"net/http"
+	"sync"
+)
+
+// CachedResponse is an API response stored for conditional requests.
+type CachedResponse struct {
+	// ETag is the entity tag returned by the API, sent back in the If-None-Match header.
+	ETag string
+	// Header holds the response headers, which are needed for pagination.
+	Header http.Header
+	// Body is the raw response body.
+	Body []byte
+}
+
+// ResponseCache stores API responses so unchanged resources can be requested conditionally. Responses answered with
+// 304 Not Modified do not count against the GitHub rate limit. Implementations must be safe for concurrent use.
+type ResponseCache interface {
+	// Get returns the cached response for the URL, if any.
+	Get(url string) (CachedResponse, bool)
+	// Put stores the response for the URL.
+	Put(url string, response CachedResponse)
+}
+
+// NewMemoryResponseCache returns a response cache that holds all responses in memory for the lifetime of the
+// process.
+func NewMemoryResponseCache() ResponseCache {
+	return &memoryResponseCache{
+		lock:      &sync.Mutex{},
+		responses: map[string]CachedResponse{},
+	}
+}
+
+type memoryResponseCache struct {
+	lock      *sync.Mutex
+	responses map[string]CachedResponse
+}
+
+func (m *memoryResponseCache) Get(url string) (CachedResponse, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	response, ok := m.responses[url]
+	return response, ok
+}
+
+func (m *memoryResponseCache) Put(url string, response CachedResponse) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.responses[url] = response
+}
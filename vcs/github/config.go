@@ -5,31 +5,67 @@ package github
 
 import (
 	"crypto/tls"
-	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/opentofu/libregistry/internal/gitcli"
 	"github.com/opentofu/libregistry/logger"
 )
 
+// DefaultBaseURL is the web address of github.com.
+const DefaultBaseURL = "https://github.com"
+
+// DefaultAPIBaseURL is the API address of github.com.
+const DefaultAPIBaseURL = "https://api.github.com"
+
+// DefaultMaxPages is the default maximum number of pages requested from paginated list endpoints.
+const DefaultMaxPages = 100
+
 // Opt is a function that modifies the config.
 type Opt func(config *Config) error
 
 // Config holds the configuration for GitHub.
 type Config struct {
+	// BaseURL is the web address of the GitHub instance without a trailing slash. It is used for cloning, the Atom
+	// feeds, asset downloads and the browse URLs. Defaults to DefaultBaseURL.
+	BaseURL string
+	// APIBaseURL is the address of the GitHub REST API without a trailing slash. Defaults to DefaultAPIBaseURL for
+	// github.com and to BaseURL/api/v3 for GitHub Enterprise Server.
+	APIBaseURL string
 	// Username to use for cloning in conjunction with a token.
 	Username string
 	// Token is the GitHub token to use when accessing the GitHub API and cloning.
 	Token string
+	// AppCredentials authenticates as a GitHub App installation instead of using a static Token. Installation tokens
+	// are requested and refreshed automatically and used for both API requests and cloning.
+	AppCredentials *AppCredentials
 	// CheckoutRootDirectory is the root directory where repositories should be checked out. Defaults to the OS' temp
 	// directory.
 	CheckoutRootDirectory string
 	// SkipCleanupWorkingCopyOnClose indicates that the working copy should not be cleaned up when it is closed.
 	// Defaults to false, cleaning up the working copy.
 	SkipCleanupWorkingCopyOnClose bool
+	// MirrorDirectory enables the mirror cache if set. A bare mirror of each repository is kept in this directory
+	// and refreshed incrementally, working copies are cloned from the mirror. The directory should be on the same
+	// filesystem as CheckoutRootDirectory so the objects can be hard linked. Defaults to no mirror cache.
+	MirrorDirectory string
+	// MirrorMaxSize is the maximum size of the mirror cache in bytes. The least recently used mirrors are evicted
+	// when the cache grows beyond this size. Defaults to 0, which means no limit.
+	MirrorMaxSize int64
+	// MaxPages is the maximum number of pages requested from paginated list endpoints. Requests that would need more
+	// pages fail with a *PageLimitExceededError. Defaults to DefaultMaxPages.
+	MaxPages int
+	// MaxRateLimitWait is the longest time a request waits for the rate limit to reset before failing with a
+	// *vcs.RateLimitedError. Defaults to 0, failing immediately.
+	MaxRateLimitWait time.Duration
+	// ResponseCache enables conditional requests using ETags if set. Unchanged responses are then served from the
+	// cache without using up the rate limit. Defaults to no cache.
+	ResponseCache ResponseCache
 	// GitPath holds the path to the git binary. Defaults to looking up the "git" or "git.exe" binaries in the path.
 	GitPath string
 
@@ -42,12 +78,28 @@ type Config struct {
 
 // ApplyDefaults adds the default values if none are present.
 func (c *Config) ApplyDefaults() {
+	if c.BaseURL == "" {
+		c.BaseURL = DefaultBaseURL
+	}
+
+	if c.APIBaseURL == "" {
+		if c.BaseURL == DefaultBaseURL {
+			c.APIBaseURL = DefaultAPIBaseURL
+		} else {
+			c.APIBaseURL = c.BaseURL + "/api/v3"
+		}
+	}
+
 	if c.CheckoutRootDirectory == "" {
 		c.CheckoutRootDirectory = os.TempDir()
 	}
 
+	if c.MaxPages == 0 {
+		c.MaxPages = DefaultMaxPages
+	}
+
 	if c.GitPath == "" {
-		c.GitPath = defaultGitPath
+		c.GitPath = gitcli.DefaultGitPath
 	}
 
 	if c.Logger == nil {
@@ -81,6 +133,63 @@ func WithToken(token string) Opt {
 	}
 }
 
+// WithBaseURL sets the web address of the GitHub instance, for example https://github.example.com for GitHub
+// Enterprise Server. Unless WithAPIBaseURL is also used, the API is expected at BaseURL/api/v3.
+func WithBaseURL(baseURL string) Opt {
+	return func(config *Config) error {
+		if err := validateURL(baseURL); err != nil {
+			return fmt.Errorf("invalid GitHub base URL: %s (%w)", baseURL, err)
+		}
+		config.BaseURL = strings.TrimSuffix(baseURL, "/")
+		return nil
+	}
+}
+
+// WithAPIBaseURL sets the address of the GitHub REST API, for example https://github.example.com/api/v3.
+func WithAPIBaseURL(apiBaseURL string) Opt {
+	return func(config *Config) error {
+		if err := validateURL(apiBaseURL); err != nil {
+			return fmt.Errorf("invalid GitHub API base URL: %s (%w)", apiBaseURL, err)
+		}
+		config.APIBaseURL = strings.TrimSuffix(apiBaseURL, "/")
+		return nil
+	}
+}
+
+func validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("the scheme must be http or https")
+	}
+	return nil
+}
+
+// WithAppCredentials authenticates as the installation of a GitHub App. The private key must be the PEM-encoded RSA
+// key downloaded from the app settings. This option cannot be combined with WithToken.
+func WithAppCredentials(appID int64, installationID int64, privateKeyPEM []byte) Opt {
+	return func(config *Config) error {
+		if appID <= 0 {
+			return fmt.Errorf("invalid GitHub App ID: %d", appID)
+		}
+		if installationID <= 0 {
+			return fmt.Errorf("invalid GitHub App installation ID: %d", installationID)
+		}
+		privateKey, err := parseAppPrivateKey(privateKeyPEM)
+		if err != nil {
+			return fmt.Errorf("invalid GitHub App private key (%w)", err)
+		}
+		config.AppCredentials = &AppCredentials{
+			AppID:          appID,
+			InstallationID: installationID,
+			PrivateKey:     privateKey,
+		}
+		return nil
+	}
+}
+
 // WithCheckoutRootDirectory sets a directory to use for repository checkouts.
 func WithCheckoutRootDirectory(rootDir string) Opt {
 	return func(config *Config) error {
@@ -109,19 +218,65 @@ func WithSkipCleanupWorkingCopyOnClose(skip bool) Opt {
 	}
 }
 
+// WithMirrorCache enables the mirror cache in the given directory. Instead of cloning each repository from scratch,
+// a bare mirror is kept per repository and refreshed with git fetch. The least recently used mirrors are evicted when
+// the total size exceeds maxSize bytes. Pass 0 as maxSize to disable eviction.
+func WithMirrorCache(directory string, maxSize int64) Opt {
+	return func(config *Config) error {
+		if maxSize < 0 {
+			return fmt.Errorf("invalid mirror cache size: %d", maxSize)
+		}
+		if err := os.MkdirAll(directory, 0700); err != nil {
+			return fmt.Errorf("unusable mirror directory (%w)", err)
+		}
+		directory, err := filepath.Abs(directory)
+		if err != nil {
+			return fmt.Errorf("failed to determine absolute path for %s (%v)", directory, err)
+		}
+		config.MirrorDirectory = directory
+		config.MirrorMaxSize = maxSize
+		return nil
+	}
+}
+
+// WithMaxPages sets the maximum number of pages requested from paginated list endpoints, such as the list of
+// releases or organization members. Each page holds up to 100 items.
+func WithMaxPages(maxPages int) Opt {
+	return func(config *Config) error {
+		if maxPages < 1 {
+			return fmt.Errorf("invalid maximum number of pages: %d", maxPages)
+		}
+		config.MaxPages = maxPages
+		return nil
+	}
+}
+
+// WithMaxRateLimitWait lets requests wait up to maxWait for the rate limit to reset instead of failing with a
+// *vcs.RateLimitedError immediately.
+func WithMaxRateLimitWait(maxWait time.Duration) Opt {
+	return func(config *Config) error {
+		if maxWait < 0 {
+			return fmt.Errorf("invalid maximum rate limit wait time: %s", maxWait)
+		}
+		config.MaxRateLimitWait = maxWait
+		return nil
+	}
+}
+
+// WithResponseCache enables conditional API requests backed by the given cache. Use NewMemoryResponseCache for a
+// simple in-memory cache.
+func WithResponseCache(cache ResponseCache) Opt {
+	return func(config *Config) error {
+		config.ResponseCache = cache
+		return nil
+	}
+}
+
 // WithGitPath sets the path to the Git binary. Defaults to looking up the "git" or "git.exe" binaries in the path.
 func WithGitPath(path string) Opt {
 	return func(config *Config) error {
-		cmd := exec.Command(path, "version")
-		if err := cmd.Run(); err != nil {
-			var exitErr *exec.ExitError
-			if errors.As(err, &exitErr) {
-				if exitErr.ExitCode() != 0 {
-					return fmt.Errorf("git binary %s is not usable (git version exited with %d)", path, exitErr.ExitCode())
-				}
-			} else {
-				return fmt.Errorf("git binary %s is not usable (%w)", path, err)
-			}
+		if err := gitcli.ValidateGitPath(path); err != nil {
+			return err
 		}
 		config.GitPath = path
 		return nil
@@ -1,8 +0,0 @@
-// Copyright (c) The OpenTofu Authors
-// SPDX-License-Identifier: MPL-2.0
-
-//go:build windows
-
-package github
-
-const defaultGitPath = "git.exe"
@@ -4,27 +4,19 @@
 package github
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
-	"io/fs"
 	"net/http"
 	"net/url"
-	"os"
-	"os/exec"
-	"path"
-	"runtime"
 	"strconv"
 	"strings"
-	"sync"
-	"syscall"
 	"time"
 
-	"github.com/opentofu/libregistry/internal/retry"
+	"github.com/opentofu/libregistry/internal/gitcli"
 
 	"github.com/opentofu/libregistry/logger"
 	"github.com/opentofu/libregistry/vcs"
@@ -41,18 +33,40 @@ func New(
 		}
 	}
 	config.ApplyDefaults()
+	if config.AppCredentials != nil && config.Token != "" {
+		return nil, fmt.Errorf("a GitHub token and GitHub App credentials cannot be used at the same time")
+	}
+
+	var appTokens *appTokenSource
+	if config.AppCredentials != nil {
+		appTokens = &appTokenSource{
+			credentials: *config.AppCredentials,
+			apiBaseURL:  config.APIBaseURL,
+			httpClient:  config.HTTPClient,
+			logger:      config.Logger,
+		}
+	}
 
 	return &github{
-		config: config,
-		lock:   &sync.Mutex{},
-		locks:  map[string]*sync.Mutex{},
+		config:     config,
+		appTokens:  appTokens,
+		rateLimits: &rateLimitTracker{},
+		git: gitcli.New(gitcli.Config{
+			GitPath:                       config.GitPath,
+			CheckoutRootDirectory:         config.CheckoutRootDirectory,
+			SkipCleanupWorkingCopyOnClose: config.SkipCleanupWorkingCopyOnClose,
+			MirrorDirectory:               config.MirrorDirectory,
+			MirrorMaxSize:                 config.MirrorMaxSize,
+			Logger:                        config.Logger,
+		}),
 	}, nil
 }
 
 type github struct {
-	config Config
-	lock   *sync.Mutex
-	locks  map[string]*sync.Mutex
+	config     Config
+	appTokens  *appTokenSource
+	rateLimits *rateLimitTracker
+	git        *gitcli.Git
 }
 
 func (g github) GetTagVersion(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (vcs.Version, error) {
@@ -67,15 +81,17 @@ func (g github) GetTagVersion(ctx context.Context, repository vcs.RepositoryAddr
 	if err != nil {
 		return vcs.Version{}, err
 	}
-	defer wc.cleanup()
-	return wc.getTag(ctx, version)
+	defer func() {
+		_ = wc.Close()
+	}()
+	return wc.GetTag(ctx, version)
 }
 
 func (g github) GetRepositoryBrowseURL(_ context.Context, repository vcs.RepositoryAddr) (string, error) {
 	if err := repository.Validate(); err != nil {
 		return "", err
 	}
-	return "https://github.com/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name), nil
+	return g.webURL(repository), nil
 }
 
 func (g github) GetVersionBrowseURL(_ context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (string, error) {
@@ -85,7 +101,7 @@ func (g github) GetVersionBrowseURL(_ context.Context, repository vcs.Repository
 	if err := version.Validate(); err != nil {
 		return "", err
 	}
-	return "https://github.com/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name) + "/tree/" + url.PathEscape(string(version)), nil
+	return g.webURL(repository) + "/tree/" + url.PathEscape(string(version)), nil
 }
 
 func (g github) GetFileViewURL(_ context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, file string) (string, error) {
@@ -102,7 +118,7 @@ func (g github) GetFileViewURL(_ context.Context, repository vcs.RepositoryAddr,
 	for i, part := range fileParts {
 		fileParts[i] = url.PathEscape(part)
 	}
-	return "https://github.com/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name) + "/blob/" + url.PathEscape(string(version)) + "/" + file, nil
+	return g.webURL(repository) + "/blob/" + url.PathEscape(string(version)) + "/" + strings.Join(fileParts, "/"), nil
 }
 
 func (g github) GetRepositoryInfo(ctx context.Context, repository vcs.RepositoryAddr) (vcs.RepositoryInfo, error) {
@@ -113,6 +129,7 @@ func (g github) GetRepositoryInfo(ctx context.Context, repository vcs.Repository
 		Description     string `json:"description"`
 		StargazersCount int    `json:"stargazers_count"`
 		ForkCount       int    `json:"forks_count"`
+		Archived        bool   `json:"archived"`
 		Parent          *struct {
 			Name  string `json:"name"`
 			Owner struct {
@@ -123,7 +140,7 @@ func (g github) GetRepositoryInfo(ctx context.Context, repository vcs.Repository
 
 	var response repoInfoResponse
 
-	if err := g.request(ctx, "https://api.github.com/repos/"+url.PathEscape(string(repository.Org))+"/"+url.PathEscape(repository.Name), &response); err != nil {
+	if err := g.request(ctx, g.repoAPIURL(repository), &response); err != nil {
 		return vcs.RepositoryInfo{}, err
 	}
 
@@ -131,6 +148,7 @@ func (g github) GetRepositoryInfo(ctx context.Context, repository vcs.Repository
 		Description: response.Description,
 		Popularity:  response.StargazersCount,
 		ForkCount:   response.ForkCount,
+		Archived:    response.Archived,
 	}
 	if response.Parent != nil {
 		repoInfo.ForkOf = &vcs.RepositoryAddr{
@@ -142,342 +160,77 @@ func (g github) GetRepositoryInfo(ctx context.Context, repository vcs.Repository
 }
 
 func (g github) Checkout(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (vcs.WorkingCopy, error) {
-	if err := version.Validate(); err != nil {
-		return nil, err
-	}
-	wc, err := g.getWorkingCopy(ctx, repository)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to get working copy: %w", err))
-	}
-
-	tagExists, err := wc.tagExists(ctx, version)
+	cloneURL, err := g.cloneURL(ctx, repository)
 	if err != nil {
-		wc.cleanup()
-		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to check if tag %s exists: %w", version, err))
-	}
-	if !tagExists {
-		wc.cleanup()
-		return nil, &vcs.VersionNotFoundError{
-			RepositoryAddr: repository,
-			Version:        version,
-		}
-	}
-
-	if err := wc.reset(ctx); err != nil {
-		wc.cleanup()
-		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to reset repository: %w", err))
-	}
-
-	if err := wc.clean(ctx); err != nil {
-		wc.cleanup()
-		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to clean repository: %w", err))
-	}
-
-	if err := wc.checkout(ctx, version); err != nil {
-		wc.cleanup()
-		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to check out tag %s: %w", version, err))
+		return nil, err
 	}
-	return wc, nil
+	return g.git.Checkout(ctx, g, repository, cloneURL, g.repositoryExistsFunc(repository), version)
 }
 
-func (g github) getWorkingCopy(ctx context.Context, repository vcs.RepositoryAddr) (*workingCopy, error) {
-	if err := repository.Validate(); err != nil {
+func (g github) getWorkingCopy(ctx context.Context, repository vcs.RepositoryAddr) (*gitcli.WorkingCopy, error) {
+	cloneURL, err := g.cloneURL(ctx, repository)
+	if err != nil {
 		return nil, err
 	}
-	parentDirectory := path.Join(g.config.CheckoutRootDirectory, string(repository.Org))
-	checkoutDirectory := path.Join(parentDirectory, repository.Name)
-	gitDirectory := path.Join(checkoutDirectory, ".git")
-
-	g.lock.Lock()
-	lock, ok := g.locks[checkoutDirectory]
-	if !ok {
-		lock = &sync.Mutex{}
-		g.locks[checkoutDirectory] = lock
-	}
-	g.lock.Unlock()
-	lock.Lock()
-	cleanup := func() {
-		g.lock.Lock()
-
-		if !g.config.SkipCleanupWorkingCopyOnClose {
-			// Make sure that any open file descriptors are closed before cleaning up the directory so Windows file
-			// locking doesn't block the cleanup:
-			runtime.GC()
+	return g.git.Open(ctx, g, repository, cloneURL, g.repositoryExistsFunc(repository))
+}
 
-			if err := os.RemoveAll(checkoutDirectory); err != nil {
-				g.config.Logger.Debug(ctx, "Failed to clean up clone repository at %s (%v)", checkoutDirectory, err)
-			}
+func (g github) cloneURL(ctx context.Context, repository vcs.RepositoryAddr) (string, error) {
+	cloneURL := g.webURL(repository) + ".git"
+	username := g.config.Username
+	token := g.config.Token
+	if g.appTokens != nil {
+		var err error
+		if token, err = g.appTokens.Token(ctx); err != nil {
+			return "", fmt.Errorf("failed to get GitHub App installation token (%w)", err)
 		}
-
-		delete(g.locks, checkoutDirectory)
-		lock.Unlock()
-		g.lock.Unlock()
+		username = "x-access-token"
 	}
-
-	stat, err := os.Stat(gitDirectory)
-	if err != nil || !stat.IsDir() {
-		if err := os.RemoveAll(checkoutDirectory); err != nil {
-			cleanup()
-			return nil, fmt.Errorf("failed to remove broken checkout directory %s (%w)", checkoutDirectory, err)
-		}
-		if err := os.MkdirAll(parentDirectory, 0700); err != nil {
-			cleanup()
-			return nil, fmt.Errorf("failed to create checkout parent directory %s (%w)", parentDirectory, err)
-		}
-		credentials := ""
-		if g.config.Username != "" && g.config.Token != "" {
-			credentials = url.PathEscape(g.config.Username) + ":" + url.PathEscape(g.config.Token) + "@"
-		}
-
-		cloneURL := "https://" + credentials + "github.com/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name) + ".git"
-		if err := g.git(ctx, parentDirectory, nil, "clone", "--depth", "1", cloneURL, checkoutDirectory); err != nil {
-			cleanup()
-
-			// Clone failed, check if repository exists.
-			repoExists, e := g.repositoryExists(ctx, repository)
-			if e == nil && !repoExists {
-				return nil, &vcs.RepositoryNotFoundError{RepositoryAddr: repository, Cause: err}
-			}
-
-			return nil, err
-		}
+	if username == "" || token == "" {
+		return cloneURL, nil
 	}
-
-	if err := g.git(ctx, checkoutDirectory, nil, "fetch", "--tags", "--force"); err != nil {
-		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) && exitErr.ExitCode() == 128 {
-			cleanup()
-			return nil, &vcs.RepositoryNotFoundError{RepositoryAddr: repository, Cause: err}
-		}
-		cleanup()
-		return nil, err
+	parsedURL, err := url.Parse(cloneURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid clone URL for repository %s (%w)", repository, err)
 	}
-
-	return &workingCopy{
-		ReadDirFS:  os.DirFS(checkoutDirectory).(fs.ReadDirFS),
-		repository: repository,
-		dir:        checkoutDirectory,
-		cleanup:    cleanup,
-		g:          g,
-	}, nil
-}
-
-type workingCopy struct {
-	fs.ReadDirFS
-	cleanup    func()
-	repository vcs.RepositoryAddr
-	version    vcs.VersionNumber
-	dir        string
-	g          github
-}
-
-func (w *workingCopy) Repository() vcs.RepositoryAddr {
-	return w.repository
-}
-
-func (w *workingCopy) Version() vcs.VersionNumber {
-	return w.version
-}
-
-func (w *workingCopy) Client() vcs.Client {
-	return w.g
-}
-
-func (w *workingCopy) RawDirectory() (string, error) {
-	return w.dir, nil
-}
-
-func (w *workingCopy) Close() error {
-	w.cleanup()
-	return nil
+	parsedURL.User = url.UserPassword(username, token)
+	return parsedURL.String(), nil
 }
 
-func is128Retryable(err error) bool {
-	var exitErr *exec.ExitError
-	return errors.As(err, &exitErr) && exitErr.ExitCode() == 128
-}
-
-func (w *workingCopy) checkout(ctx context.Context, version vcs.VersionNumber) error {
-	if err := retry.Func(
-		ctx,
-		"git checkout "+string(version),
-		func() error {
-			return w.g.git(ctx, w.dir, nil, "checkout", string(version))
-		},
-		is128Retryable,
-		10,
-		100*time.Millisecond,
-		w.g.config.Logger,
-	); err != nil {
-		if !is128Retryable(err) {
-			// Checkout failed, see if tag exists.
-			tagExists, e := w.tagExists(ctx, version)
-			if e == nil && !tagExists {
-				return &vcs.VersionNotFoundError{Version: version, RepositoryAddr: w.repository, Cause: err}
-			}
-
+// authorize adds the authorization header to an API request, if any credentials are configured.
+func (g github) authorize(ctx context.Context, req *http.Request) error {
+	token := g.config.Token
+	if g.appTokens != nil {
+		var err error
+		if token, err = g.appTokens.Token(ctx); err != nil {
+			return fmt.Errorf("failed to get GitHub App installation token (%w)", err)
 		}
-		return err
-	}
-	w.version = version
-	return nil
-}
-
-func (w *workingCopy) reset(ctx context.Context) error {
-	return retry.Func(
-		ctx,
-		"git reset --hard",
-		func() error {
-			return w.g.git(ctx, w.dir, nil, "reset", "--hard")
-		},
-		is128Retryable,
-		10,
-		100*time.Millisecond,
-		w.g.config.Logger,
-	)
-}
-
-func (w *workingCopy) clean(ctx context.Context) error {
-	return retry.Func(
-		ctx,
-		"git clean -fd",
-		func() error {
-			return w.g.git(ctx, w.dir, nil, "clean", "-fd")
-		},
-		is128Retryable,
-		10,
-		100*time.Millisecond,
-		w.g.config.Logger,
-	)
-}
-
-func (w *workingCopy) tagExists(ctx context.Context, version vcs.VersionNumber) (bool, error) {
-	tags, err := w.listTags(ctx)
-	if err != nil {
-		return false, err
 	}
-	for _, tag := range tags {
-		if tag.VersionNumber.Equals(version) {
-			return true, nil
-		}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
-	return false, nil
+	return nil
 }
 
-func (w *workingCopy) getTag(ctx context.Context, tag vcs.VersionNumber) (vcs.Version, error) {
-	tags, err := w.listTags(ctx)
-	if err != nil {
-		return vcs.Version{}, err
-	}
-	for _, t := range tags {
-		if t.VersionNumber.Equals(tag) {
-			return t, nil
-		}
-	}
-	return vcs.Version{}, &vcs.VersionNotFoundError{
-		RepositoryAddr: w.repository,
-		Version:        tag,
-	}
+func (g github) webURL(repository vcs.RepositoryAddr) string {
+	return g.config.BaseURL + "/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name)
 }
 
-func (w *workingCopy) listTags(ctx context.Context) ([]vcs.Version, error) {
-	stdout, err := w.listRefs(ctx)
-	if err != nil {
-		return nil, err
-	}
-	lines := strings.Split(stdout.String(), "\n")
-	var result []vcs.Version
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		parts := strings.SplitN(line, "\t", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("line does not contain enough parts to parse: %s", line)
-		}
-		tag := vcs.VersionNumber(strings.ReplaceAll(parts[0], "refs/tags/", ""))
-		unixTime, err := strconv.Atoi(parts[1])
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse git output: %s (%v)", line, err)
-		}
-		created := time.Unix(int64(unixTime), 0)
-		ver := vcs.Version{
-			VersionNumber: tag,
-			Created:       created,
-		}
-		if err := ver.Validate(); err != nil {
-			w.g.config.Logger.Debug(ctx, "Skipping tag %s because it does not match the naming rules.", ver.VersionNumber)
-			continue
-		}
-		result = append(result, ver)
-	}
-	return result, nil
+func (g github) repoAPIURL(repository vcs.RepositoryAddr) string {
+	return g.config.APIBaseURL + "/repos/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name)
 }
 
-func (w *workingCopy) listRefs(ctx context.Context) (*bytes.Buffer, error) {
-	return retry.Func2(
-		ctx,
-		fmt.Sprintf("git for-each-ref: %s", w.dir),
-		func() (*bytes.Buffer, error) {
-			stdout := &bytes.Buffer{}
-			err := w.g.git(ctx, w.dir, stdout, "for-each-ref", "--format=%(refname:short)\t%(creatordate:format:%s)", "refs/tags/*")
-			return stdout, err
-		},
-		is128Retryable,
-		10,
-		100*time.Millisecond,
-		w.g.config.Logger,
-	)
-}
-
-func (g github) git(ctx context.Context, dir string, stdout io.Writer, params ...string) error {
-	params = append([]string{"-c", "credential.helper="}, params...)
-	cmd := exec.Command(g.config.GitPath, params...)
-	commandString := strings.Join(append([]string{g.config.GitPath}, params...), " ")
-	logger.LogTrace(ctx, g.config.Logger, "Running "+commandString)
-	if stdout == nil {
-		stdout = logger.NewWriter(ctx, g.config.Logger, logger.LevelDebug, dir+"> "+commandString+": ")
-	}
-	cmd.Stdout = stdout
-	cmd.Stderr = logger.NewWriter(ctx, g.config.Logger, logger.LevelDebug, dir+"> "+commandString+": ")
-	cmd.Dir = dir
-	cmd.Env = []string{"GIT_TERMINAL_PROMPT=0"}
-	done := make(chan struct{})
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to run %s (%w)", commandString, err)
-	}
-	var lastErr error
-	go func() {
-		defer close(done)
-		lastErr = cmd.Wait()
-	}()
-	select {
-	case <-done:
-	case <-ctx.Done():
-		_ = cmd.Process.Signal(syscall.SIGTERM)
-		select {
-		case <-time.After(30 * time.Second):
-			_ = cmd.Process.Kill()
-		case <-done:
-		}
-		<-done
+func (g github) repositoryExistsFunc(repository vcs.RepositoryAddr) gitcli.RepositoryExistsFunc {
+	return func(ctx context.Context) (bool, error) {
+		return g.repositoryExists(ctx, repository)
 	}
-	if lastErr == nil {
-		return nil
-	}
-	var exitErr *exec.ExitError
-	if !errors.As(lastErr, &exitErr) {
-		return fmt.Errorf("%s failed (%w)", commandString, lastErr)
-	}
-	if exitErr.ExitCode() != 0 {
-		return fmt.Errorf("%s exited with exit code %d (%w)", commandString, exitErr.ExitCode(), exitErr)
-	}
-	return nil
 }
 
 func (g github) ParseRepositoryAddr(ref string) (vcs.RepositoryAddr, error) {
-	ref = strings.TrimPrefix(ref, "github.com/")
+	ref = strings.TrimPrefix(ref, g.config.BaseURL+"/")
+	if baseURL, err := url.Parse(g.config.BaseURL); err == nil {
+		ref = strings.TrimPrefix(ref, baseURL.Host+"/")
+	}
 	parts := strings.SplitN(ref, "/", 2)
 	if len(parts) != 2 {
 		return vcs.RepositoryAddr{}, &vcs.InvalidRepositoryAddrError{
@@ -513,7 +266,7 @@ func (g github) listLatest(ctx context.Context, repository vcs.RepositoryAddr, f
 	if err := repository.Validate(); err != nil {
 		return nil, err
 	}
-	rssURL := "https://github.com/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name) + "/" + file
+	rssURL := g.webURL(repository) + "/" + file
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rssURL, nil)
 	if err != nil {
 		return nil, &vcs.RequestFailedError{
@@ -573,8 +326,10 @@ func (g github) ListAllTags(ctx context.Context, repository vcs.RepositoryAddr)
 	if err != nil {
 		return nil, err
 	}
-	defer wc.cleanup()
-	return wc.listTags(ctx)
+	defer func() {
+		_ = wc.Close()
+	}()
+	return wc.ListTags(ctx)
 }
 
 func (g github) ListAllReleases(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
@@ -592,10 +347,10 @@ func (g github) listAll(ctx context.Context, repository vcs.RepositoryAddr, item
 	if err := repository.Validate(); err != nil {
 		return nil, err
 	}
-	reqURL := "https://api.github.com/repos/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name) + "/" + itemType + "s"
+	reqURL := g.repoAPIURL(repository) + "/" + itemType + "s"
 
-	var response []responseItem
-	if err := g.request(ctx, reqURL, &response); err != nil {
+	response, err := requestAllPages[responseItem](ctx, g, reqURL)
+	if err != nil {
 		var statusCodeErr *InvalidStatusCodeError
 		if errors.As(err, &statusCodeErr) && statusCodeErr.StatusCode == http.StatusNotFound {
 			return nil, &vcs.RepositoryNotFoundError{
@@ -629,21 +384,113 @@ func (g github) listAll(ctx context.Context, repository vcs.RepositoryAddr, item
 	return result, nil
 }
 
+// requestAllPages requests all pages of a list endpoint by following the next link in the Link header and returns
+// the items of all pages. It returns a *PageLimitExceededError if the list has more than MaxPages pages.
+func requestAllPages[T any](ctx context.Context, g github, reqURL string) ([]T, error) {
+	separator := "?"
+	if strings.Contains(reqURL, "?") {
+		separator = "&"
+	}
+	nextURL := reqURL + separator + "per_page=" + strconv.Itoa(pageSize)
+	var result []T
+	for page := 0; nextURL != ""; page++ {
+		if page >= g.config.MaxPages {
+			return nil, &PageLimitExceededError{
+				URL:      reqURL,
+				MaxPages: g.config.MaxPages,
+			}
+		}
+		var items []T
+		header, err := g.requestWithHeader(ctx, nextURL, &items)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+		nextURL = parseNextLink(header.Get("Link"))
+	}
+	return result, nil
+}
+
+// parseNextLink returns the URL marked with rel="next" from a Link header, or an empty string if there is none.
+func parseNextLink(linkHeader string) string {
+	for _, link := range strings.Split(linkHeader, ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+		linkURL := strings.TrimSpace(parts[0])
+		if !strings.HasPrefix(linkURL, "<") || !strings.HasSuffix(linkURL, ">") {
+			continue
+		}
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.TrimSuffix(strings.TrimPrefix(linkURL, "<"), ">")
+			}
+		}
+	}
+	return ""
+}
+
 func (g github) request(ctx context.Context, url string, response any) error {
+	_, err := g.requestWithHeader(ctx, url, response)
+	return err
+}
+
+func (g github) requestWithHeader(ctx context.Context, url string, response any) (http.Header, error) {
+	for attempt := 0; ; attempt++ {
+		body, header, err := g.requestRaw(ctx, url)
+		if err != nil {
+			var rateLimitErr *vcs.RateLimitedError
+			if !errors.As(err, &rateLimitErr) || attempt >= maxRateLimitRetries {
+				return nil, err
+			}
+			wait := time.Until(rateLimitErr.ResetAt)
+			if wait > g.config.MaxRateLimitWait {
+				return nil, err
+			}
+			g.config.Logger.Info(ctx, "GitHub rate limit exceeded, waiting %s before retrying %s...", wait, url)
+			select {
+			case <-ctx.Done():
+				return nil, err
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		if err := json.Unmarshal(body, &response); err != nil {
+			g.config.Logger.Warn(ctx, "GitHub returned an invalid JSON when requesting %s (%v)", url, err)
+			return nil, &vcs.RequestFailedError{
+				Cause: fmt.Errorf("failed to decode response (%w)", err),
+			}
+		}
+		return header, nil
+	}
+}
+
+// requestRaw sends a GET request to the API and returns the response body and headers. If a response cache is
+// configured, the request is sent conditionally and the cached response is returned if it has not changed.
+func (g github) requestRaw(ctx context.Context, url string) ([]byte, http.Header, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return &vcs.RequestFailedError{
+		return nil, nil, &vcs.RequestFailedError{
 			Cause: fmt.Errorf("invalid HTTP request (%w)", err),
 		}
 	}
-	if g.config.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+g.config.Token)
+	if err := g.authorize(ctx, req); err != nil {
+		return nil, nil, err
+	}
+	var cached CachedResponse
+	hasCached := false
+	if g.config.ResponseCache != nil {
+		if cached, hasCached = g.config.ResponseCache.Get(url); hasCached {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
 	}
 	logger.LogTrace(ctx, g.config.Logger, "Sending GET request to %s...", url)
 	resp, err := g.config.HTTPClient.Do(req)
 	if err != nil {
 		logger.LogTrace(ctx, g.config.Logger, "GET request to %s failed (%v)", url, err)
-		return &vcs.RequestFailedError{
+		return nil, nil, &vcs.RequestFailedError{
 			Cause: err,
 		}
 	}
@@ -651,34 +498,48 @@ func (g github) request(ctx context.Context, url string, response any) error {
 		_ = resp.Body.Close()
 	}()
 	logger.LogTrace(ctx, g.config.Logger, "GET request to %s returned status code %d", url, resp.StatusCode)
+	g.rateLimits.update(resp.Header)
+	if resp.StatusCode == http.StatusNotModified && hasCached {
+		return cached.Body, cached.Header, nil
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return &vcs.RequestFailedError{
+		err := &vcs.RequestFailedError{
 			Cause: &InvalidStatusCodeError{resp.StatusCode},
 			Body:  body,
 		}
+		if resetAt, ok := rateLimitResetTime(resp); ok {
+			return nil, nil, &vcs.RateLimitedError{
+				ResetAt: resetAt,
+				Cause:   err,
+			}
+		}
+		return nil, nil, err
 	}
-
-	decoder := json.NewDecoder(resp.Body)
-
-	if err := decoder.Decode(&response); err != nil {
-		g.config.Logger.Warn(ctx, "GitHub returned an invalid JSON when requesting %s (%v)", url, err)
-		return &vcs.RequestFailedError{
-			Cause: fmt.Errorf("failed to decode response (%w)", err),
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, &vcs.RequestFailedError{
+			Cause: err,
 		}
 	}
-
-	return nil
+	if etag := resp.Header.Get("ETag"); etag != "" && g.config.ResponseCache != nil {
+		g.config.ResponseCache.Put(url, CachedResponse{
+			ETag:   etag,
+			Header: resp.Header,
+			Body:   body,
+		})
+	}
+	return body, resp.Header, nil
 }
 
 func (g github) ListAssets(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) ([]vcs.AssetName, error) {
 	logger.LogTrace(ctx, g.config.Logger, "Listing assets for repository %s version %s", repository, version)
 
 	type responseItem struct {
-		Name   string `json:"name"`
-		Assets []struct {
-			Name vcs.AssetName `json:"name"`
-		} `json:"assets"`
+		ID int64 `json:"id"`
+	}
+	type assetItem struct {
+		Name vcs.AssetName `json:"name"`
 	}
 
 	if err := repository.Validate(); err != nil {
@@ -688,7 +549,7 @@ func (g github) ListAssets(ctx context.Context, repository vcs.RepositoryAddr, v
 		return nil, err
 	}
 
-	reqURL := "https://api.github.com/repos/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name) + "/releases/tags/" + url.PathEscape(string(version))
+	reqURL := g.repoAPIURL(repository) + "/releases/tags/" + url.PathEscape(string(version))
 
 	var response responseItem
 	if err := g.request(ctx, reqURL, &response); err != nil {
@@ -703,8 +564,13 @@ func (g github) ListAssets(ctx context.Context, repository vcs.RepositoryAddr, v
 
 		return nil, err
 	}
+	// The release response only contains a limited number of assets, so the assets are listed separately.
+	assets, err := requestAllPages[assetItem](ctx, g, g.repoAPIURL(repository)+"/releases/"+strconv.FormatInt(response.ID, 10)+"/assets")
+	if err != nil {
+		return nil, err
+	}
 	var result []vcs.AssetName
-	for _, asset := range response.Assets {
+	for _, asset := range assets {
 		err := asset.Name.Validate()
 		if err != nil {
 			g.config.Logger.Debug(ctx, "Skipping invalid asset named %s in repository %s release %s", asset.Name, repository, version)
@@ -725,16 +591,19 @@ func (g github) DownloadAsset(ctx context.Context, repository vcs.RepositoryAddr
 	if err := asset.Validate(); err != nil {
 		return nil, err
 	}
-	logger.LogTrace(ctx, g.config.Logger, "Listing asset %s for repository %s version %s", asset, repository, version)
-	assetURL := "https://api.github.com/repos/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name) + "/releases/download/" + url.PathEscape(string(version)) + "/" + url.PathEscape(string(asset))
+	logger.LogTrace(ctx, g.config.Logger, "Downloading asset %s for repository %s version %s", asset, repository, version)
+	assetURL, err := g.GetAssetDownloadURL(ctx, repository, version, asset)
+	if err != nil {
+		return nil, err
+	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, assetURL, nil)
 	if err != nil {
 		return nil, &vcs.RequestFailedError{
 			Cause: fmt.Errorf("invalid HTTP request (%w)", err),
 		}
 	}
-	if g.config.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+g.config.Token)
+	if err := g.authorize(ctx, req); err != nil {
+		return nil, err
 	}
 	logger.LogTrace(ctx, g.config.Logger, "Sending GET request to %s...", assetURL)
 	resp, err := g.config.HTTPClient.Do(req)
@@ -772,6 +641,19 @@ func (g github) DownloadAsset(ctx context.Context, repository vcs.RepositoryAddr
 	return body, nil
 }
 
+func (g github) GetAssetDownloadURL(_ context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, asset vcs.AssetName) (string, error) {
+	if err := repository.Validate(); err != nil {
+		return "", err
+	}
+	if err := version.Validate(); err != nil {
+		return "", err
+	}
+	if err := asset.Validate(); err != nil {
+		return "", err
+	}
+	return g.webURL(repository) + "/releases/download/" + url.PathEscape(string(version)) + "/" + url.PathEscape(string(asset)), nil
+}
+
 func (g github) HasPermission(ctx context.Context, username vcs.Username, organization vcs.OrganizationAddr) (bool, error) {
 	type memberType struct {
 		Login string `json:"login"`
@@ -784,9 +666,9 @@ func (g github) HasPermission(ctx context.Context, username vcs.Username, organi
 		return false, err
 	}
 	logger.LogTrace(ctx, g.config.Logger, "Checking if user %s has permissions for the organization %s...", username, organization)
-	reqURL := "https://api.github.com/orgs/" + url.PathEscape(string(organization)) + "/members"
-	var response []memberType
-	if err := g.request(ctx, reqURL, &response); err != nil {
+	reqURL := g.config.APIBaseURL + "/orgs/" + url.PathEscape(string(organization)) + "/members"
+	response, err := requestAllPages[memberType](ctx, g, reqURL)
+	if err != nil {
 		var statusCodeErr *InvalidStatusCodeError
 		if errors.As(err, &statusCodeErr) && statusCodeErr.StatusCode == http.StatusNotFound {
 			return false, &vcs.OrganizationNotFoundError{
@@ -806,7 +688,7 @@ func (g github) HasPermission(ctx context.Context, username vcs.Username, organi
 
 func (g github) repositoryExists(ctx context.Context, repositoryAddr vcs.RepositoryAddr) (bool, error) {
 	var repoResponse any
-	if err := g.request(ctx, "https://github.com/repos/"+url.PathEscape(string(repositoryAddr.Org))+"/"+url.PathEscape(repositoryAddr.Name), &repoResponse); err != nil {
+	if err := g.request(ctx, g.repoAPIURL(repositoryAddr), &repoResponse); err != nil {
 		var statusCodeError *InvalidStatusCodeError
 		if errors.As(err, &statusCodeError) && statusCodeError.StatusCode == http.StatusNotFound {
 			return false, nil
@@ -816,6 +698,19 @@ func (g github) repositoryExists(ctx context.Context, repositoryAddr vcs.Reposit
 	return true, nil
 }
 
+// pageSize is the number of items requested per page from list endpoints. This is the maximum GitHub allows.
+const pageSize = 100
+
+// PageLimitExceededError indicates that a list endpoint returned more pages than the configured MaxPages.
+type PageLimitExceededError struct {
+	URL      string
+	MaxPages int
+}
+
+func (p PageLimitExceededError) Error() string {
+	return "Request to " + p.URL + " exceeded the maximum of " + strconv.Itoa(p.MaxPages) + " pages"
+}
+
 type InvalidStatusCodeError struct {
 	StatusCode int
 }
@@ -5,12 +5,9 @@ package github_test
 
 import (
 	"context"
-	"os"
 	"testing"
 
-	"github.com/opentofu/libregistry/logger"
 	"github.com/opentofu/libregistry/vcs"
-	"github.com/opentofu/libregistry/vcs/github"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -23,16 +20,7 @@ func TestCloneRace(t *testing.T) {
 	const testRepo = "terraform-provider-tfcoremock"
 	const testVersion = "v0.3.0"
 
-	checkoutDir := t.TempDir()
-
-	gh, err := github.New(
-		github.WithCheckoutRootDirectory(checkoutDir),
-		github.WithLogger(logger.NewTestLogger(t)),
-		github.WithToken(os.Getenv("GITHUB_TOKEN")),
-	)
-	if err != nil {
-		t.Fatal(err)
-	}
+	gh := newTestClient(t, newFakeGitHub(t))
 	ctx := context.Background()
 
 	workingCopy, err := gh.Checkout(ctx, vcs.RepositoryAddr{
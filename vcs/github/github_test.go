@@ -5,17 +5,93 @@ package github_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
-	"os"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/opentofu/libregistry/internal/gittest"
 	"github.com/opentofu/libregistry/logger"
 	"github.com/opentofu/libregistry/vcs"
 	"github.com/opentofu/libregistry/vcs/github"
 )
 
+const testTagsFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <id>tag:github.com,2008:Repository/1/v6.2.3</id>
+    <title>v6.2.3</title>
+    <updated>2024-07-08T16:55:36Z</updated>
+  </entry>
+</feed>`
+
+// newFakeGitHub starts a fake GitHub Enterprise Server with the API at /api/v3. Git requests are served from local
+// repositories, which contain the integrations/terraform-provider-github and opentofu/terraform-provider-tfcoremock
+// repositories.
+func newFakeGitHub(t *testing.T) *httptest.Server {
+	t.Helper()
+	gitRoot := t.TempDir()
+	gittest.CreateRepository(t, gitRoot, vcs.RepositoryAddr{Org: "integrations", Name: "terraform-provider-github"}, map[string]string{
+		"README.md": "# Terraform Provider GitHub",
+	}, "v6.2.2", "v6.2.3")
+	gittest.CreateRepository(t, gitRoot, vcs.RepositoryAddr{Org: "opentofu", Name: "terraform-provider-tfcoremock"}, map[string]string{
+		"README.md": "# Terraform Provider TF Core Mock",
+	}, "v0.3.0")
+	gitHandler := gittest.NewHandler(t, gitRoot)
+
+	mux := http.NewServeMux()
+	handleJSON := func(path string, response any) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(response)
+		})
+	}
+	handleJSON("/api/v3/repos/opentofu/opentofu", map[string]any{
+		"description":      "OpenTofu lets you declaratively manage your cloud infrastructure.",
+		"stargazers_count": 22000,
+	})
+	handleJSON("/api/v3/repos/integrations/terraform-provider-github", map[string]any{})
+	handleJSON("/api/v3/repos/opentofu/terraform-provider-tfcoremock", map[string]any{})
+	handleJSON("/api/v3/repos/integrations/terraform-provider-github/releases", []any{
+		map[string]any{"name": "v6.2.3", "published_at": "2024-07-08T16:58:50Z"},
+		map[string]any{"name": "v6.2.2", "published_at": "2024-06-20T10:00:00Z"},
+	})
+	mux.HandleFunc("/integrations/terraform-provider-github/tags.atom", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/atom+xml")
+		_, _ = w.Write([]byte(testTagsFeed))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, ".git/") {
+			gitHandler.ServeHTTP(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(t *testing.T, srv *httptest.Server, opts ...github.Opt) vcs.Client {
+	t.Helper()
+	opts = append([]github.Opt{
+		github.WithBaseURL(srv.URL),
+		github.WithHTTPClient(srv.Client()),
+		github.WithCheckoutRootDirectory(t.TempDir()),
+		github.WithLogger(logger.NewTestLogger(t)),
+	}, opts...)
+	gh, err := github.New(opts...)
+	if err != nil {
+		t.Fatalf("❌ Failed to initialize GitHub client (%v)", err)
+	}
+	return gh
+}
+
 func TestRepoInfo(t *testing.T) {
 	t.Parallel()
 
@@ -24,13 +100,7 @@ func TestRepoInfo(t *testing.T) {
 
 	t.Logf("⚙️ Checking if the GitHub API returns a repository description for %s/%s...", testOrg, testRepo)
 
-	gh, err := github.New(
-		github.WithLogger(logger.NewTestLogger(t)),
-		github.WithToken(os.Getenv("GITHUB_TOKEN")),
-	)
-	if err != nil {
-		t.Fatalf("❌ Failed to initialize Github client (%v)", err)
-	}
+	gh := newTestClient(t, newFakeGitHub(t))
 	ctx := context.Background()
 
 	info, err := gh.GetRepositoryInfo(ctx, vcs.RepositoryAddr{
@@ -57,13 +127,7 @@ func TestReleases(t *testing.T) {
 
 	t.Logf("⚙️ Checking if version %s is present in %s/%s and was released on %s...", testVersion, testOrg, testRepo, testDate.String())
 
-	gh, err := github.New(
-		github.WithLogger(logger.NewTestLogger(t)),
-		github.WithToken(os.Getenv("GITHUB_TOKEN")),
-	)
-	if err != nil {
-		t.Fatalf("❌ Failed to initialize Github client (%v)", err)
-	}
+	gh := newTestClient(t, newFakeGitHub(t))
 	ctx := context.Background()
 
 	releases, err := gh.ListAllReleases(ctx, vcs.RepositoryAddr{
@@ -99,20 +163,11 @@ func TestTags(t *testing.T) {
 	const testOrg = "integrations"
 	const testRepo = "terraform-provider-github"
 	const testVersion = "v6.2.3"
-	testDate, err := time.Parse(time.RFC3339, "2024-07-08T16:55:36Z")
-	if err != nil {
-		t.Fatalf("❌ Failed to parse test date (%v)", err)
-	}
+	testDate := gittest.CommitDate
 
 	t.Logf("⚙️ Checking if version %s is present in %s/%s and was released on %s...", testVersion, testOrg, testRepo, testDate.String())
 
-	gh, err := github.New(
-		github.WithLogger(logger.NewTestLogger(t)),
-		github.WithToken(os.Getenv("GITHUB_TOKEN")),
-	)
-	if err != nil {
-		t.Fatalf("❌ Failed to initialize Github client (%v)", err)
-	}
+	gh := newTestClient(t, newFakeGitHub(t))
 	ctx := context.Background()
 
 	tags, err := gh.ListAllTags(ctx, vcs.RepositoryAddr{
@@ -139,6 +194,22 @@ func TestTags(t *testing.T) {
 	t.Fatalf("❌ Expected version not found (%s)", testVersion)
 }
 
+func TestLatestTags(t *testing.T) {
+	t.Parallel()
+
+	gh := newTestClient(t, newFakeGitHub(t))
+	tags, err := gh.ListLatestTags(context.Background(), vcs.RepositoryAddr{
+		Org:  "integrations",
+		Name: "terraform-provider-github",
+	})
+	if err != nil {
+		t.Fatalf("❌ Failed to list the latest GitHub tags (%v)", err)
+	}
+	if len(tags) != 1 || tags[0].VersionNumber != "v6.2.3" || !tags[0].Created.Equal(gittest.CommitDate) {
+		t.Fatalf("❌ Incorrect tags returned from the Atom feed: %v", tags)
+	}
+}
+
 func TestClone(t *testing.T) {
 	t.Parallel()
 
@@ -146,16 +217,7 @@ func TestClone(t *testing.T) {
 	const testRepo = "terraform-provider-github"
 	const testVersion = "v6.2.3"
 
-	checkoutDir := t.TempDir()
-
-	gh, err := github.New(
-		github.WithCheckoutRootDirectory(checkoutDir),
-		github.WithLogger(logger.NewTestLogger(t)),
-		github.WithToken(os.Getenv("GITHUB_TOKEN")),
-	)
-	if err != nil {
-		t.Fatal(err)
-	}
+	gh := newTestClient(t, newFakeGitHub(t))
 	ctx := context.Background()
 	workingCopy, err := gh.Checkout(ctx, vcs.RepositoryAddr{
 		Org:  testOrg,
@@ -191,18 +253,9 @@ func TestCloneNotFound(t *testing.T) {
 	const testRepo = "nonexistent"
 	const testVersion = "v1.6.0"
 
-	checkoutDir := t.TempDir()
-
-	gh, err := github.New(
-		github.WithCheckoutRootDirectory(checkoutDir),
-		github.WithLogger(logger.NewTestLogger(t)),
-		github.WithToken(os.Getenv("GITHUB_TOKEN")),
-	)
-	if err != nil {
-		t.Fatal(err)
-	}
+	gh := newTestClient(t, newFakeGitHub(t))
 	ctx := context.Background()
-	_, err = gh.Checkout(ctx, vcs.RepositoryAddr{
+	_, err := gh.Checkout(ctx, vcs.RepositoryAddr{
 		Org:  testOrg,
 		Name: testRepo,
 	}, testVersion)
@@ -228,7 +281,6 @@ func TestURL(t *testing.T) {
 	gh, err := github.New(
 		github.WithCheckoutRootDirectory(checkoutDir),
 		github.WithLogger(logger.NewTestLogger(t)),
-		github.WithToken(os.Getenv("GITHUB_TOKEN")),
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -261,4 +313,42 @@ func TestURL(t *testing.T) {
 		t.Fatalf("❌ Querying the file view URL returned the incorrect URL: %s", fileURL)
 	}
 	t.Logf("✅ The file view URL is correct: %s", fileURL)
+
+	fileURL, err = gh.GetFileViewURL(ctx, vcs.RepositoryAddr{Org: testOrg, Name: testRepo}, testVersion, "docs/my page#1.md")
+	if err != nil {
+		t.Fatalf("❌ Querying the file view URL returned an error (%v)", err)
+	}
+	if fileURL != "https://github.com/opentofu/opentofu/blob/v1.6.0/docs/my%20page%231.md" {
+		t.Fatalf("❌ The file view URL was not escaped correctly: %s", fileURL)
+	}
+	t.Logf("✅ The file view URL is escaped correctly: %s", fileURL)
+}
+
+func TestEnterpriseURL(t *testing.T) {
+	t.Parallel()
+
+	gh, err := github.New(github.WithBaseURL("https://github.example.com/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	addr, err := gh.ParseRepositoryAddr("github.example.com/opentofu/opentofu")
+	if err != nil {
+		t.Fatalf("❌ Failed to parse repository address (%v)", err)
+	}
+	repoURL, err := gh.GetRepositoryBrowseURL(ctx, addr)
+	if err != nil {
+		t.Fatalf("❌ Querying the repo browse URL returned an error (%v)", err)
+	}
+	if repoURL != "https://github.example.com/opentofu/opentofu" {
+		t.Fatalf("❌ Querying the repo browse URL returned the incorrect URL: %s", repoURL)
+	}
+	assetURL, err := gh.GetAssetDownloadURL(ctx, addr, "v1.6.0", "tofu.zip")
+	if err != nil {
+		t.Fatalf("❌ Querying the asset download URL returned an error (%v)", err)
+	}
+	if assetURL != "https://github.example.com/opentofu/opentofu/releases/download/v1.6.0/tofu.zip" {
+		t.Fatalf("❌ Querying the asset download URL returned the incorrect URL: %s", assetURL)
+	}
+	t.Logf("✅ The GitHub Enterprise URLs are correct.")
 }
@@ -0,0 +1,260 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// graphQLBatchSize is the number of repositories queried in a single GraphQL request.
+const graphQLBatchSize = 25
+
+// graphQLTagPageSize is the number of tags requested per repository and page. This is the maximum GitHub allows.
+const graphQLTagPageSize = 100
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+type graphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Path    []any  `json:"path"`
+}
+
+type graphQLRefs struct {
+	PageInfo struct {
+		HasNextPage bool   `json:"hasNextPage"`
+		EndCursor   string `json:"endCursor"`
+	} `json:"pageInfo"`
+	Nodes []struct {
+		Name   vcs.VersionNumber `json:"name"`
+		Target struct {
+			// OID and CommittedDate are set for lightweight tags pointing to a commit.
+			OID           string `json:"oid"`
+			CommittedDate string `json:"committedDate"`
+			// Tagger and Target are set for annotated tags.
+			Tagger *struct {
+				Date string `json:"date"`
+			} `json:"tagger"`
+			Target *struct {
+				OID string `json:"oid"`
+			} `json:"target"`
+		} `json:"target"`
+	} `json:"nodes"`
+}
+
+type graphQLResponse struct {
+	Data   map[string]*struct{ Refs graphQLRefs } `json:"data"`
+	Errors []graphQLError                         `json:"errors"`
+}
+
+// pendingRepository is a repository with more tags to fetch.
+type pendingRepository struct {
+	repository vcs.RepositoryAddr
+	cursor     string
+}
+
+// ListAllTagsBatch lists the tags of many repositories using the GitHub GraphQL API. The creation date of a tag is
+// the tagger date for annotated tags and the commit date for lightweight tags, the same as ListAllTags returns.
+func (g github) ListAllTagsBatch(ctx context.Context, repositories []vcs.RepositoryAddr) (map[vcs.RepositoryAddr]vcs.BatchTagResult, error) {
+	results := make(map[vcs.RepositoryAddr]vcs.BatchTagResult, len(repositories))
+	var pending []pendingRepository
+	for _, repository := range repositories {
+		if _, ok := results[repository]; ok {
+			continue
+		}
+		if err := repository.Validate(); err != nil {
+			results[repository] = vcs.BatchTagResult{Err: err}
+			continue
+		}
+		results[repository] = vcs.BatchTagResult{}
+		pending = append(pending, pendingRepository{repository: repository})
+	}
+
+	for len(pending) > 0 {
+		batch := pending[:min(graphQLBatchSize, len(pending))]
+		pending = pending[len(batch):]
+		logger.LogTrace(ctx, g.config.Logger, "Requesting tags for %d repositories via GraphQL...", len(batch))
+		next, err := g.listTagsGraphQL(ctx, batch, results)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, next...)
+	}
+	return results, nil
+}
+
+// listTagsGraphQL fetches one page of tags for each repository in the batch and adds them to the results. It returns
+// the repositories that have more pages.
+func (g github) listTagsGraphQL(
+	ctx context.Context,
+	batch []pendingRepository,
+	results map[vcs.RepositoryAddr]vcs.BatchTagResult,
+) ([]pendingRepository, error) {
+	var params []string
+	var fields []string
+	variables := map[string]any{}
+	for i, p := range batch {
+		index := strconv.Itoa(i)
+		params = append(params, "$owner"+index+": String!", "$name"+index+": String!", "$cursor"+index+": String")
+		fields = append(fields, "r"+index+": repository(owner: $owner"+index+", name: $name"+index+") { "+
+			"refs(refPrefix: \"refs/tags/\", first: "+strconv.Itoa(graphQLTagPageSize)+", after: $cursor"+index+") { "+
+			"pageInfo { hasNextPage endCursor } "+
+			"nodes { name target { ... on Commit { oid committedDate } ... on Tag { tagger { date } target { oid } } } } } }")
+		variables["owner"+index] = string(p.repository.Org)
+		variables["name"+index] = p.repository.Name
+		if p.cursor != "" {
+			variables["cursor"+index] = p.cursor
+		}
+	}
+	query := "query(" + strings.Join(params, ", ") + ") { " + strings.Join(fields, " ") + " }"
+
+	response, err := g.graphQL(ctx, graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, graphQLErr := range response.Errors {
+		if len(graphQLErr.Path) == 0 {
+			continue
+		}
+		alias, ok := graphQLErr.Path[0].(string)
+		if !ok || !strings.HasPrefix(alias, "r") {
+			continue
+		}
+		i, err := strconv.Atoi(strings.TrimPrefix(alias, "r"))
+		if err != nil || i < 0 || i >= len(batch) {
+			continue
+		}
+		repository := batch[i].repository
+		var repoErr error = &vcs.RequestFailedError{Cause: errors.New(graphQLErr.Message)}
+		if graphQLErr.Type == "NOT_FOUND" {
+			repoErr = &vcs.RepositoryNotFoundError{RepositoryAddr: repository, Cause: errors.New(graphQLErr.Message)}
+		}
+		results[repository] = vcs.BatchTagResult{Err: repoErr}
+	}
+
+	var next []pendingRepository
+	for i, p := range batch {
+		if results[p.repository].Err != nil {
+			continue
+		}
+		data := response.Data["r"+strconv.Itoa(i)]
+		if data == nil {
+			results[p.repository] = vcs.BatchTagResult{Err: &vcs.RepositoryNotFoundError{RepositoryAddr: p.repository}}
+			continue
+		}
+		result := results[p.repository]
+		for _, node := range data.Refs.Nodes {
+			if err := node.Name.Validate(); err != nil {
+				g.config.Logger.Debug(ctx, "Skipping invalid tag %s in repository %s", node.Name, p.repository)
+				continue
+			}
+			date := node.Target.CommittedDate
+			if node.Target.Tagger != nil {
+				date = node.Target.Tagger.Date
+			}
+			commit := node.Target.OID
+			if node.Target.Target != nil {
+				commit = node.Target.Target.OID
+			}
+			created, err := time.Parse(time.RFC3339, date)
+			if err != nil {
+				g.config.Logger.Debug(ctx, "Skipping tag %s with invalid creation date (%s) in repository %s", node.Name, date, p.repository)
+				continue
+			}
+			result.Tags = append(result.Tags, vcs.Version{
+				VersionNumber: node.Name,
+				Created:       created,
+				Commit:        commit,
+			})
+		}
+		results[p.repository] = result
+		if data.Refs.PageInfo.HasNextPage {
+			next = append(next, pendingRepository{
+				repository: p.repository,
+				cursor:     data.Refs.PageInfo.EndCursor,
+			})
+		}
+	}
+	return next, nil
+}
+
+func (g github) graphQL(ctx context.Context, request graphQLRequest) (graphQLResponse, error) {
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return graphQLResponse{}, err
+	}
+	graphQLURL := g.graphQLURL()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, graphQLURL, bytes.NewReader(requestBody))
+	if err != nil {
+		return graphQLResponse{}, &vcs.RequestFailedError{
+			Cause: fmt.Errorf("invalid HTTP request (%w)", err),
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := g.authorize(ctx, req); err != nil {
+		return graphQLResponse{}, err
+	}
+	logger.LogTrace(ctx, g.config.Logger, "Sending POST request to %s...", graphQLURL)
+	resp, err := g.config.HTTPClient.Do(req)
+	if err != nil {
+		return graphQLResponse{}, &vcs.RequestFailedError{
+			Cause: err,
+		}
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	logger.LogTrace(ctx, g.config.Logger, "POST request to %s returned status code %d", graphQLURL, resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		err := &vcs.RequestFailedError{
+			Cause: &InvalidStatusCodeError{resp.StatusCode},
+			Body:  body,
+		}
+		if resetAt, ok := rateLimitResetTime(resp); ok {
+			return graphQLResponse{}, &vcs.RateLimitedError{
+				ResetAt: resetAt,
+				Cause:   err,
+			}
+		}
+		return graphQLResponse{}, err
+	}
+	var response graphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return graphQLResponse{}, &vcs.RequestFailedError{
+			Cause: fmt.Errorf("failed to decode GraphQL response (%w)", err),
+		}
+	}
+	if response.Data == nil && len(response.Errors) > 0 {
+		return graphQLResponse{}, &vcs.RequestFailedError{
+			Cause: fmt.Errorf("GraphQL request failed: %s", response.Errors[0].Message),
+		}
+	}
+	return response, nil
+}
+
+// graphQLURL returns the address of the GraphQL API. GitHub Enterprise Server serves it at /api/graphql next to the
+// REST API at /api/v3.
+func (g github) graphQLURL() string {
+	if strings.HasSuffix(g.config.APIBaseURL, "/api/v3") {
+		return strings.TrimSuffix(g.config.APIBaseURL, "/v3") + "/graphql"
+	}
+	return g.config.APIBaseURL + "/graphql"
+}
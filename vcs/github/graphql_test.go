@@ -0,0 +1,123 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package github_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/github"
+)
+
+// fakeGraphQLRefs returns one page of tags for a repository with the given number of lightweight tags.
+func fakeGraphQLRefs(tagCount int, cursor string) map[string]any {
+	start := 0
+	if cursor != "" {
+		start, _ = strconv.Atoi(cursor)
+	}
+	end := min(start+100, tagCount)
+	var nodes []any
+	for i := start; i < end; i++ {
+		nodes = append(nodes, map[string]any{
+			"name":   fmt.Sprintf("v1.0.%d", i),
+			"target": map[string]any{"committedDate": "2024-01-01T00:00:00Z"},
+		})
+	}
+	return map[string]any{
+		"pageInfo": map[string]any{
+			"hasNextPage": end < tagCount,
+			"endCursor":   strconv.Itoa(end),
+		},
+		"nodes": nodes,
+	}
+}
+
+func TestListAllTagsBatch(t *testing.T) {
+	tagCounts := map[string]int{
+		"opentofu/many-tags": 150,
+		"opentofu/few-tags":  2,
+	}
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/graphql" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		requests++
+		var request struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data := map[string]any{}
+		var graphQLErrors []any
+		for i := 0; ; i++ {
+			owner, ok := request.Variables["owner"+strconv.Itoa(i)].(string)
+			if !ok {
+				break
+			}
+			name := request.Variables["name"+strconv.Itoa(i)].(string)
+			cursor, _ := request.Variables["cursor"+strconv.Itoa(i)].(string)
+			alias := "r" + strconv.Itoa(i)
+			tagCount, ok := tagCounts[owner+"/"+name]
+			if !ok {
+				data[alias] = nil
+				graphQLErrors = append(graphQLErrors, map[string]any{
+					"type":    "NOT_FOUND",
+					"path":    []any{alias},
+					"message": "Could not resolve to a Repository with the name '" + owner + "/" + name + "'.",
+				})
+				continue
+			}
+			data[alias] = map[string]any{"refs": fakeGraphQLRefs(tagCount, cursor)}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data, "errors": graphQLErrors})
+	}))
+	t.Cleanup(srv.Close)
+
+	gh, err := github.New(
+		github.WithBaseURL(srv.URL),
+		github.WithHTTPClient(srv.Client()),
+		github.WithLogger(logger.NewTestLogger(t)),
+	)
+	if err != nil {
+		t.Fatalf("❌ Failed to initialize GitHub client (%v)", err)
+	}
+	batchLister, ok := gh.(vcs.BatchTagLister)
+	if !ok {
+		t.Fatalf("❌ The GitHub client does not implement vcs.BatchTagLister.")
+	}
+
+	manyTags := vcs.RepositoryAddr{Org: "opentofu", Name: "many-tags"}
+	fewTags := vcs.RepositoryAddr{Org: "opentofu", Name: "few-tags"}
+	nonexistent := vcs.RepositoryAddr{Org: "opentofu", Name: "nonexistent"}
+	results, err := batchLister.ListAllTagsBatch(context.Background(), []vcs.RepositoryAddr{manyTags, fewTags, nonexistent})
+	if err != nil {
+		t.Fatalf("❌ Failed to list tags in batch (%v)", err)
+	}
+	if requests != 2 {
+		t.Fatalf("❌ Expected 2 GraphQL requests (one for the second page), got %d.", requests)
+	}
+	if results[manyTags].Err != nil || len(results[manyTags].Tags) != 150 {
+		t.Fatalf("❌ Incorrect result for %s: %d tags (%v)", manyTags, len(results[manyTags].Tags), results[manyTags].Err)
+	}
+	if results[fewTags].Err != nil || len(results[fewTags].Tags) != 2 {
+		t.Fatalf("❌ Incorrect result for %s: %d tags (%v)", fewTags, len(results[fewTags].Tags), results[fewTags].Err)
+	}
+	var notFound *vcs.RepositoryNotFoundError
+	if !errors.As(results[nonexistent].Err, &notFound) {
+		t.Fatalf("❌ Expected a RepositoryNotFoundError for %s, got: %v", nonexistent, results[nonexistent].Err)
+	}
+	t.Logf("✅ The batch tag listing returned the correct results.")
+}
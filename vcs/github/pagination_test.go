@@ -0,0 +1,140 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package github_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/github"
+)
+
+// servePages serves the items in pages of 100, linking to the next page the same way GitHub does.
+func servePages(t *testing.T, w http.ResponseWriter, r *http.Request, items []any) {
+	if r.URL.Query().Get("per_page") != "100" {
+		t.Errorf("❌ Incorrect per_page parameter: %s", r.URL.Query().Get("per_page"))
+	}
+	page := 1
+	if pageParam := r.URL.Query().Get("page"); pageParam != "" {
+		var err error
+		if page, err = strconv.Atoi(pageParam); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	start := min((page-1)*100, len(items))
+	end := min(page*100, len(items))
+	if end < len(items) {
+		w.Header().Set("Link", fmt.Sprintf(
+			`<http://%s%s?per_page=100&page=%d>; rel="next", <http://%s%s?per_page=100&page=%d>; rel="last"`,
+			r.Host, r.URL.Path, page+1, r.Host, r.URL.Path, (len(items)+99)/100,
+		))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(items[start:end])
+}
+
+func newPaginatedGitHub(t *testing.T, opts ...github.Opt) vcs.Client {
+	t.Helper()
+	var releases []any
+	for i := 0; i < 205; i++ {
+		releases = append(releases, map[string]any{
+			"name":         fmt.Sprintf("v1.0.%d", i),
+			"published_at": "2024-01-01T00:00:00Z",
+		})
+	}
+	var members []any
+	for i := 0; i < 150; i++ {
+		members = append(members, map[string]any{"login": fmt.Sprintf("user%d", i)})
+	}
+	var assets []any
+	for i := 0; i < 120; i++ {
+		assets = append(assets, map[string]any{"name": fmt.Sprintf("asset%d.zip", i)})
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/opentofu/test/releases", func(w http.ResponseWriter, r *http.Request) {
+		servePages(t, w, r, releases)
+	})
+	mux.HandleFunc("/repos/opentofu/test/releases/tags/v1.0.0", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 42})
+	})
+	mux.HandleFunc("/repos/opentofu/test/releases/42/assets", func(w http.ResponseWriter, r *http.Request) {
+		servePages(t, w, r, assets)
+	})
+	mux.HandleFunc("/orgs/opentofu/members", func(w http.ResponseWriter, r *http.Request) {
+		servePages(t, w, r, members)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	opts = append([]github.Opt{
+		github.WithAPIBaseURL(srv.URL),
+		github.WithHTTPClient(srv.Client()),
+		github.WithLogger(logger.NewTestLogger(t)),
+	}, opts...)
+	gh, err := github.New(opts...)
+	if err != nil {
+		t.Fatalf("❌ Failed to initialize GitHub client (%v)", err)
+	}
+	return gh
+}
+
+var paginationTestRepo = vcs.RepositoryAddr{Org: "opentofu", Name: "test"}
+
+func TestPaginatedReleases(t *testing.T) {
+	gh := newPaginatedGitHub(t)
+	releases, err := gh.ListAllReleases(context.Background(), paginationTestRepo)
+	if err != nil {
+		t.Fatalf("❌ Failed to list releases (%v)", err)
+	}
+	if len(releases) != 205 {
+		t.Fatalf("❌ Incorrect number of releases returned: %d", len(releases))
+	}
+	if releases[204].VersionNumber != "v1.0.204" {
+		t.Fatalf("❌ Incorrect last release: %s", releases[204].VersionNumber)
+	}
+}
+
+func TestPaginatedAssets(t *testing.T) {
+	gh := newPaginatedGitHub(t)
+	assets, err := gh.ListAssets(context.Background(), paginationTestRepo, "v1.0.0")
+	if err != nil {
+		t.Fatalf("❌ Failed to list assets (%v)", err)
+	}
+	if len(assets) != 120 {
+		t.Fatalf("❌ Incorrect number of assets returned: %d", len(assets))
+	}
+}
+
+func TestPaginatedHasPermission(t *testing.T) {
+	gh := newPaginatedGitHub(t)
+	hasPermission, err := gh.HasPermission(context.Background(), "user149", "opentofu")
+	if err != nil {
+		t.Fatalf("❌ Failed to check permissions (%v)", err)
+	}
+	if !hasPermission {
+		t.Fatalf("❌ The member on the second page does not have permission.")
+	}
+}
+
+func TestPageLimit(t *testing.T) {
+	gh := newPaginatedGitHub(t, github.WithMaxPages(2))
+	_, err := gh.ListAllReleases(context.Background(), paginationTestRepo)
+	var pageLimitErr *github.PageLimitExceededError
+	if !errors.As(err, &pageLimitErr) {
+		t.Fatalf("❌ Expected a PageLimitExceededError, got: %v", err)
+	}
+	if _, err := github.New(github.WithMaxPages(0)); err == nil {
+		t.Fatalf("❌ Setting the page limit to 0 did not fail.")
+	}
+}
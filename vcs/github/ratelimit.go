@@ -0,0 +1,78 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package github
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// maxRateLimitRetries is the number of times a request is retried after waiting for the rate limit to reset.
+const maxRateLimitRetries = 3
+
+// rateLimitTracker keeps the last rate limit status reported by the GitHub API.
+type rateLimitTracker struct {
+	lock   sync.Mutex
+	status vcs.RateLimitStatus
+}
+
+// update records the rate limit headers of an API response, if present.
+func (r *rateLimitTracker) update(header http.Header) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.status = vcs.RateLimitStatus{
+		Known:     true,
+		Limit:     limit,
+		Remaining: remaining,
+		ResetAt:   time.Unix(reset, 0),
+	}
+}
+
+func (r *rateLimitTracker) get() vcs.RateLimitStatus {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.status
+}
+
+// rateLimitResetTime determines if the response was rejected due to a primary or secondary rate limit and returns
+// the time when the request can be retried. See
+// https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api for details.
+func rateLimitResetTime(resp *http.Response) (time.Time, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return time.Time{}, false
+	}
+	if retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Now().Add(time.Duration(retryAfter) * time.Second), true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return time.Unix(reset, 0), true
+		}
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		// GitHub recommends waiting at least a minute if no other information is present.
+		return time.Now().Add(time.Minute), true
+	}
+	return time.Time{}, false
+}
+
+func (g github) RateLimitStatus() vcs.RateLimitStatus {
+	return g.rateLimits.get()
+}
@@ -0,0 +1,114 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package github_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/github"
+)
+
+var rateLimitTestRepo = vcs.RepositoryAddr{Org: "opentofu", Name: "opentofu"}
+
+func newRateLimitGitHub(t *testing.T, handler http.HandlerFunc, opts ...github.Opt) vcs.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	opts = append([]github.Opt{
+		github.WithAPIBaseURL(srv.URL),
+		github.WithHTTPClient(srv.Client()),
+		github.WithLogger(logger.NewTestLogger(t)),
+	}, opts...)
+	gh, err := github.New(opts...)
+	if err != nil {
+		t.Fatalf("❌ Failed to initialize GitHub client (%v)", err)
+	}
+	return gh
+}
+
+func TestRateLimitExceeded(t *testing.T) {
+	resetAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	gh := newRateLimitGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}, github.WithMaxRateLimitWait(time.Minute))
+
+	_, err := gh.GetRepositoryInfo(context.Background(), rateLimitTestRepo)
+	var rateLimitErr *vcs.RateLimitedError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("❌ Expected a RateLimitedError, got: %v", err)
+	}
+	if !rateLimitErr.ResetAt.Equal(resetAt) {
+		t.Fatalf("❌ Incorrect reset time: %s (expected: %s)", rateLimitErr.ResetAt, resetAt)
+	}
+
+	status := gh.(vcs.RateLimitReporter).RateLimitStatus()
+	if !status.Known || status.Limit != 5000 || status.Remaining != 0 || !status.ResetAt.Equal(resetAt) {
+		t.Fatalf("❌ Incorrect rate limit status: %v", status)
+	}
+	t.Logf("✅ The exhausted rate limit was reported correctly.")
+}
+
+func TestRateLimitRetryAfter(t *testing.T) {
+	requests := &atomic.Int32{}
+	gh := newRateLimitGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"description": "OpenTofu"})
+	}, github.WithMaxRateLimitWait(time.Minute))
+
+	info, err := gh.GetRepositoryInfo(context.Background(), rateLimitTestRepo)
+	if err != nil {
+		t.Fatalf("❌ The request was not retried after the secondary rate limit (%v)", err)
+	}
+	if info.Description != "OpenTofu" || requests.Load() != 2 {
+		t.Fatalf("❌ Incorrect result after retrying: %v (%d requests)", info, requests.Load())
+	}
+	t.Logf("✅ The request was retried after the Retry-After time.")
+}
+
+func TestConditionalRequests(t *testing.T) {
+	const etag = `"releases-1"`
+	fullResponses := &atomic.Int32{}
+	gh := newRateLimitGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fullResponses.Add(1)
+		w.Header().Set("ETag", etag)
+		_ = json.NewEncoder(w).Encode([]any{
+			map[string]any{"name": "v1.6.0", "published_at": "2024-01-10T12:00:00Z"},
+		})
+	}, github.WithResponseCache(github.NewMemoryResponseCache()))
+
+	for i := 0; i < 3; i++ {
+		releases, err := gh.ListAllReleases(context.Background(), rateLimitTestRepo)
+		if err != nil {
+			t.Fatalf("❌ Failed to list releases (%v)", err)
+		}
+		if len(releases) != 1 || releases[0].VersionNumber != "v1.6.0" {
+			t.Fatalf("❌ Incorrect releases returned: %v", releases)
+		}
+	}
+	if fullResponses.Load() != 1 {
+		t.Fatalf("❌ The unchanged release list was transferred %d times.", fullResponses.Load())
+	}
+	t.Logf("✅ The unchanged release list was served from the cache.")
+}
@@ -0,0 +1,583 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package gitlab implements the vcs.Client interface on top of the GitLab v4 API. Organizations map to top-level
+// GitLab groups, repositories to projects and release assets to release links.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentofu/libregistry/internal/gitcli"
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// latestItemCount is the number of items returned from ListLatestTags and ListLatestReleases.
+const latestItemCount = 20
+
+// pageSize is the number of items requested per page when listing all items.
+const pageSize = 100
+
+// New creates a new GitLab VCS client.
+func New(
+	options ...Opt,
+) (vcs.Client, error) {
+	config := Config{}
+	for _, opt := range options {
+		if err := opt(&config); err != nil {
+			return nil, err
+		}
+	}
+	config.ApplyDefaults()
+
+	return &gitlab{
+		config: config,
+		git: gitcli.New(gitcli.Config{
+			GitPath:                       config.GitPath,
+			CheckoutRootDirectory:         config.CheckoutRootDirectory,
+			SkipCleanupWorkingCopyOnClose: config.SkipCleanupWorkingCopyOnClose,
+			Logger:                        config.Logger,
+		}),
+	}, nil
+}
+
+type gitlab struct {
+	config Config
+	git    *gitcli.Git
+}
+
+func (g gitlab) ParseRepositoryAddr(ref string) (vcs.RepositoryAddr, error) {
+	ref = strings.TrimPrefix(ref, g.config.BaseURL+"/")
+	if baseURL, err := url.Parse(g.config.BaseURL); err == nil {
+		ref = strings.TrimPrefix(ref, baseURL.Host+"/")
+	}
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) != 2 {
+		return vcs.RepositoryAddr{}, &vcs.InvalidRepositoryAddrError{
+			RepositoryString: ref,
+		}
+	}
+	result := vcs.RepositoryAddr{
+		Org:  vcs.OrganizationAddr(parts[0]),
+		Name: parts[1],
+	}
+	return result, result.Validate()
+}
+
+func (g gitlab) GetRepositoryInfo(ctx context.Context, repository vcs.RepositoryAddr) (vcs.RepositoryInfo, error) {
+	if err := repository.Validate(); err != nil {
+		return vcs.RepositoryInfo{}, err
+	}
+	type projectResponse struct {
+		Description       string `json:"description"`
+		StarCount         int    `json:"star_count"`
+		ForksCount        int    `json:"forks_count"`
+		Archived          bool   `json:"archived"`
+		ForkedFromProject *struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+		} `json:"forked_from_project"`
+	}
+
+	var response projectResponse
+	if _, err := g.request(ctx, g.projectURL(repository), &response); err != nil {
+		return vcs.RepositoryInfo{}, g.repositoryError(repository, err)
+	}
+
+	repoInfo := vcs.RepositoryInfo{
+		Description: response.Description,
+		Popularity:  response.StarCount,
+		ForkCount:   response.ForksCount,
+		Archived:    response.Archived,
+	}
+	if response.ForkedFromProject != nil {
+		parent, err := g.ParseRepositoryAddr(response.ForkedFromProject.PathWithNamespace)
+		if err != nil {
+			g.config.Logger.Debug(ctx, "Ignoring unsupported parent project %s of repository %s (%v)", response.ForkedFromProject.PathWithNamespace, repository, err)
+		} else {
+			repoInfo.ForkOf = &parent
+		}
+	}
+	return repoInfo, nil
+}
+
+type tagResponse struct {
+	Name vcs.VersionNumber `json:"name"`
+	// CreatedAt is only present for annotated tags.
+	CreatedAt string `json:"created_at"`
+	Commit    struct {
+		ID        string `json:"id"`
+		CreatedAt string `json:"created_at"`
+	} `json:"commit"`
+}
+
+func (t tagResponse) toVersion() (vcs.Version, error) {
+	createdAt := t.CreatedAt
+	if createdAt == "" {
+		createdAt = t.Commit.CreatedAt
+	}
+	created, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return vcs.Version{}, err
+	}
+	return vcs.Version{
+		VersionNumber: t.Name,
+		Created:       created,
+		Commit:        t.Commit.ID,
+	}, nil
+}
+
+func (g gitlab) ListLatestTags(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	logger.LogTrace(ctx, g.config.Logger, "Requesting latest tags for repository %s...", repository)
+	return g.listTags(ctx, repository, false)
+}
+
+func (g gitlab) ListAllTags(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	logger.LogTrace(ctx, g.config.Logger, "Requesting all tags for repository %s...", repository)
+	return g.listTags(ctx, repository, true)
+}
+
+func (g gitlab) listTags(ctx context.Context, repository vcs.RepositoryAddr, all bool) ([]vcs.Version, error) {
+	if err := repository.Validate(); err != nil {
+		return nil, err
+	}
+	var tags []tagResponse
+	if err := g.list(ctx, repository, g.projectURL(repository)+"/repository/tags", all, &tags); err != nil {
+		return nil, err
+	}
+	var result []vcs.Version
+	for _, tag := range tags {
+		if err := tag.Name.Validate(); err != nil {
+			g.config.Logger.Debug(ctx, "Skipping invalid tag %s in repository %s", tag.Name, repository)
+			continue
+		}
+		ver, err := tag.toVersion()
+		if err != nil {
+			g.config.Logger.Debug(ctx, "Skipping tag %s with invalid creation date in repository %s (%v)", tag.Name, repository, err)
+			continue
+		}
+		result = append(result, ver)
+	}
+	return result, nil
+}
+
+func (g gitlab) GetTagVersion(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (vcs.Version, error) {
+	if err := repository.Validate(); err != nil {
+		return vcs.Version{}, err
+	}
+	if err := version.Validate(); err != nil {
+		return vcs.Version{}, err
+	}
+	var tag tagResponse
+	if _, err := g.request(ctx, g.projectURL(repository)+"/repository/tags/"+url.PathEscape(string(version)), &tag); err != nil {
+		return vcs.Version{}, g.versionError(repository, version, err)
+	}
+	ver, err := tag.toVersion()
+	if err != nil {
+		return vcs.Version{}, fmt.Errorf("invalid creation date for tag %s in repository %s (%w)", version, repository, err)
+	}
+	return ver, nil
+}
+
+type releaseResponse struct {
+	TagName    vcs.VersionNumber `json:"tag_name"`
+	ReleasedAt string            `json:"released_at"`
+	Assets     struct {
+		Links []releaseLink `json:"links"`
+	} `json:"assets"`
+}
+
+type releaseLink struct {
+	Name           vcs.AssetName `json:"name"`
+	URL            string        `json:"url"`
+	DirectAssetURL string        `json:"direct_asset_url"`
+}
+
+func (l releaseLink) downloadURL() string {
+	if l.DirectAssetURL != "" {
+		return l.DirectAssetURL
+	}
+	return l.URL
+}
+
+func (g gitlab) ListLatestReleases(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	logger.LogTrace(ctx, g.config.Logger, "Requesting latest releases for repository %s...", repository)
+	return g.listReleases(ctx, repository, false)
+}
+
+func (g gitlab) ListAllReleases(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	logger.LogTrace(ctx, g.config.Logger, "Requesting all releases for repository %s...", repository)
+	return g.listReleases(ctx, repository, true)
+}
+
+func (g gitlab) listReleases(ctx context.Context, repository vcs.RepositoryAddr, all bool) ([]vcs.Version, error) {
+	if err := repository.Validate(); err != nil {
+		return nil, err
+	}
+	var releases []releaseResponse
+	if err := g.list(ctx, repository, g.projectURL(repository)+"/releases", all, &releases); err != nil {
+		return nil, err
+	}
+	var result []vcs.Version
+	for _, release := range releases {
+		if err := release.TagName.Validate(); err != nil {
+			g.config.Logger.Debug(ctx, "Skipping invalid release %s in repository %s", release.TagName, repository)
+			continue
+		}
+		created, err := time.Parse(time.RFC3339, release.ReleasedAt)
+		if err != nil {
+			g.config.Logger.Debug(ctx, "Skipping invalid release creation date (%s) for %s in repository %s", release.ReleasedAt, release.TagName, repository)
+			continue
+		}
+		result = append(result, vcs.Version{
+			VersionNumber: release.TagName,
+			Created:       created,
+		})
+	}
+	return result, nil
+}
+
+func (g gitlab) getRelease(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (releaseResponse, error) {
+	if err := repository.Validate(); err != nil {
+		return releaseResponse{}, err
+	}
+	if err := version.Validate(); err != nil {
+		return releaseResponse{}, err
+	}
+	var release releaseResponse
+	if _, err := g.request(ctx, g.projectURL(repository)+"/releases/"+url.PathEscape(string(version)), &release); err != nil {
+		return releaseResponse{}, g.versionError(repository, version, err)
+	}
+	return release, nil
+}
+
+func (g gitlab) ListAssets(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) ([]vcs.AssetName, error) {
+	logger.LogTrace(ctx, g.config.Logger, "Listing assets for repository %s version %s", repository, version)
+	release, err := g.getRelease(ctx, repository, version)
+	if err != nil {
+		return nil, err
+	}
+	var result []vcs.AssetName
+	for _, link := range release.Assets.Links {
+		if err := link.Name.Validate(); err != nil {
+			g.config.Logger.Debug(ctx, "Skipping invalid asset named %s in repository %s release %s", link.Name, repository, version)
+			continue
+		}
+		result = append(result, link.Name)
+	}
+	return result, nil
+}
+
+func (g gitlab) findAssetLink(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, asset vcs.AssetName) (releaseLink, bool, error) {
+	if err := asset.Validate(); err != nil {
+		return releaseLink{}, false, err
+	}
+	release, err := g.getRelease(ctx, repository, version)
+	if err != nil {
+		return releaseLink{}, false, err
+	}
+	for _, link := range release.Assets.Links {
+		if link.Name == asset {
+			return link, true, nil
+		}
+	}
+	return releaseLink{}, false, nil
+}
+
+func (g gitlab) DownloadAsset(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, asset vcs.AssetName) ([]byte, error) {
+	logger.LogTrace(ctx, g.config.Logger, "Downloading asset %s for repository %s version %s", asset, repository, version)
+	link, found, err := g.findAssetLink(ctx, repository, version, asset)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, &vcs.AssetNotFoundError{
+			RepositoryAddr: repository,
+			Version:        version,
+			Asset:          asset,
+		}
+	}
+	assetURL := link.downloadURL()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, assetURL, nil)
+	if err != nil {
+		return nil, &vcs.RequestFailedError{
+			Cause: fmt.Errorf("invalid HTTP request (%w)", err),
+		}
+	}
+	// Release links may point to external hosts, only send the token to the GitLab instance itself.
+	if g.config.Token != "" && strings.HasPrefix(assetURL, g.config.BaseURL+"/") {
+		req.Header.Set("PRIVATE-TOKEN", g.config.Token)
+	}
+	logger.LogTrace(ctx, g.config.Logger, "Sending GET request to %s...", assetURL)
+	resp, err := g.config.HTTPClient.Do(req)
+	if err != nil {
+		logger.LogTrace(ctx, g.config.Logger, "GET request to %s failed (%v)", assetURL, err)
+		return nil, &vcs.RequestFailedError{
+			Cause: err,
+		}
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	logger.LogTrace(ctx, g.config.Logger, "GET request to %s returned status code %d", assetURL, resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		err = &InvalidStatusCodeError{resp.StatusCode}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, &vcs.AssetNotFoundError{
+				RepositoryAddr: repository,
+				Version:        version,
+				Asset:          asset,
+				Cause:          err,
+			}
+		}
+		return nil, &vcs.RequestFailedError{
+			Cause: err,
+		}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &vcs.RequestFailedError{
+			Cause: err,
+		}
+	}
+	return body, nil
+}
+
+func (g gitlab) GetAssetDownloadURL(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, asset vcs.AssetName) (string, error) {
+	link, found, err := g.findAssetLink(ctx, repository, version, asset)
+	if err != nil {
+		return "", err
+	}
+	if found {
+		return link.downloadURL(), nil
+	}
+	// Fall back to the permanent link format GitLab uses for release assets.
+	return g.webURL(repository) + "/-/releases/" + url.PathEscape(string(version)) + "/downloads/" + url.PathEscape(string(asset)), nil
+}
+
+func (g gitlab) HasPermission(ctx context.Context, username vcs.Username, organization vcs.OrganizationAddr) (bool, error) {
+	type memberType struct {
+		Username string `json:"username"`
+	}
+
+	if err := organization.Validate(); err != nil {
+		return false, err
+	}
+	if err := username.Validate(); err != nil {
+		return false, err
+	}
+	logger.LogTrace(ctx, g.config.Logger, "Checking if user %s has permissions for the organization %s...", username, organization)
+	reqURL := g.config.BaseURL + "/api/v4/groups/" + url.PathEscape(string(organization)) + "/members/all"
+	page := "1"
+	for page != "" {
+		var response []memberType
+		nextPage, err := g.request(ctx, reqURL+"?per_page="+strconv.Itoa(pageSize)+"&page="+url.QueryEscape(page), &response)
+		if err != nil {
+			var statusCodeErr *InvalidStatusCodeError
+			if errors.As(err, &statusCodeErr) && statusCodeErr.StatusCode == http.StatusNotFound {
+				return false, &vcs.OrganizationNotFoundError{
+					OrganizationAddr: organization,
+					Cause:            err,
+				}
+			}
+			return false, err
+		}
+		for _, member := range response {
+			if strings.EqualFold(member.Username, string(username)) {
+				return true, nil
+			}
+		}
+		page = nextPage
+	}
+	return false, nil
+}
+
+func (g gitlab) Checkout(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (vcs.WorkingCopy, error) {
+	cloneURL, err := g.cloneURL(repository)
+	if err != nil {
+		return nil, err
+	}
+	return g.git.Checkout(ctx, g, repository, cloneURL, func(ctx context.Context) (bool, error) {
+		return g.repositoryExists(ctx, repository)
+	}, version)
+}
+
+func (g gitlab) cloneURL(repository vcs.RepositoryAddr) (string, error) {
+	if err := repository.Validate(); err != nil {
+		return "", err
+	}
+	cloneURL, err := url.Parse(g.webURL(repository) + ".git")
+	if err != nil {
+		return "", fmt.Errorf("invalid clone URL for repository %s (%w)", repository, err)
+	}
+	if g.config.Token != "" {
+		cloneURL.User = url.UserPassword("oauth2", g.config.Token)
+	}
+	return cloneURL.String(), nil
+}
+
+func (g gitlab) GetRepositoryBrowseURL(_ context.Context, repository vcs.RepositoryAddr) (string, error) {
+	if err := repository.Validate(); err != nil {
+		return "", err
+	}
+	return g.webURL(repository), nil
+}
+
+func (g gitlab) GetVersionBrowseURL(_ context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (string, error) {
+	if err := repository.Validate(); err != nil {
+		return "", err
+	}
+	if err := version.Validate(); err != nil {
+		return "", err
+	}
+	return g.webURL(repository) + "/-/tree/" + url.PathEscape(string(version)), nil
+}
+
+func (g gitlab) GetFileViewURL(_ context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, file string) (string, error) {
+	if err := repository.Validate(); err != nil {
+		return "", err
+	}
+	if err := version.Validate(); err != nil {
+		return "", err
+	}
+	if file == "" {
+		return "", fmt.Errorf("empty file name passed")
+	}
+	fileParts := strings.Split(file, "/")
+	for i, part := range fileParts {
+		fileParts[i] = url.PathEscape(part)
+	}
+	return g.webURL(repository) + "/-/blob/" + url.PathEscape(string(version)) + "/" + strings.Join(fileParts, "/"), nil
+}
+
+func (g gitlab) webURL(repository vcs.RepositoryAddr) string {
+	return g.config.BaseURL + "/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name)
+}
+
+func (g gitlab) projectURL(repository vcs.RepositoryAddr) string {
+	return g.config.BaseURL + "/api/v4/projects/" + url.PathEscape(string(repository.Org)+"/"+repository.Name)
+}
+
+func (g gitlab) repositoryExists(ctx context.Context, repository vcs.RepositoryAddr) (bool, error) {
+	var response any
+	if _, err := g.request(ctx, g.projectURL(repository), &response); err != nil {
+		var statusCodeErr *InvalidStatusCodeError
+		if errors.As(err, &statusCodeErr) && statusCodeErr.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// list requests a paginated list endpoint. If all is false, only the first page with the latest items is requested.
+// The items of all pages are appended to the response slice.
+func (g gitlab) list(ctx context.Context, repository vcs.RepositoryAddr, reqURL string, all bool, response any) error {
+	perPage := latestItemCount
+	if all {
+		perPage = pageSize
+	}
+	var items []json.RawMessage
+	page := "1"
+	for page != "" {
+		var pageItems []json.RawMessage
+		nextPage, err := g.request(ctx, reqURL+"?per_page="+strconv.Itoa(perPage)+"&page="+url.QueryEscape(page), &pageItems)
+		if err != nil {
+			return g.repositoryError(repository, err)
+		}
+		items = append(items, pageItems...)
+		if !all {
+			break
+		}
+		page = nextPage
+	}
+	marshalled, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(marshalled, response)
+}
+
+// request sends a GET request to the API and decodes the JSON response. It returns the next page from the
+// X-Next-Page header, which is empty on the last page.
+func (g gitlab) request(ctx context.Context, url string, response any) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", &vcs.RequestFailedError{
+			Cause: fmt.Errorf("invalid HTTP request (%w)", err),
+		}
+	}
+	if g.config.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", g.config.Token)
+	}
+	logger.LogTrace(ctx, g.config.Logger, "Sending GET request to %s...", url)
+	resp, err := g.config.HTTPClient.Do(req)
+	if err != nil {
+		logger.LogTrace(ctx, g.config.Logger, "GET request to %s failed (%v)", url, err)
+		return "", &vcs.RequestFailedError{
+			Cause: err,
+		}
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	logger.LogTrace(ctx, g.config.Logger, "GET request to %s returned status code %d", url, resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", &vcs.RequestFailedError{
+			Cause: &InvalidStatusCodeError{resp.StatusCode},
+			Body:  body,
+		}
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	if err := decoder.Decode(&response); err != nil {
+		g.config.Logger.Warn(ctx, "GitLab returned an invalid JSON when requesting %s (%v)", url, err)
+		return "", &vcs.RequestFailedError{
+			Cause: fmt.Errorf("failed to decode response (%w)", err),
+		}
+	}
+
+	return resp.Header.Get("X-Next-Page"), nil
+}
+
+func (g gitlab) repositoryError(repository vcs.RepositoryAddr, err error) error {
+	var statusCodeErr *InvalidStatusCodeError
+	if errors.As(err, &statusCodeErr) && statusCodeErr.StatusCode == http.StatusNotFound {
+		return &vcs.RepositoryNotFoundError{
+			RepositoryAddr: repository,
+			Cause:          err,
+		}
+	}
+	return err
+}
+
+func (g gitlab) versionError(repository vcs.RepositoryAddr, version vcs.VersionNumber, err error) error {
+	var statusCodeErr *InvalidStatusCodeError
+	if errors.As(err, &statusCodeErr) && statusCodeErr.StatusCode == http.StatusNotFound {
+		return &vcs.VersionNotFoundError{
+			RepositoryAddr: repository,
+			Version:        version,
+			Cause:          err,
+		}
+	}
+	return err
+}
+
+type InvalidStatusCodeError struct {
+	StatusCode int
+}
+
+func (i InvalidStatusCodeError) Error() string {
+	return "Invalid status code: " + strconv.Itoa(i.StatusCode)
+}
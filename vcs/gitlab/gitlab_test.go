@@ -0,0 +1,336 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package gitlab_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/libregistry/internal/gittest"
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/gitlab"
+)
+
+const testToken = "glpat-test"
+
+var testRepo = vcs.RepositoryAddr{
+	Org:  "opentofu",
+	Name: "terraform-aws-test",
+}
+
+// newFakeGitLab creates a fake of the GitLab v4 API serving a single project. Requests for paths containing ".git/"
+// are served from the local git repositories in gitRoot.
+func newFakeGitLab(t *testing.T, gitRoot string) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	var gitHandler http.Handler
+	if gitRoot != "" {
+		gitHandler = gittest.NewHandler(t, gitRoot)
+	}
+	projectPath := "/api/v4/projects/opentofu%2Fterraform-aws-test"
+	// The project ID contains an escaped slash, so routing is done on the escaped path.
+	routes := map[string]http.HandlerFunc{}
+	handle := func(path string, response any) {
+		routes[path] = func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("PRIVATE-TOKEN") != testToken {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(response)
+		}
+	}
+	handle(projectPath, map[string]any{
+		"description": "Test module",
+		"star_count":  42,
+		"forks_count": 3,
+		"archived":    true,
+		"forked_from_project": map[string]any{
+			"path_with_namespace": "upstream/terraform-aws-test",
+		},
+	})
+	tag := func(name string, created string) map[string]any {
+		return map[string]any{
+			"name":   name,
+			"commit": map[string]any{"created_at": created},
+		}
+	}
+	routes[projectPath+"/repository/tags"] = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Header().Set("X-Next-Page", "2")
+			_ = json.NewEncoder(w).Encode([]any{
+				tag("v1.1.0", "2024-02-01T10:00:00.000Z"),
+				tag("invalid tag", "2024-01-15T10:00:00.000Z"),
+			})
+		default:
+			_ = json.NewEncoder(w).Encode([]any{
+				tag("v1.0.0", "2024-01-01T10:00:00+02:00"),
+			})
+		}
+	}
+	handle(projectPath+"/repository/tags/v1.0.0", tag("v1.0.0", "2024-01-01T10:00:00+02:00"))
+	release := func() map[string]any {
+		return map[string]any{
+			"tag_name":    "v1.0.0",
+			"released_at": "2024-01-01T12:00:00Z",
+			"assets": map[string]any{
+				"links": []any{
+					map[string]any{
+						"name":             "test.zip",
+						"url":              "https://example.com/test.zip",
+						"direct_asset_url": srv.URL + "/opentofu/terraform-aws-test/-/releases/v1.0.0/downloads/test.zip",
+					},
+				},
+			},
+		}
+	}
+	routes[projectPath+"/releases"] = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]any{release()})
+	}
+	routes[projectPath+"/releases/v1.0.0"] = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(release())
+	}
+	routes["/opentofu/terraform-aws-test/-/releases/v1.0.0/downloads/test.zip"] = func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("Hello world!"))
+	}
+	handle("/api/v4/groups/opentofu/members/all", []any{
+		map[string]any{"username": "janedoe"},
+	})
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route, ok := routes[r.URL.EscapedPath()]; ok {
+			route(w, r)
+			return
+		}
+		if gitHandler != nil && strings.Contains(r.URL.Path, ".git/") {
+			gitHandler.ServeHTTP(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"404 Not Found"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(t *testing.T, srv *httptest.Server, opts ...gitlab.Opt) vcs.Client {
+	t.Helper()
+	opts = append([]gitlab.Opt{
+		gitlab.WithBaseURL(srv.URL),
+		gitlab.WithToken(testToken),
+		gitlab.WithHTTPClient(srv.Client()),
+		gitlab.WithLogger(logger.NewTestLogger(t)),
+	}, opts...)
+	gl, err := gitlab.New(opts...)
+	if err != nil {
+		t.Fatalf("❌ Failed to initialize GitLab client (%v)", err)
+	}
+	return gl
+}
+
+func TestRepoInfo(t *testing.T) {
+	srv := newFakeGitLab(t, "")
+	gl := newTestClient(t, srv)
+
+	info, err := gl.GetRepositoryInfo(context.Background(), testRepo)
+	if err != nil {
+		t.Fatalf("❌ Failed to fetch repository info (%v)", err)
+	}
+	if info.Description != "Test module" || info.Popularity != 42 || info.ForkCount != 3 || !info.Archived {
+		t.Fatalf("❌ Incorrect repository info returned: %v", info)
+	}
+	if info.ForkOf == nil || info.ForkOf.String() != "upstream/terraform-aws-test" {
+		t.Fatalf("❌ Incorrect parent repository returned: %v", info.ForkOf)
+	}
+
+	_, err = gl.GetRepositoryInfo(context.Background(), vcs.RepositoryAddr{Org: "opentofu", Name: "nonexistent"})
+	var notFound *vcs.RepositoryNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("❌ Expected a RepositoryNotFoundError, got: %v", err)
+	}
+}
+
+func TestTags(t *testing.T) {
+	srv := newFakeGitLab(t, "")
+	gl := newTestClient(t, srv)
+	ctx := context.Background()
+
+	latest, err := gl.ListLatestTags(ctx, testRepo)
+	if err != nil {
+		t.Fatalf("❌ Failed to list latest tags (%v)", err)
+	}
+	if len(latest) != 1 || latest[0].VersionNumber != "v1.1.0" {
+		t.Fatalf("❌ Incorrect latest tags returned: %v", latest)
+	}
+
+	all, err := gl.ListAllTags(ctx, testRepo)
+	if err != nil {
+		t.Fatalf("❌ Failed to list all tags (%v)", err)
+	}
+	if len(all) != 2 || all[1].VersionNumber != "v1.0.0" {
+		t.Fatalf("❌ Incorrect tags returned: %v", all)
+	}
+	if all[1].Created.UTC().Hour() != 8 {
+		t.Fatalf("❌ Incorrect creation date for %s: %v", all[1].VersionNumber, all[1].Created)
+	}
+
+	ver, err := gl.GetTagVersion(ctx, testRepo, "v1.0.0")
+	if err != nil {
+		t.Fatalf("❌ Failed to get tag (%v)", err)
+	}
+	if !ver.Created.Equal(all[1].Created) {
+		t.Fatalf("❌ Incorrect creation date for tag: %v", ver.Created)
+	}
+
+	_, err = gl.GetTagVersion(ctx, testRepo, "v2.0.0")
+	var notFound *vcs.VersionNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("❌ Expected a VersionNotFoundError, got: %v", err)
+	}
+}
+
+func TestReleases(t *testing.T) {
+	srv := newFakeGitLab(t, "")
+	gl := newTestClient(t, srv)
+	ctx := context.Background()
+
+	releases, err := gl.ListAllReleases(ctx, testRepo)
+	if err != nil {
+		t.Fatalf("❌ Failed to list releases (%v)", err)
+	}
+	if len(releases) != 1 || releases[0].VersionNumber != "v1.0.0" {
+		t.Fatalf("❌ Incorrect releases returned: %v", releases)
+	}
+
+	assets, err := gl.ListAssets(ctx, testRepo, "v1.0.0")
+	if err != nil {
+		t.Fatalf("❌ Failed to list assets (%v)", err)
+	}
+	if len(assets) != 1 || assets[0] != "test.zip" {
+		t.Fatalf("❌ Incorrect assets returned: %v", assets)
+	}
+
+	contents, err := gl.DownloadAsset(ctx, testRepo, "v1.0.0", "test.zip")
+	if err != nil {
+		t.Fatalf("❌ Failed to download asset (%v)", err)
+	}
+	if string(contents) != "Hello world!" {
+		t.Fatalf("❌ Incorrect asset contents: %s", contents)
+	}
+
+	_, err = gl.DownloadAsset(ctx, testRepo, "v1.0.0", "nonexistent.zip")
+	var notFound *vcs.AssetNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("❌ Expected an AssetNotFoundError, got: %v", err)
+	}
+
+	downloadURL, err := gl.GetAssetDownloadURL(ctx, testRepo, "v1.0.0", "test.zip")
+	if err != nil {
+		t.Fatalf("❌ Failed to get asset download URL (%v)", err)
+	}
+	if downloadURL != srv.URL+"/opentofu/terraform-aws-test/-/releases/v1.0.0/downloads/test.zip" {
+		t.Fatalf("❌ Incorrect asset download URL: %s", downloadURL)
+	}
+}
+
+func TestHasPermission(t *testing.T) {
+	srv := newFakeGitLab(t, "")
+	gl := newTestClient(t, srv)
+	ctx := context.Background()
+
+	hasPermission, err := gl.HasPermission(ctx, "JaneDoe", "opentofu")
+	if err != nil {
+		t.Fatalf("❌ Failed to check permissions (%v)", err)
+	}
+	if !hasPermission {
+		t.Fatalf("❌ Group member does not have permission.")
+	}
+	hasPermission, err = gl.HasPermission(ctx, "johndoe", "opentofu")
+	if err != nil {
+		t.Fatalf("❌ Failed to check permissions (%v)", err)
+	}
+	if hasPermission {
+		t.Fatalf("❌ Non-member has permission.")
+	}
+
+	_, err = gl.HasPermission(ctx, "janedoe", "nonexistent")
+	var notFound *vcs.OrganizationNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("❌ Expected an OrganizationNotFoundError, got: %v", err)
+	}
+}
+
+func TestCheckout(t *testing.T) {
+	gitRoot := t.TempDir()
+	gittest.CreateRepository(t, gitRoot, testRepo, map[string]string{
+		"main.tf": "# Test module",
+	}, "v1.0.0")
+	srv := newFakeGitLab(t, gitRoot)
+	gl := newTestClient(t, srv, gitlab.WithCheckoutRootDirectory(t.TempDir()))
+
+	wc, err := gl.Checkout(context.Background(), testRepo, "v1.0.0")
+	if err != nil {
+		t.Fatalf("❌ Failed to check out repository (%v)", err)
+	}
+	defer func() {
+		if err := wc.Close(); err != nil {
+			t.Fatalf("❌ Failed to close working copy (%v)", err)
+		}
+	}()
+	dir, err := wc.RawDirectory()
+	if err != nil {
+		t.Fatalf("❌ Failed to get working copy directory (%v)", err)
+	}
+	contents, err := os.ReadFile(filepath.Join(dir, "main.tf"))
+	if err != nil {
+		t.Fatalf("❌ Failed to read checked out file (%v)", err)
+	}
+	if string(contents) != "# Test module" {
+		t.Fatalf("❌ Incorrect file contents: %s", contents)
+	}
+}
+
+func TestURLs(t *testing.T) {
+	gl, err := gitlab.New(gitlab.WithBaseURL("https://gitlab.example.com/"))
+	if err != nil {
+		t.Fatalf("❌ Failed to initialize GitLab client (%v)", err)
+	}
+	ctx := context.Background()
+
+	addr, err := gl.ParseRepositoryAddr("https://gitlab.example.com/opentofu/terraform-aws-test")
+	if err != nil {
+		t.Fatalf("❌ Failed to parse repository address (%v)", err)
+	}
+	if addr != testRepo {
+		t.Fatalf("❌ Incorrect repository address: %v", addr)
+	}
+
+	browseURL, err := gl.GetRepositoryBrowseURL(ctx, testRepo)
+	if err != nil || browseURL != "https://gitlab.example.com/opentofu/terraform-aws-test" {
+		t.Fatalf("❌ Incorrect repository browse URL: %s (%v)", browseURL, err)
+	}
+	versionURL, err := gl.GetVersionBrowseURL(ctx, testRepo, "v1.0.0")
+	if err != nil || versionURL != "https://gitlab.example.com/opentofu/terraform-aws-test/-/tree/v1.0.0" {
+		t.Fatalf("❌ Incorrect version browse URL: %s (%v)", versionURL, err)
+	}
+	fileURL, err := gl.GetFileViewURL(ctx, testRepo, "v1.0.0", "modules/test/main.tf")
+	if err != nil || fileURL != "https://gitlab.example.com/opentofu/terraform-aws-test/-/blob/v1.0.0/modules/test/main.tf" {
+		t.Fatalf("❌ Incorrect file view URL: %s (%v)", fileURL, err)
+	}
+}
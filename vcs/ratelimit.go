@@ -0,0 +1,26 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package vcs
+
+import (
+	"time"
+)
+
+// RateLimitStatus describes the API quota of a VCS client as last reported by the VCS system.
+type RateLimitStatus struct {
+	// Known is false if no response with rate limit information has been received yet.
+	Known bool
+	// Limit is the maximum number of requests in the current window.
+	Limit int
+	// Remaining is the number of requests left in the current window.
+	Remaining int
+	// ResetAt is the time the current window ends and the quota is restored.
+	ResetAt time.Time
+}
+
+// RateLimitReporter is implemented by VCS clients that track the rate limit of the VCS system.
+type RateLimitReporter interface {
+	// RateLimitStatus returns the last known rate limit status.
+	RateLimitStatus() RateLimitStatus
+}
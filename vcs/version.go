@@ -36,6 +36,9 @@ func (v VersionNumber) Equals(tag VersionNumber) bool {
 type Version struct {
 	VersionNumber VersionNumber
 	Created       time.Time
+	// Commit is the SHA of the commit the version points to. It may be empty if the VCS implementation cannot
+	// determine it cheaply, for example when reading a feed.
+	Commit string
 }
 
 func (v Version) Validate() error {